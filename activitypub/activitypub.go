@@ -0,0 +1,167 @@
+// Package activitypub implements the parts of ActivityPub, WebFinger and HTTP
+// Signatures needed to federate authors and articles with servers such as Mastodon.
+package activitypub
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ContentType is the media type of ActivityPub documents.
+const ContentType = "application/activity+json"
+
+// Public is the special collection addressing an activity to everyone.
+const Public = "https://www.w3.org/ns/activitystreams#Public"
+
+// Context is the JSON-LD context of the documents we publish.
+var Context = []string{"https://www.w3.org/ns/activitystreams", "https://w3id.org/security/v1"}
+
+// Actor is an ActivityPub actor document (a Person for our authors).
+type Actor struct {
+	Context           interface{} `json:"@context,omitempty"`
+	ID                string      `json:"id"`
+	Type              string      `json:"type"`
+	PreferredUsername string      `json:"preferredUsername"`
+	Name              string      `json:"name,omitempty"`
+	Summary           string      `json:"summary,omitempty"`
+	URL               string      `json:"url,omitempty"`
+	Icon              *Image      `json:"icon,omitempty"`
+	Inbox             string      `json:"inbox"`
+	Outbox            string      `json:"outbox,omitempty"`
+	Followers         string      `json:"followers,omitempty"`
+	Endpoints         *Endpoints  `json:"endpoints,omitempty"`
+	PublicKey         PublicKey   `json:"publicKey"`
+}
+
+// Endpoints lists server-wide endpoints of an actor.
+type Endpoints struct {
+	SharedInbox string `json:"sharedInbox,omitempty"`
+}
+
+// PublicKey is the key an actor signs its requests with.
+type PublicKey struct {
+	ID           string `json:"id"`
+	Owner        string `json:"owner"`
+	PublicKeyPem string `json:"publicKeyPem"`
+}
+
+// Image is an image attached to an actor or object.
+type Image struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// Tag is a hashtag attached to an object.
+type Tag struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	Href string `json:"href,omitempty"`
+}
+
+// Object is an ActivityPub object, such as an Article or a Note.
+type Object struct {
+	Context      interface{} `json:"@context,omitempty"`
+	ID           string      `json:"id"`
+	Type         string      `json:"type"`
+	AttributedTo string      `json:"attributedTo,omitempty"`
+	InReplyTo    string      `json:"inReplyTo,omitempty"`
+	Name         string      `json:"name,omitempty"`
+	Summary      string      `json:"summary,omitempty"`
+	Content      string      `json:"content,omitempty"`
+	URL          string      `json:"url,omitempty"`
+	Image        *Image      `json:"image,omitempty"`
+	Tag          []Tag       `json:"tag,omitempty"`
+	To           []string    `json:"to,omitempty"`
+	Cc           []string    `json:"cc,omitempty"`
+	Published    string      `json:"published,omitempty"`
+	Updated      string      `json:"updated,omitempty"`
+}
+
+// Activity is an ActivityPub activity. Object holds the raw JSON of the activity's
+// object, which is either an embedded document or the ID of one.
+type Activity struct {
+	Context   interface{}     `json:"@context,omitempty"`
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Actor     string          `json:"actor"`
+	Object    json.RawMessage `json:"object"`
+	To        []string        `json:"to,omitempty"`
+	Cc        []string        `json:"cc,omitempty"`
+	Published string          `json:"published,omitempty"`
+}
+
+// NewActivity builds an activity wrapping the given object.
+func NewActivity(activityType, id, actor string, object interface{}) (Activity, error) {
+	raw, err := json.Marshal(object)
+	if err != nil {
+		return Activity{}, err
+	}
+
+	return Activity{
+		Context: Context,
+		ID:      id,
+		Type:    activityType,
+		Actor:   actor,
+		Object:  raw,
+	}, nil
+}
+
+// ObjectID returns the ID of the activity's object, whether it is embedded or referenced.
+func (a Activity) ObjectID() string {
+	var id string
+	if err := json.Unmarshal(a.Object, &id); err == nil {
+		return id
+	}
+
+	var object struct {
+		ID string `json:"id"`
+	}
+	json.Unmarshal(a.Object, &object)
+	return object.ID
+}
+
+// OrderedCollection is an ordered list of items, such as an outbox.
+type OrderedCollection struct {
+	Context      interface{} `json:"@context,omitempty"`
+	ID           string      `json:"id"`
+	Type         string      `json:"type"`
+	TotalItems   int64       `json:"totalItems"`
+	OrderedItems interface{} `json:"orderedItems,omitempty"`
+}
+
+// NewOrderedCollection builds a collection with the given items.
+func NewOrderedCollection(id string, total int64, items interface{}) OrderedCollection {
+	return OrderedCollection{Context: Context, ID: id, Type: "OrderedCollection", TotalItems: total, OrderedItems: items}
+}
+
+// WebFinger is a WebFinger (RFC 7033) resource descriptor.
+type WebFinger struct {
+	Subject string   `json:"subject"`
+	Aliases []string `json:"aliases,omitempty"`
+	Links   []Link   `json:"links"`
+}
+
+// Link is a link of a WebFinger resource descriptor.
+type Link struct {
+	Rel  string `json:"rel"`
+	Type string `json:"type,omitempty"`
+	Href string `json:"href"`
+}
+
+// ParseAccount splits an "acct:user@host" WebFinger resource into its user and host.
+func ParseAccount(resource string) (string, string, error) {
+	account := strings.TrimPrefix(strings.TrimPrefix(resource, "acct:"), "@")
+
+	user, host, ok := strings.Cut(account, "@")
+	if !ok || user == "" || host == "" {
+		return "", "", errors.New("resource must be an acct: URI")
+	}
+	return user, host, nil
+}
+
+// FormatTime formats a timestamp the way ActivityPub documents expect.
+func FormatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,112 @@
+package activitypub
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jasen-devvv/mini-blog-backend/netsafe"
+)
+
+// maxResponseSize limits how much of a remote server's response is read.
+const maxResponseSize = 1 << 20
+
+// HTTPClient sends HTTP requests. *http.Client implements it; tests can point the
+// client at a local fake server or replace it entirely.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client fetches remote actors and delivers activities to remote inboxes.
+type Client struct {
+	HTTP      HTTPClient
+	UserAgent string
+}
+
+// DefaultClient is the client used for federation. It refuses to connect to loopback
+// and private addresses, so a remote request naming an internal URL (an inbox signature's
+// keyId, for instance) cannot make the server fetch it.
+var DefaultClient = &Client{
+	HTTP:      netsafe.NewHTTPClient(10 * time.Second),
+	UserAgent: "mini-blog-backend (ActivityPub)",
+}
+
+// FetchActor retrieves the actor document at the given URI, which must be an https URL
+// (or http, for local development).
+func (c *Client) FetchActor(ctx context.Context, uri string) (*Actor, error) {
+	if !netsafe.IsHTTPURL(uri) {
+		return nil, fmt.Errorf("fetching actor %q: not an http(s) URL", uri)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", ContentType)
+	req.Header.Set("User-Agent", c.UserAgent)
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching actor %s: unexpected status %s", uri, resp.Status)
+	}
+
+	var actor Actor
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&actor); err != nil {
+		return nil, fmt.Errorf("fetching actor %s: %w", uri, err)
+	}
+	if actor.ID != uri || actor.Inbox == "" {
+		return nil, fmt.Errorf("fetching actor %s: document is not the requested actor", uri)
+	}
+
+	return &actor, nil
+}
+
+// Deliver posts a signed activity to a remote inbox.
+func (c *Client) Deliver(ctx context.Context, inbox string, activity Activity, keyID string, key *rsa.PrivateKey) error {
+	body, err := json.Marshal(activity)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, inbox, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", ContentType)
+	req.Header.Set("User-Agent", c.UserAgent)
+	if err := Sign(req, body, keyID, key); err != nil {
+		return err
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseSize))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("delivering to %s: unexpected status %s", inbox, resp.Status)
+	}
+	return nil
+}
+
+// SameHost reports whether two URLs are http(s) URLs on the same host, such as a key ID
+// and the actor it claims to belong to.
+func SameHost(a, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	return errA == nil && errB == nil && netsafe.IsHTTPURL(a) && netsafe.IsHTTPURL(b) && strings.EqualFold(ua.Host, ub.Host)
+}
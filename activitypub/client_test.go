@@ -0,0 +1,96 @@
+package activitypub_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jasen-devvv/mini-blog-backend/activitypub"
+)
+
+// actorServer serves the actor "/users/alice", and at "/users/impostor" a document
+// claiming to be alice.
+func actorServer(t *testing.T) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != activitypub.ContentType {
+			http.Error(w, "not acceptable", http.StatusNotAcceptable)
+			return
+		}
+		id := server.URL + r.URL.Path
+		switch r.URL.Path {
+		case "/users/alice":
+		case "/users/impostor":
+			id = server.URL + "/users/alice"
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", activitypub.ContentType)
+		json.NewEncoder(w).Encode(activitypub.Actor{
+			ID:        id,
+			Type:      "Person",
+			Inbox:     id + "/inbox",
+			PublicKey: activitypub.PublicKey{ID: id + "#main-key", Owner: id, PublicKeyPem: "key"},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchActor(t *testing.T) {
+	server := actorServer(t)
+	client := &activitypub.Client{HTTP: server.Client(), UserAgent: "test"}
+	ctx := context.Background()
+
+	actor, err := client.FetchActor(ctx, server.URL+"/users/alice")
+	if err != nil {
+		t.Fatalf("fetching actor: %v", err)
+	}
+	if actor.Inbox != server.URL+"/users/alice/inbox" || actor.PublicKey.ID != server.URL+"/users/alice#main-key" {
+		t.Errorf("unexpected actor: %+v", actor)
+	}
+
+	for name, uri := range map[string]string{
+		"missing actor":       server.URL + "/users/nobody",
+		"document of another": server.URL + "/users/impostor",
+		"not an http(s) URL":  "file:///etc/passwd",
+		"relative URL":        "/users/alice",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if actor, err := client.FetchActor(ctx, uri); err == nil {
+				t.Errorf("FetchActor(%q) = %+v, want an error", uri, actor)
+			}
+		})
+	}
+}
+
+func TestDefaultClientRefusesPrivateAddresses(t *testing.T) {
+	server := actorServer(t)
+
+	_, err := activitypub.DefaultClient.FetchActor(context.Background(), server.URL+"/users/alice")
+	if err == nil || !strings.Contains(err.Error(), "refusing to connect") {
+		t.Fatalf("fetching a loopback actor: %v, want a refused connection", err)
+	}
+}
+
+func TestSameHost(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"https://remote.example/users/alice#main-key", "https://remote.example/users/alice", true},
+		{"https://REMOTE.example/key", "https://remote.example/users/alice", true},
+		{"https://evil.example/key", "https://remote.example/users/alice", false},
+		{"https://remote.example:8443/key", "https://remote.example/users/alice", false},
+		{"ftp://remote.example/key", "https://remote.example/users/alice", false},
+	}
+	for _, tt := range tests {
+		if got := activitypub.SameHost(tt.a, tt.b); got != tt.want {
+			t.Errorf("SameHost(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package activitypub
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// MaxClockSkew is how far the Date of a signed request may be from the current time.
+// It bounds how long a captured request could be replayed to the same inbox.
+const MaxClockSkew = 5 * time.Minute
+
+// requiredHeaders must be covered by every signature, binding it to the method, path,
+// host and time of the request.
+var requiredHeaders = []string{"(request-target)", "host", "date"}
+
+// KeyLookup returns the public key identified by a signature's keyId.
+type KeyLookup func(keyID string) (*rsa.PublicKey, error)
+
+// Sign signs a request following the HTTP Signatures draft (draft-cavage-http-signatures)
+// as implemented by Mastodon: rsa-sha256 over the request target, host, date and,
+// for requests with a body, a SHA-256 digest of the body.
+func Sign(req *http.Request, body []byte, keyID string, key *rsa.PrivateKey) error {
+	if req.Header.Get("Date") == "" {
+		req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	}
+
+	headers := []string{"(request-target)", "host", "date"}
+	if body != nil {
+		req.Header.Set("Digest", digest(body))
+		headers = append(headers, "digest")
+	}
+
+	hashed := sha256.Sum256([]byte(signingString(req, req.URL.Host, headers)))
+	signature, err := rsa.SignPKCS1v15(nil, key, crypto.SHA256, hashed[:])
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Signature", fmt.Sprintf(`keyId="%s",algorithm="rsa-sha256",headers="%s",signature="%s"`,
+		keyID, strings.Join(headers, " "), base64.StdEncoding.EncodeToString(signature)))
+	return nil
+}
+
+// Verify checks the signature of an incoming request and returns the keyId it was signed with.
+//
+// The signature must cover the request target, Host and Date headers, so a captured
+// signature cannot be replayed to another inbox or path. The Date header must be within
+// MaxClockSkew, and requests with a body must sign a Digest header matching the body,
+// so it cannot be replayed with different content either.
+func Verify(req *http.Request, body []byte, lookup KeyLookup) (string, error) {
+	params, err := parseSignature(req.Header.Get("Signature"))
+	if err != nil {
+		return "", err
+	}
+
+	keyID := params["keyId"]
+	if keyID == "" || params["signature"] == "" {
+		return "", errors.New("signature is missing keyId or signature")
+	}
+	if algorithm := params["algorithm"]; algorithm != "" && algorithm != "rsa-sha256" && algorithm != "hs2019" {
+		return "", fmt.Errorf("unsupported signature algorithm %q", algorithm)
+	}
+
+	// The target, host, date and, for requests with a body, the digest must be covered by
+	// the signature
+	headers := strings.Fields(strings.ToLower(params["headers"]))
+	for _, name := range requiredHeaders {
+		if !contains(headers, name) {
+			return "", fmt.Errorf("signature must cover %s", name)
+		}
+	}
+	date, err := http.ParseTime(req.Header.Get("Date"))
+	if err != nil {
+		return "", errors.New("signature must cover a valid Date header")
+	}
+	if skew := time.Since(date); skew > MaxClockSkew || skew < -MaxClockSkew {
+		return "", errors.New("request date is too far from the current time")
+	}
+	if len(body) > 0 {
+		if !contains(headers, "digest") || req.Header.Get("Digest") != digest(body) {
+			return "", errors.New("signature must cover a Digest header matching the body")
+		}
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(params["signature"])
+	if err != nil {
+		return "", errors.New("signature is not valid base64")
+	}
+
+	key, err := lookup(keyID)
+	if err != nil {
+		return "", err
+	}
+
+	hashed := sha256.Sum256([]byte(signingString(req, req.Host, headers)))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hashed[:], signature); err != nil {
+		return "", errors.New("signature does not match")
+	}
+
+	return keyID, nil
+}
+
+// signingString builds the string covered by a signature from the listed headers.
+func signingString(req *http.Request, host string, headers []string) string {
+	lines := make([]string, len(headers))
+	for i, name := range headers {
+		switch name {
+		case "(request-target)":
+			lines[i] = fmt.Sprintf("(request-target): %s %s", strings.ToLower(req.Method), req.URL.RequestURI())
+		case "host":
+			lines[i] = "host: " + host
+		default:
+			lines[i] = fmt.Sprintf("%s: %s", name, req.Header.Get(name))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseSignature parses the comma separated key="value" pairs of a Signature header.
+func parseSignature(header string) (map[string]string, error) {
+	if header == "" {
+		return nil, errors.New("request is not signed")
+	}
+
+	params := map[string]string{}
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, errors.New("malformed Signature header")
+		}
+		params[key] = strings.Trim(value, `"`)
+	}
+	return params, nil
+}
+
+// digest returns the Digest header value for a body.
+func digest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "SHA-256=" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,159 @@
+package activitypub_test
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jasen-devvv/mini-blog-backend/activitypub"
+)
+
+const testKeyID = "https://remote.example/users/alice#main-key"
+
+var (
+	keyOnce sync.Once
+	testKey *rsa.PrivateKey
+)
+
+// signingKey returns the key the test requests are signed with, generated once.
+func signingKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	keyOnce.Do(func() {
+		_, private, err := activitypub.GenerateKey()
+		if err != nil {
+			t.Fatalf("generating key: %v", err)
+		}
+		if testKey, err = activitypub.ParsePrivateKey(private); err != nil {
+			t.Fatalf("parsing key: %v", err)
+		}
+	})
+	if testKey == nil {
+		t.Fatal("no signing key")
+	}
+	return testKey
+}
+
+// inboxServer starts a server verifying the signature of every request, answering
+// 202 Accepted with the keyId or 401 Unauthorized with the verification error.
+func inboxServer(t *testing.T) *httptest.Server {
+	key := signingKey(t)
+	lookup := func(keyID string) (*rsa.PublicKey, error) {
+		if keyID != testKeyID {
+			return nil, errors.New("unknown key")
+		}
+		return &key.PublicKey, nil
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		keyID, err := activitypub.Verify(r, body, lookup)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		io.WriteString(w, keyID)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// signedRequest builds a POST request to url signed over body.
+func signedRequest(t *testing.T, url string, body []byte) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := activitypub.Sign(req, body, testKeyID, signingKey(t)); err != nil {
+		t.Fatalf("signing request: %v", err)
+	}
+	return req
+}
+
+// send sends req with body and returns the status and response body.
+func send(t *testing.T, req *http.Request, body []byte) (int, string) {
+	t.Helper()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	response, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(response)
+}
+
+func TestVerify(t *testing.T) {
+	server := inboxServer(t)
+	body := []byte(`{"type":"Follow"}`)
+
+	status, response := send(t, signedRequest(t, server.URL+"/users/bob/inbox", body), body)
+	if status != http.StatusAccepted || response != testKeyID {
+		t.Fatalf("valid signature: status %d, %q", status, response)
+	}
+
+	tests := []struct {
+		name    string
+		request func() (*http.Request, []byte)
+		want    string
+	}{
+		{"replayed to another target", func() (*http.Request, []byte) {
+			req := signedRequest(t, server.URL+"/users/bob/inbox", body)
+			replayed, _ := http.NewRequest(http.MethodPost, server.URL+"/users/carol/inbox", nil)
+			replayed.Header = req.Header.Clone()
+			return replayed, body
+		}, "signature does not match"},
+		{"replayed to another host", func() (*http.Request, []byte) {
+			req := signedRequest(t, server.URL+"/users/bob/inbox", body)
+			req.Host = "other.example"
+			return req, body
+		}, "signature does not match"},
+		{"body does not match the digest", func() (*http.Request, []byte) {
+			return signedRequest(t, server.URL+"/users/bob/inbox", body), []byte(`{"type":"Delete"}`)
+		}, "Digest"},
+		{"date too old", func() (*http.Request, []byte) {
+			req, _ := http.NewRequest(http.MethodPost, server.URL+"/users/bob/inbox", nil)
+			req.Header.Set("Date", time.Now().Add(-activitypub.MaxClockSkew-time.Minute).UTC().Format(http.TimeFormat))
+			activitypub.Sign(req, body, testKeyID, signingKey(t))
+			return req, body
+		}, "too far"},
+		{"date in the future", func() (*http.Request, []byte) {
+			req, _ := http.NewRequest(http.MethodPost, server.URL+"/users/bob/inbox", nil)
+			req.Header.Set("Date", time.Now().Add(activitypub.MaxClockSkew+time.Minute).UTC().Format(http.TimeFormat))
+			activitypub.Sign(req, body, testKeyID, signingKey(t))
+			return req, body
+		}, "too far"},
+		{"target not signed", func() (*http.Request, []byte) {
+			req := signedRequest(t, server.URL+"/users/bob/inbox", body)
+			req.Header.Set("Signature", strings.Replace(req.Header.Get("Signature"), `headers="(request-target) `, `headers="`, 1))
+			return req, body
+		}, "must cover (request-target)"},
+		{"unknown key", func() (*http.Request, []byte) {
+			req := signedRequest(t, server.URL+"/users/bob/inbox", body)
+			req.Header.Set("Signature", strings.Replace(req.Header.Get("Signature"), testKeyID, "https://remote.example/users/mallory#main-key", 1))
+			return req, body
+		}, "unknown key"},
+		{"unsigned", func() (*http.Request, []byte) {
+			req, _ := http.NewRequest(http.MethodPost, server.URL+"/users/bob/inbox", nil)
+			return req, body
+		}, "not signed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, body := tt.request()
+			status, response := send(t, req, body)
+			if status != http.StatusUnauthorized || !strings.Contains(response, tt.want) {
+				t.Errorf("status %d, %q; want 401 mentioning %q", status, strings.TrimSpace(response), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+// Package apierror describes failed requests and renders them as RFC 7807 problem
+// details ("application/problem+json").
+//
+// Handlers report a failure by attaching an *Error to the request with ctx.Error and
+// returning; middleware.ErrorMiddleware renders it once the handler chain is done. Every
+// error has a stable, machine-readable code that clients can rely on, while the detail is
+// a human-readable message that may change. The cause of an error is logged with the
+// request but never sent to the client.
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/tracing"
+)
+
+// ContentType is the media type of problem details responses.
+const ContentType = "application/problem+json"
+
+// Error is a failed request.
+//
+// Fields:
+//   - Status: HTTP status code of the response.
+//   - Code: Stable, machine-readable code of the error (e.g. "not_found").
+//   - Detail: Human-readable explanation of this occurrence of the error.
+//   - Fields: Invalid fields of the request, for validation errors.
+//   - Extensions: Additional members of the response, such as the "lock" naming the holder
+//     of an article's edit lock in 423 Locked responses.
+//   - Err: Cause of the error, logged but not sent to the client.
+type Error struct {
+	Status     int
+	Code       string
+	Detail     string
+	Fields     []FieldError
+	Extensions map[string]interface{}
+	Err        error
+}
+
+// FieldError is an invalid field of a request.
+//
+// Fields:
+//   - Field: Path of the field in the request, such as "email" or "links[0].url".
+//   - Code: Rule the value broke, such as "required" or "max".
+//   - Message: Human-readable explanation, such as "must be at most 100 characters".
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Detail + ": " + e.Err.Error()
+	}
+	return e.Detail
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithCode replaces the code of the error with a more specific one and returns the error.
+func (e *Error) WithCode(code string) *Error {
+	e.Code = code
+	return e
+}
+
+// With adds a member to the response and returns the error. Names of the standard
+// members (see Problem) are ignored.
+func (e *Error) With(name string, value interface{}) *Error {
+	if e.Extensions == nil {
+		e.Extensions = map[string]interface{}{}
+	}
+	e.Extensions[name] = value
+	return e
+}
+
+// codes are the default codes of errors by status.
+var codes = map[int]string{
+	http.StatusBadRequest:            "bad_request",
+	http.StatusUnauthorized:          "unauthorized",
+	http.StatusForbidden:             "forbidden",
+	http.StatusNotFound:              "not_found",
+	http.StatusMethodNotAllowed:      "method_not_allowed",
+	http.StatusNotAcceptable:         "not_acceptable",
+	http.StatusConflict:              "conflict",
+	http.StatusGone:                  "gone",
+	http.StatusPreconditionFailed:    "precondition_failed",
+	http.StatusRequestEntityTooLarge: "payload_too_large",
+	http.StatusUnsupportedMediaType:  "unsupported_media_type",
+	http.StatusUnprocessableEntity:   "unprocessable_entity",
+	http.StatusLocked:                "locked",
+	http.StatusPreconditionRequired:  "precondition_required",
+	http.StatusTooManyRequests:       "rate_limited",
+	http.StatusInternalServerError:   "internal_error",
+	http.StatusBadGateway:            "bad_gateway",
+	http.StatusServiceUnavailable:    "unavailable",
+	http.StatusGatewayTimeout:        "gateway_timeout",
+}
+
+// New returns an error with the given status and detail, and the default code of the
+// status. Use WithCode for a more specific code.
+func New(status int, detail string) *Error {
+	code, ok := codes[status]
+	if !ok {
+		code = "error"
+	}
+	return &Error{Status: status, Code: code, Detail: detail}
+}
+
+// Internal returns a 500 Internal Server Error caused by err. Only the detail is sent to
+// the client.
+func Internal(err error, detail string) *Error {
+	e := New(http.StatusInternalServerError, detail)
+	e.Err = err
+	return e
+}
+
+// Problem is the body of a problem details response (RFC 7807), extended with the
+// error's code, the request's trace ID, the invalid fields and the error's extensions.
+type Problem struct {
+	Type       string                 `json:"type"`
+	Title      string                 `json:"title"`
+	Status     int                    `json:"status"`
+	Detail     string                 `json:"detail,omitempty"`
+	Instance   string                 `json:"instance,omitempty"`
+	Code       string                 `json:"code"`
+	TraceID    string                 `json:"trace_id,omitempty"`
+	Errors     []FieldError           `json:"errors,omitempty"`
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// MarshalJSON encodes the problem with its extensions as members of the same object.
+// Extensions cannot replace the standard members.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem
+	data, err := json.Marshal(problem(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return data, err
+	}
+
+	members := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+	for name, value := range p.Extensions {
+		if _, taken := members[name]; taken {
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		members[name] = encoded
+	}
+	return json.Marshal(members)
+}
+
+// Render writes err as a problem details response and aborts the request. Errors that are
+// not an *Error become 500 Internal Server Error responses.
+func Render(ctx *gin.Context, err error) {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		apiErr = Internal(err, "Internal server error")
+	}
+
+	problem := Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(apiErr.Status),
+		Status:   apiErr.Status,
+		Detail:   apiErr.Detail,
+		Instance: ctx.Request.URL.Path,
+		Code:     apiErr.Code,
+		Errors:   apiErr.Fields,
+
+		Extensions: apiErr.Extensions,
+	}
+	if spanContext := tracing.SpanContextFromContext(ctx.Request.Context()); spanContext.IsValid() {
+		problem.TraceID = spanContext.TraceID.String()
+	}
+
+	ctx.Header("Content-Type", ContentType)
+	ctx.AbortWithStatusJSON(apiErr.Status, problem)
+}
@@ -0,0 +1,57 @@
+package apierror_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/apierror"
+)
+
+func TestRenderExtensions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPut, "/api/articles/1", nil)
+
+	err := apierror.New(http.StatusLocked, "Article is being edited by another user").
+		With("lock", map[string]string{"user": "bob"}).
+		With("status", 200)
+	apierror.Render(c, err)
+
+	if w.Code != http.StatusLocked || w.Header().Get("Content-Type") != apierror.ContentType {
+		t.Fatalf("response = %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decoding %s: %v", w.Body, err)
+	}
+
+	// Extensions are members of the problem, next to the standard ones they cannot replace
+	lock, _ := body["lock"].(map[string]interface{})
+	if lock["user"] != "bob" {
+		t.Errorf("lock = %v, want the holder", body["lock"])
+	}
+	if body["status"] != float64(http.StatusLocked) || body["code"] != "locked" || body["instance"] != "/api/articles/1" {
+		t.Errorf("standard members = %v", body)
+	}
+}
+
+func TestRenderWithoutExtensions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/articles/1", nil)
+
+	apierror.Render(c, apierror.New(http.StatusNotFound, "Article not found"))
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decoding %s: %v", w.Body, err)
+	}
+	if len(body) != 6 || body["detail"] != "Article not found" || body["code"] != "not_found" {
+		t.Errorf("problem = %v, want type, title, status, detail, instance and code only", body)
+	}
+}
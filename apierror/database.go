@@ -0,0 +1,50 @@
+package apierror
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jasen-devvv/mini-blog-backend/repository"
+	"gorm.io/gorm"
+)
+
+// FromDB maps an error from a GORM query or a repository to the matching response:
+//
+//   - missing records are 404 Not Found, with the notFound detail;
+//   - unique and foreign key violations are 409 Conflict;
+//   - values breaking a column's type, length or check are 400 Bad Request;
+//   - timeouts are 503 Service Unavailable;
+//   - anything else is 500 Internal Server Error.
+func FromDB(err error, notFound string) *Error {
+	var pgErr *pgconn.PgError
+	var e *Error
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, repository.ErrNotFound):
+		e = New(http.StatusNotFound, notFound)
+	case errors.Is(err, repository.ErrConflict):
+		e = New(http.StatusConflict, "The record conflicts with existing data")
+	case errors.As(err, &pgErr):
+		switch pgErr.Code {
+		case "23505": // unique_violation
+			e = New(http.StatusConflict, "The record already exists").WithCode("already_exists")
+		case "23503": // foreign_key_violation
+			e = New(http.StatusConflict, "The record refers to or is referenced by other records")
+		case "22001", "22003", "22P02", "23502", "23514": // value too long, out of range, invalid text, not null, check
+			e = New(http.StatusBadRequest, "A value of the request is invalid").WithCode("invalid_value")
+		case "57014": // query_canceled, after a statement timeout
+			e = New(http.StatusServiceUnavailable, "The database did not respond in time")
+		default:
+			e = New(http.StatusInternalServerError, "Internal server error")
+		}
+	case errors.Is(err, context.DeadlineExceeded):
+		e = New(http.StatusServiceUnavailable, "The database did not respond in time")
+	default:
+		e = New(http.StatusInternalServerError, "Internal server error")
+	}
+
+	e.Err = err
+	return e
+}
@@ -0,0 +1,135 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// Report fields by the names clients send, not the names of the Go struct fields
+	if validate, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		validate.RegisterTagNameFunc(fieldName)
+	}
+}
+
+// fieldName returns the name of a field in requests: its JSON name, or its form name for
+// query parameters.
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// Validation returns a 400 Bad Request for an error from binding a request, such as
+// ctx.ShouldBindJSON. Invalid fields are listed with the rule each broke, and malformed
+// JSON is reported without the decoder's message.
+func Validation(err error) *Error {
+	var validationErrors validator.ValidationErrors
+	var typeError *json.UnmarshalTypeError
+	var syntaxError *json.SyntaxError
+
+	switch {
+	case errors.As(err, &validationErrors):
+		e := New(http.StatusBadRequest, "The request has invalid fields").WithCode("validation_failed")
+		for _, fieldError := range validationErrors {
+			e.Fields = append(e.Fields, FieldError{
+				Field:   fieldPath(fieldError.Namespace()),
+				Code:    fieldError.Tag(),
+				Message: ruleMessage(fieldError),
+			})
+		}
+		e.Err = err
+		return e
+
+	case errors.As(err, &typeError):
+		e := New(http.StatusBadRequest, "The request has invalid fields").WithCode("validation_failed")
+		e.Fields = []FieldError{{
+			Field:   typeError.Field,
+			Code:    "type",
+			Message: "must be " + jsonType(typeError.Type),
+		}}
+		e.Err = err
+		return e
+
+	case errors.As(err, &syntaxError), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		e := New(http.StatusBadRequest, "The request body must be valid JSON").WithCode("invalid_json")
+		e.Err = err
+		return e
+	}
+
+	e := New(http.StatusBadRequest, "The request is invalid")
+	e.Err = err
+	return e
+}
+
+// fieldPath removes the name of the request struct from a validator namespace, turning
+// "ArticleInput.links[0].url" into "links[0].url".
+func fieldPath(namespace string) string {
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
+	}
+	return namespace
+}
+
+// ruleMessage explains the validation rule a field broke.
+func ruleMessage(fieldError validator.FieldError) string {
+	param := fieldError.Param()
+	kind := fieldError.Kind()
+
+	switch fieldError.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "url", "http_url":
+		return "must be a valid URL"
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(param), ", ")
+	case "min", "max", "len":
+		bound := map[string]string{"min": "at least", "max": "at most", "len": "exactly"}[fieldError.Tag()]
+		switch kind {
+		case reflect.String:
+			return fmt.Sprintf("must be %s %s characters long", bound, param)
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return fmt.Sprintf("must have %s %s items", bound, param)
+		}
+		return fmt.Sprintf("must be %s %s", bound, param)
+	case "gt", "gte", "lt", "lte":
+		comparison := map[string]string{"gt": "greater than", "gte": "at least", "lt": "less than", "lte": "at most"}[fieldError.Tag()]
+		return fmt.Sprintf("must be %s %s", comparison, param)
+	}
+	return "is invalid"
+}
+
+// jsonType names the JSON type that decodes into a Go type.
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	}
+	return "an object"
+}
@@ -0,0 +1,66 @@
+// Package background runs work that outlives the request that started it, such as sending
+// webmentions, delivering activities and writing exports, so the server can wait for it
+// when shutting down instead of cutting it off.
+//
+// Short tasks run with Go and are allowed to finish. Long-running workers, such as the
+// export cleanup loop, also watch Context and return once shutdown begins.
+package background
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+var (
+	mu          sync.Mutex
+	tasks       sync.WaitGroup
+	stopped     bool
+	ctx, cancel = context.WithCancel(context.Background())
+)
+
+// Go runs fn in a new goroutine that Shutdown waits for. Once shutdown has begun, fn is
+// not run.
+func Go(fn func()) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if stopped {
+		slog.Warn("background task dropped during shutdown")
+		return
+	}
+
+	tasks.Add(1)
+	go func() {
+		defer tasks.Done()
+		fn()
+	}()
+}
+
+// Context returns a context that is cancelled when shutdown begins. Long-running workers
+// return when it is done.
+func Context() context.Context {
+	return ctx
+}
+
+// Shutdown stops accepting tasks, cancels Context and waits for the running tasks to
+// return. It gives up when ctx is done, returning its error.
+func Shutdown(ctx context.Context) error {
+	mu.Lock()
+	stopped = true
+	mu.Unlock()
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		tasks.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,296 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jasen-devvv/mini-blog-backend/ratelimit"
+	"github.com/joho/godotenv"
+)
+
+// Config holds the application settings.
+//
+// Fields:
+//   - Port: Port the HTTP server listens on (PORT, -port).
+//   - DatabaseURL: PostgreSQL connection string (DB_URL, -db-url).
+//   - JWTSecret: Secret used to sign authentication tokens (JWT_SECRET).
+//   - SiteURL: Public URL of the blog frontend, used in feeds, sitemaps and notifications
+//     (SITE_URL, -site-url).
+//   - SiteName: Name of the blog shown in feeds and metadata (SITE_NAME).
+//   - APIURL: Public URL of this API, used in federation IDs and download links
+//     (API_URL, -api-url).
+//   - PubSubBroker: Real-time event broker, "memory" or "postgres" (PUBSUB_BROKER).
+//   - MigrateOnStart: Apply pending migrations when the server starts
+//     (MIGRATE_ON_START, -migrate).
+//   - ExportDir: Directory data exports are written to (EXPORT_DIR).
+//   - MetricsToken: Bearer token required to read /metrics; the endpoint is public when
+//     empty (METRICS_TOKEN).
+//   - TracesExporter: Where traces are sent: none, stdout, file or otlp
+//     (OTEL_TRACES_EXPORTER, -traces).
+//   - TracesFile: File the "file" traces exporter appends to (TRACES_FILE).
+//   - OTLPEndpoint: Base URL of the OpenTelemetry collector for the "otlp" exporter
+//     (OTEL_EXPORTER_OTLP_ENDPOINT).
+//   - ServiceName: Name of this service in traces (OTEL_SERVICE_NAME).
+//   - LogLevel: Minimum level of logged entries: debug, info, warn or error
+//     (LOG_LEVEL, -log-level).
+//   - ShutdownTimeout: How long a stopping server waits for requests and background work
+//     to finish (SHUTDOWN_TIMEOUT).
+//   - RateLimits: Rate limit policies by name, written as "name=limit/period:key"
+//     (RATE_LIMITS; see ratelimit.ParsePolicies).
+//   - RateLimitStore: Where rate limit buckets are kept, "memory" or "postgres" to share
+//     them between instances (RATE_LIMIT_STORE).
+//   - TrustedProxies: Addresses or CIDR ranges of the reverse proxies whose
+//     X-Forwarded-For header gives the client's IP address; none when empty
+//     (TRUSTED_PROXIES).
+type Config struct {
+	Port            string
+	DatabaseURL     string
+	JWTSecret       string
+	SiteURL         string
+	SiteName        string
+	APIURL          string
+	PubSubBroker    string
+	MigrateOnStart  bool
+	ExportDir       string
+	MetricsToken    string
+	TracesExporter  string
+	TracesFile      string
+	OTLPEndpoint    string
+	ServiceName     string
+	LogLevel        slog.Level
+	ShutdownTimeout time.Duration
+	RateLimits      map[string]ratelimit.Policy
+	RateLimitStore  string
+	TrustedProxies  []string
+}
+
+// setting describes where a configuration value comes from.
+type setting struct {
+	env      string
+	flag     string // empty when the setting has no flag
+	usage    string
+	fallback string
+	set      func(value string) error
+}
+
+// settings lists every setting of the configuration, writing into c.
+func (c *Config) settings() []setting {
+	text := func(target *string) func(string) error {
+		return func(value string) error {
+			*target = value
+			return nil
+		}
+	}
+	baseURL := func(target *string) func(string) error {
+		return func(value string) error {
+			*target = strings.TrimRight(value, "/")
+			return nil
+		}
+	}
+
+	return []setting{
+		{"PORT", "port", "port the HTTP server listens on", "8080", text(&c.Port)},
+		{"DB_URL", "db-url", "PostgreSQL connection string", "", text(&c.DatabaseURL)},
+		{"JWT_SECRET", "", "secret used to sign authentication tokens", "", text(&c.JWTSecret)},
+		{"SITE_URL", "site-url", "public URL of the blog frontend", "", baseURL(&c.SiteURL)},
+		{"SITE_NAME", "", "name of the blog", "Mini Blog", text(&c.SiteName)},
+		{"API_URL", "api-url", "public URL of this API", "", baseURL(&c.APIURL)},
+		{"PUBSUB_BROKER", "", "real-time event broker: memory or postgres", "memory", text(&c.PubSubBroker)},
+		{"MIGRATE_ON_START", "migrate", "apply pending migrations on startup", "false", func(value string) error {
+			enabled, err := strconv.ParseBool(value)
+			c.MigrateOnStart = enabled
+			return err
+		}},
+		{"EXPORT_DIR", "", "directory data exports are written to", filepath.Join(os.TempDir(), "mini-blog-exports"), text(&c.ExportDir)},
+		{"METRICS_TOKEN", "", "bearer token required to read /metrics", "", text(&c.MetricsToken)},
+		{"OTEL_TRACES_EXPORTER", "traces", "where traces are sent: none, stdout, file or otlp", "none", text(&c.TracesExporter)},
+		{"TRACES_FILE", "", "file the file traces exporter appends to", "traces.jsonl", text(&c.TracesFile)},
+		{"OTEL_EXPORTER_OTLP_ENDPOINT", "", "base URL of the OpenTelemetry collector", "http://localhost:4318", baseURL(&c.OTLPEndpoint)},
+		{"OTEL_SERVICE_NAME", "", "name of this service in traces", "mini-blog-backend", text(&c.ServiceName)},
+		{"LOG_LEVEL", "log-level", "minimum log level: debug, info, warn or error", "info", func(value string) error {
+			return c.LogLevel.UnmarshalText([]byte(value))
+		}},
+		{"SHUTDOWN_TIMEOUT", "", "how long shutdown waits for requests and background work, e.g. 30s", "30s", func(value string) error {
+			timeout, err := time.ParseDuration(value)
+			c.ShutdownTimeout = timeout
+			return err
+		}},
+		{"RATE_LIMITS", "", "rate limit policies, e.g. login=10/1m:ip,comment=10/1m:user", "register=5/1h:ip,login=10/1m:ip,comment=10/1m:user", func(value string) error {
+			policies, err := ratelimit.ParsePolicies(value)
+			c.RateLimits = policies
+			return err
+		}},
+		{"RATE_LIMIT_STORE", "", "where rate limit buckets are kept: memory or postgres", "memory", text(&c.RateLimitStore)},
+		{"TRUSTED_PROXIES", "", "comma-separated addresses or CIDR ranges of trusted reverse proxies", "", func(value string) error {
+			c.TrustedProxies = nil
+			for _, proxy := range strings.Split(value, ",") {
+				if proxy = strings.TrimSpace(proxy); proxy != "" {
+					c.TrustedProxies = append(c.TrustedProxies, proxy)
+				}
+			}
+			return nil
+		}},
+	}
+}
+
+// Load reads the configuration from command-line flags, environment variables and an
+// optional env file, in that order of precedence, falling back to defaults. It returns
+// the configuration and the arguments left after the flags (the command to run, if any).
+//
+// The env file is ".env" when it exists, or the file given with -config or CONFIG_FILE,
+// which must then exist. Variables already set in the environment are never overridden
+// by the file, so containers can be configured with environment variables alone.
+func Load(args []string) (*Config, []string, error) {
+	cfg := &Config{}
+	settings := cfg.settings()
+
+	// Register a flag for the settings that have one
+	flags := flag.NewFlagSet("mini-blog-backend", flag.ContinueOnError)
+	configFile := flags.String("config", os.Getenv("CONFIG_FILE"), "env file to read settings from")
+	flagValues := map[string]*string{}
+	for _, s := range settings {
+		if s.flag != "" {
+			flagValues[s.flag] = flags.String(s.flag, "", fmt.Sprintf("%s (overrides %s)", s.usage, s.env))
+		}
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	setFlags := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+
+	// Read the env file
+	fileValues := map[string]string{}
+	path := *configFile
+	if path == "" {
+		path = ".env"
+	}
+	values, err := godotenv.Read(path)
+	if err == nil {
+		fileValues = values
+	} else if *configFile != "" || !errors.Is(err, os.ErrNotExist) {
+		return nil, nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	// Apply each setting from the first source that has it
+	for _, s := range settings {
+		value := s.fallback
+		if fileValue, ok := fileValues[s.env]; ok {
+			value = fileValue
+		}
+		if envValue, ok := os.LookupEnv(s.env); ok {
+			value = envValue
+		}
+		if setFlags[s.flag] {
+			value = *flagValues[s.flag]
+		}
+
+		if err := s.set(value); err != nil {
+			return nil, nil, fmt.Errorf("invalid %s: %q", s.env, value)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return cfg, flags.Args(), nil
+}
+
+// Validate checks that the configuration is consistent, reporting every problem at once.
+// The settings only the server needs may be empty; see ValidateServer. Load calls it,
+// so commands such as "migrate" only need DB_URL.
+func (c *Config) Validate() error {
+	return report(c.problems())
+}
+
+// ValidateServer checks that the configuration can run the server: on top of Validate,
+// it requires JWT_SECRET, SITE_URL and API_URL.
+func (c *Config) ValidateServer() error {
+	problems := c.problems()
+	if strings.TrimSpace(c.JWTSecret) == "" {
+		problems = append(problems, "JWT_SECRET is required")
+	}
+	if c.SiteURL == "" {
+		problems = append(problems, "SITE_URL is required")
+	}
+	if c.APIURL == "" {
+		problems = append(problems, "API_URL is required")
+	}
+	return report(problems)
+}
+
+// problems lists what is wrong with the configuration, apart from the settings only the
+// server requires.
+func (c *Config) problems() []string {
+	var problems []string
+
+	if c.DatabaseURL == "" {
+		problems = append(problems, "DB_URL is required")
+	}
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		problems = append(problems, "PORT must be a number between 1 and 65535")
+	}
+	if c.PubSubBroker != "memory" && c.PubSubBroker != "postgres" {
+		problems = append(problems, "PUBSUB_BROKER must be memory or postgres")
+	}
+	if !validBaseURL(c.SiteURL) {
+		problems = append(problems, "SITE_URL must be an http(s) URL")
+	}
+	if !validBaseURL(c.APIURL) {
+		problems = append(problems, "API_URL must be an http(s) URL")
+	}
+	if c.ExportDir == "" {
+		problems = append(problems, "EXPORT_DIR must not be empty")
+	}
+	switch c.TracesExporter {
+	case "none", "stdout":
+	case "file":
+		if c.TracesFile == "" {
+			problems = append(problems, "TRACES_FILE is required with the file traces exporter")
+		}
+	case "otlp":
+		if c.OTLPEndpoint == "" || !validBaseURL(c.OTLPEndpoint) {
+			problems = append(problems, "OTEL_EXPORTER_OTLP_ENDPOINT must be an http(s) URL")
+		}
+	default:
+		problems = append(problems, "OTEL_TRACES_EXPORTER must be none, stdout, file or otlp")
+	}
+	if c.RateLimitStore != "memory" && c.RateLimitStore != "postgres" {
+		problems = append(problems, "RATE_LIMIT_STORE must be memory or postgres")
+	}
+	for _, proxy := range c.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			problems = append(problems, fmt.Sprintf("TRUSTED_PROXIES entry %q is not an IP address or CIDR range", proxy))
+		}
+	}
+	if c.ShutdownTimeout <= 0 {
+		problems = append(problems, "SHUTDOWN_TIMEOUT must be a positive duration")
+	}
+	return problems
+}
+
+// report returns an error listing problems, or nil when there are none.
+func report(problems []string) error {
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// validBaseURL reports whether value is empty or an absolute http(s) URL.
+func validBaseURL(value string) bool {
+	if value == "" {
+		return true
+	}
+	u, err := url.Parse(value)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
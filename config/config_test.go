@@ -0,0 +1,212 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jasen-devvv/mini-blog-backend/config"
+)
+
+// clearEnv unsets the variables read by Load for the duration of the test, so settings
+// from the machine running the tests do not leak in.
+func clearEnv(t *testing.T) {
+	for _, name := range []string{
+		"CONFIG_FILE", "PORT", "DB_URL", "JWT_SECRET", "SITE_URL", "SITE_NAME", "API_URL",
+		"PUBSUB_BROKER", "MIGRATE_ON_START", "EXPORT_DIR", "METRICS_TOKEN", "OTEL_TRACES_EXPORTER",
+		"TRACES_FILE", "OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_SERVICE_NAME", "LOG_LEVEL",
+		"SHUTDOWN_TIMEOUT", "RATE_LIMITS", "RATE_LIMIT_STORE", "TRUSTED_PROXIES",
+	} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+}
+
+// writeEnvFile writes an env file with the given content and returns its path.
+func writeEnvFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "test.env")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("writing env file: %v", err)
+	}
+	return path
+}
+
+const requiredSettings = "DB_URL=postgres://localhost/blog\nJWT_SECRET=secret\nSITE_URL=https://blog.example\nAPI_URL=https://api.blog.example/\n"
+
+func TestLoadPrecedence(t *testing.T) {
+	clearEnv(t)
+	path := writeEnvFile(t, requiredSettings+"PORT=7000\nSITE_NAME=From File\nLOG_LEVEL=warn\nMETRICS_TOKEN=file-token\n")
+	t.Setenv("PORT", "7100")
+	t.Setenv("SITE_NAME", "From Env")
+
+	cfg, args, err := config.Load([]string{"-config", path, "-port", "7200", "serve", "-x"})
+	if err != nil {
+		t.Fatalf("loading: %v", err)
+	}
+
+	// The flag beats the environment and the file
+	if cfg.Port != "7200" {
+		t.Errorf("Port = %q, want the flag value 7200", cfg.Port)
+	}
+	// The environment beats the file
+	if cfg.SiteName != "From Env" {
+		t.Errorf("SiteName = %q, want the environment value", cfg.SiteName)
+	}
+	// The file beats the default
+	if cfg.MetricsToken != "file-token" || cfg.LogLevel.String() != "WARN" {
+		t.Errorf("MetricsToken = %q, LogLevel = %v, want the file values", cfg.MetricsToken, cfg.LogLevel)
+	}
+	// Unset settings take their default
+	if cfg.PubSubBroker != "memory" || cfg.ShutdownTimeout != 30*time.Second || cfg.RateLimits["login"].Limit != 10 {
+		t.Errorf("unexpected defaults: broker %q, shutdown timeout %v, rate limits %v", cfg.PubSubBroker, cfg.ShutdownTimeout, cfg.RateLimits)
+	}
+	// Base URLs lose their trailing slash
+	if cfg.APIURL != "https://api.blog.example" {
+		t.Errorf("APIURL = %q, want it without a trailing slash", cfg.APIURL)
+	}
+	if strings.Join(args, " ") != "serve -x" {
+		t.Errorf("args = %q, want the arguments after the flags", args)
+	}
+}
+
+func TestLoadOverrides(t *testing.T) {
+	clearEnv(t)
+	path := writeEnvFile(t, requiredSettings+"METRICS_TOKEN=file-token\n")
+	t.Setenv("SITE_URL", "https://env.example")
+
+	cfg, _, err := config.Load([]string{"-config", path, "-site-url", "https://flag.example/"})
+	if err != nil {
+		t.Fatalf("loading: %v", err)
+	}
+	if cfg.SiteURL != "https://flag.example" {
+		t.Errorf("SiteURL = %q, want the flag value", cfg.SiteURL)
+	}
+
+	// A variable set to an empty value still overrides the file
+	t.Setenv("METRICS_TOKEN", "")
+	cfg, _, err = config.Load([]string{"-config", path})
+	if err != nil {
+		t.Fatalf("loading: %v", err)
+	}
+	if cfg.MetricsToken != "" {
+		t.Errorf("MetricsToken = %q, want the empty environment value", cfg.MetricsToken)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	clearEnv(t)
+
+	if _, _, err := config.Load([]string{"-config", filepath.Join(t.TempDir(), "missing.env")}); err == nil {
+		t.Error("loading a missing -config file succeeded")
+	}
+
+	path := writeEnvFile(t, requiredSettings+"SHUTDOWN_TIMEOUT=soon\n")
+	if _, _, err := config.Load([]string{"-config", path}); err == nil || !strings.Contains(err.Error(), "SHUTDOWN_TIMEOUT") {
+		t.Errorf("loading an invalid duration: got %v, want an error naming SHUTDOWN_TIMEOUT", err)
+	}
+
+	path = writeEnvFile(t, "PORT=8080\n")
+	if _, _, err := config.Load([]string{"-config", path}); err == nil || !strings.Contains(err.Error(), "DB_URL is required") {
+		t.Errorf("loading without required settings: got %v, want a validation error", err)
+	}
+
+	// Commands only need the database
+	path = writeEnvFile(t, "DB_URL=postgres://localhost/blog\n")
+	if _, args, err := config.Load([]string{"-config", path, "migrate", "status"}); err != nil || strings.Join(args, " ") != "migrate status" {
+		t.Errorf("loading for a command: got %v (args %q), want no error", err, args)
+	}
+
+	if _, _, err := config.Load([]string{"-config", path, "-unknown"}); err == nil {
+		t.Error("loading with an unknown flag succeeded")
+	}
+}
+
+// validConfig returns a configuration that passes ValidateServer.
+func validConfig() config.Config {
+	return config.Config{
+		Port:            "8080",
+		DatabaseURL:     "postgres://localhost/blog",
+		JWTSecret:       "secret",
+		SiteURL:         "https://blog.example",
+		APIURL:          "https://api.blog.example",
+		PubSubBroker:    "memory",
+		ExportDir:       "/tmp/exports",
+		TracesExporter:  "none",
+		ShutdownTimeout: 30 * time.Second,
+		RateLimitStore:  "memory",
+		TrustedProxies:  []string{"10.0.0.0/8", "192.168.1.1", "::1"},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := validConfig()
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("validating a valid configuration: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		change  func(c *config.Config)
+		problem string
+	}{
+		{"missing database", func(c *config.Config) { c.DatabaseURL = "" }, "DB_URL is required"},
+		{"port out of range", func(c *config.Config) { c.Port = "70000" }, "PORT must be"},
+		{"port not a number", func(c *config.Config) { c.Port = "http" }, "PORT must be"},
+		{"unknown broker", func(c *config.Config) { c.PubSubBroker = "redis" }, "PUBSUB_BROKER must be"},
+		{"relative site URL", func(c *config.Config) { c.SiteURL = "blog.example" }, "SITE_URL must be"},
+		{"non-http API URL", func(c *config.Config) { c.APIURL = "ftp://api.blog.example" }, "API_URL must be"},
+		{"empty export dir", func(c *config.Config) { c.ExportDir = "" }, "EXPORT_DIR must not be empty"},
+		{"file exporter without file", func(c *config.Config) { c.TracesExporter, c.TracesFile = "file", "" }, "TRACES_FILE is required"},
+		{"otlp exporter without endpoint", func(c *config.Config) { c.TracesExporter = "otlp" }, "OTEL_EXPORTER_OTLP_ENDPOINT must be"},
+		{"unknown exporter", func(c *config.Config) { c.TracesExporter = "jaeger" }, "OTEL_TRACES_EXPORTER must be"},
+		{"unknown rate limit store", func(c *config.Config) { c.RateLimitStore = "redis" }, "RATE_LIMIT_STORE must be"},
+		{"invalid proxy", func(c *config.Config) { c.TrustedProxies = []string{"proxy.internal"} }, `"proxy.internal" is not an IP address`},
+		{"no shutdown timeout", func(c *config.Config) { c.ShutdownTimeout = 0 }, "SHUTDOWN_TIMEOUT must be"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := validConfig()
+			test.change(&c)
+			err := c.Validate()
+			if err == nil || !strings.Contains(err.Error(), test.problem) {
+				t.Errorf("got %v, want an error containing %q", err, test.problem)
+			}
+		})
+	}
+
+	// Every problem is reported at once
+	c := validConfig()
+	c.DatabaseURL, c.Port = "", ""
+	err := c.Validate()
+	if err == nil || !strings.Contains(err.Error(), "DB_URL is required") || !strings.Contains(err.Error(), "PORT must be") {
+		t.Errorf("got %v, want both problems reported", err)
+	}
+}
+
+func TestValidateServer(t *testing.T) {
+	valid := validConfig()
+	if err := valid.ValidateServer(); err != nil {
+		t.Fatalf("validating a valid configuration: %v", err)
+	}
+
+	// The commands do without the server's settings
+	c := validConfig()
+	c.JWTSecret, c.SiteURL, c.APIURL = "  ", "", ""
+	if err := c.Validate(); err != nil {
+		t.Errorf("Validate without the server settings: %v", err)
+	}
+	err := c.ValidateServer()
+	for _, problem := range []string{"JWT_SECRET is required", "SITE_URL is required", "API_URL is required"} {
+		if err == nil || !strings.Contains(err.Error(), problem) {
+			t.Errorf("got %v, want an error containing %q", err, problem)
+		}
+	}
+
+	// The other problems are reported too
+	c.DatabaseURL = ""
+	if err := c.ValidateServer(); err == nil || !strings.Contains(err.Error(), "DB_URL is required") {
+		t.Errorf("got %v, want the missing DB_URL reported", err)
+	}
+}
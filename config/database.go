@@ -1,12 +1,14 @@
 package config
 
 import (
-	"fmt"
+	"context"
 	"log"
-	"os"
+	"log/slog"
 
-	"github.com/jasen-devvv/mini-blog-backend/models"
-	"github.com/joho/godotenv"
+	"github.com/jasen-devvv/mini-blog-backend/logging"
+	"github.com/jasen-devvv/mini-blog-backend/metrics"
+	"github.com/jasen-devvv/mini-blog-backend/migrations"
+	"github.com/jasen-devvv/mini-blog-backend/tracing"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -16,31 +18,51 @@ var DB *gorm.DB
 
 // ConnectDatabase initializes the database connection.
 //
-// It performs the following steps:
-// - Loads environment variables from .env file
-// - Reads the database connection URL from the environment variable DB_URL
-// - Connects to the PostgreSQL database using GORM
-// - Runs automatic migrations for User, Article, and Comment models
-//
-// If any step fails, the application will log an error and terminate.
-func ConnectDatabase() {
-	// Load environment variables from .env file
-	err := godotenv.Load()
+// It connects to the PostgreSQL database at databaseURL (Config.DatabaseURL) using GORM.
+// The schema is managed by the migrations package (see MigrateDatabase). Failed and slow
+// queries are logged (see logging.GormLogger), recorded in the metrics and traced.
+// If the connection fails, the application will log an error and terminate.
+func ConnectDatabase(databaseURL string) {
+	// Initialize database connection
+	var err error
+	DB, err = gorm.Open(postgres.Open(databaseURL), &gorm.Config{Logger: logging.NewGormLogger()})
 	if err != nil {
-		log.Fatal("Error loading .env file")
+		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
-	// Get database connection string from environment
-	dsn := os.Getenv("DB_URL")
+	// Record query and connection pool metrics, and trace queries made within requests
+	if err := DB.Use(metrics.GormPlugin{}); err != nil {
+		log.Fatalf("Failed to set up database metrics: %v", err)
+	}
+	if err := DB.Use(tracing.GormPlugin{}); err != nil {
+		log.Fatalf("Failed to set up database tracing: %v", err)
+	}
+	if sqlDB, err := DB.DB(); err == nil {
+		metrics.RegisterDBStats(sqlDB)
+	}
 
-	// Initialize database connection
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	slog.Info("database connected")
+}
+
+// CloseDatabase closes the connection pool of the database connection.
+func CloseDatabase() error {
+	sqlDB, err := DB.DB()
 	if err != nil {
-		log.Fatal("Failed to connect to database")
+		return err
 	}
+	return sqlDB.Close()
+}
 
-	// AutoMigrate ensures tables exist and updates schema if necessary
-	DB.AutoMigrate(&models.User{}, &models.Article{}, &models.Comment{})
+// MigrateDatabase applies pending schema migrations and logs the ones it applied.
+func MigrateDatabase(ctx context.Context) error {
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
 
-	fmt.Println("Database connected successfully")
+	applied, err := migrations.Up(ctx, sqlDB)
+	for _, migration := range applied {
+		slog.Info("applied migration", "version", migration.Version, "name", migration.Name)
+	}
+	return err
 }
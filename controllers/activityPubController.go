@@ -0,0 +1,637 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/activitypub"
+	"github.com/jasen-devvv/mini-blog-backend/apierror"
+	"github.com/jasen-devvv/mini-blog-backend/background"
+	"github.com/jasen-devvv/mini-blog-backend/logging"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"github.com/jasen-devvv/mini-blog-backend/pubsub"
+	"github.com/jasen-devvv/mini-blog-backend/sanitize"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxActivitySize limits the size of activities accepted by the inbox.
+const maxActivitySize = 1 << 20
+
+// outboxSize is the number of recent activities listed in an outbox.
+const outboxSize = 20
+
+// WebFinger resolves an "acct:username@host" resource to the author's ActivityPub actor,
+// which is how fediverse servers look up an account such as @alice@blog.example.com.
+func (h *Handler) WebFinger(c *gin.Context) {
+	api := h.Config.APIURL
+
+	username, host, err := activitypub.ParseAccount(c.Query("resource"))
+	if err != nil {
+		c.Error(apierror.New(http.StatusBadRequest, err.Error()))
+		return
+	}
+	if host != hostOf(api) && host != strings.TrimPrefix(strings.TrimPrefix(api, "https://"), "http://") {
+		c.Error(apierror.New(http.StatusNotFound, "User not found"))
+		return
+	}
+
+	var user models.User
+	if err := h.db(c).Where("username = ?", username).First(&user).Error; err != nil {
+		c.Error(apierror.FromDB(err, "User not found"))
+		return
+	}
+
+	renderActivityJSON(c, "application/jrd+json", activitypub.WebFinger{
+		Subject: fmt.Sprintf("acct:%s@%s", user.Username, host),
+		Aliases: []string{actorURI(api, user), authorURL(h.Config.SiteURL, user)},
+		Links: []activitypub.Link{
+			{Rel: "self", Type: activitypub.ContentType, Href: actorURI(api, user)},
+			{Rel: "http://webfinger.net/rel/profile-page", Type: "text/html", Href: authorURL(h.Config.SiteURL, user)},
+		},
+	})
+}
+
+// GetActor returns the ActivityPub actor document of an author, including the public key
+// remote servers use to verify our signed requests.
+func (h *Handler) GetActor(c *gin.Context) {
+	user, ok := h.findActorUser(c)
+	if !ok {
+		return
+	}
+
+	key, err := h.actorKey(c.Request.Context(), user)
+	if err != nil {
+		c.Error(apierror.Internal(err, "Failed to load actor key"))
+		return
+	}
+
+	api := h.Config.APIURL
+	actor := activitypub.Actor{
+		Context:           activitypub.Context,
+		ID:                actorURI(api, user),
+		Type:              "Person",
+		PreferredUsername: user.Username,
+		Name:              displayName(user),
+		Summary:           sanitize.Text(user.Bio),
+		URL:               authorURL(h.Config.SiteURL, user),
+		Inbox:             actorURI(api, user) + "/inbox",
+		Outbox:            actorURI(api, user) + "/outbox",
+		Followers:         actorURI(api, user) + "/followers",
+		PublicKey: activitypub.PublicKey{
+			ID:           actorKeyID(api, user),
+			Owner:        actorURI(api, user),
+			PublicKeyPem: key.PublicKeyPEM,
+		},
+	}
+	if user.AvatarURL != "" {
+		actor.Icon = &activitypub.Image{Type: "Image", URL: user.AvatarURL}
+	}
+
+	renderActivityJSON(c, activitypub.ContentType, actor)
+}
+
+// GetOutbox returns an author's outbox: the Create activities of their newest published articles.
+func (h *Handler) GetOutbox(c *gin.Context) {
+	user, ok := h.findActorUser(c)
+	if !ok {
+		return
+	}
+
+	query := h.db(c).Model(&models.Article{}).Where("user_id = ? AND status = ?", user.ID, models.ArticleStatusPublished)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to get outbox"))
+		return
+	}
+
+	var articles []models.Article
+	if err := query.Preload("Tags").Order("created_at desc").Limit(outboxSize).Find(&articles).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to get outbox"))
+		return
+	}
+
+	api, site := h.Config.APIURL, h.Config.SiteURL
+	items := make([]activitypub.Activity, 0, len(articles))
+	for _, article := range articles {
+		article.User = user
+		activity, err := articleActivity(api, site, "Create", article)
+		if err != nil {
+			c.Error(apierror.Internal(err, "Failed to get outbox"))
+			return
+		}
+		activity.Context = nil
+		items = append(items, activity)
+	}
+
+	renderActivityJSON(c, activitypub.ContentType, activitypub.NewOrderedCollection(actorURI(api, user)+"/outbox", total, items))
+}
+
+// GetFollowers returns the size of an author's followers collection, counting both local
+// and remote followers. The followers themselves are not listed, for privacy.
+func (h *Handler) GetFollowers(c *gin.Context) {
+	user, ok := h.findActorUser(c)
+	if !ok {
+		return
+	}
+
+	local, err := h.countFollows(c, user.ID)
+	if err != nil {
+		c.Error(apierror.Internal(err, "Failed to count followers"))
+		return
+	}
+
+	var remote int64
+	if err := h.db(c).Model(&models.RemoteFollow{}).Where("user_id = ?", user.ID).Count(&remote).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to count followers"))
+		return
+	}
+
+	renderActivityJSON(c, activitypub.ContentType, activitypub.NewOrderedCollection(actorURI(h.Config.APIURL, user)+"/followers", local+remote, nil))
+}
+
+// GetArticleObject returns a published article as an ActivityPub Article object.
+func (h *Handler) GetArticleObject(c *gin.Context) {
+	var article models.Article
+	if err := h.db(c).Preload("User").Preload("Tags").Where("status = ?", models.ArticleStatusPublished).First(&article, c.Param("id")).Error; err != nil {
+		c.Error(apierror.FromDB(err, "Article not found"))
+		return
+	}
+
+	object := articleObject(h.Config.APIURL, h.Config.SiteURL, article)
+	object.Context = activitypub.Context
+	renderActivityJSON(c, activitypub.ContentType, object)
+}
+
+// PostInbox receives activities from remote servers for an author.
+//
+// Every request must carry a valid HTTP signature from the activity's actor. Supported activities:
+//   - Follow: the remote actor follows the author; an Accept is sent back.
+//   - Undo of a Follow: the remote actor unfollows the author.
+//   - Create of a Note replying to one of our articles: stored as a comment.
+//   - Delete: removes a federated comment, or every trace of a deleted remote actor.
+//
+// Other activities are acknowledged and ignored.
+func (h *Handler) PostInbox(c *gin.Context) {
+	user, ok := h.findActorUser(c)
+	if !ok {
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxActivitySize))
+	if err != nil {
+		c.Error(apierror.New(http.StatusBadRequest, "Failed to read activity"))
+		return
+	}
+
+	var activity activitypub.Activity
+	if err := json.Unmarshal(body, &activity); err != nil || activity.Type == "" || activity.Actor == "" {
+		c.Error(apierror.New(http.StatusBadRequest, "Invalid activity"))
+		return
+	}
+
+	// Only accept activities signed by their own actor
+	sender, err := h.verifyInboxSignature(c, activity.Actor, body)
+	if err != nil {
+		// The cause stays in the logs; it may describe what fetching the key ran into
+		logging.FromContext(c.Request.Context()).Warn("rejected inbox signature", "actor", activity.Actor, "error", err)
+		c.Error(apierror.New(http.StatusUnauthorized, "Invalid signature"))
+		return
+	}
+	if sender.URI != activity.Actor {
+		c.Error(apierror.New(http.StatusUnauthorized, "Activity actor does not match the signature"))
+		return
+	}
+
+	switch activity.Type {
+	case "Follow":
+		err = h.receiveFollow(c, user, sender, activity, body)
+	case "Undo":
+		err = h.receiveUndo(c, user, sender, activity)
+	case "Create":
+		err = h.receiveReply(c, sender, activity)
+	case "Delete":
+		err = h.receiveDelete(c, sender, activity)
+	}
+	if err != nil {
+		var invalid invalidActivityError
+		if errors.As(err, &invalid) {
+			c.Error(apierror.New(http.StatusBadRequest, err.Error()))
+			return
+		}
+		logging.FromContext(c.Request.Context()).Error("failed to process activity", "type", activity.Type, "activity", activity.ID, "error", err)
+		c.Error(apierror.New(http.StatusInternalServerError, "Failed to process activity"))
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// invalidActivityError reports an activity that is well signed but cannot be processed.
+type invalidActivityError string
+
+func (e invalidActivityError) Error() string {
+	return string(e)
+}
+
+// receiveFollow records a remote follower and sends an Accept back to them.
+func (h *Handler) receiveFollow(c *gin.Context, user models.User, sender models.RemoteActor, activity activitypub.Activity, body []byte) error {
+	api := h.Config.APIURL
+	if activity.ObjectID() != actorURI(api, user) {
+		return invalidActivityError("Follow must target this actor")
+	}
+
+	follow := models.RemoteFollow{UserID: user.ID, RemoteActorID: sender.ID, ActivityURI: activity.ID}
+	err := h.db(c).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "remote_actor_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"activity_uri"}),
+	}).Create(&follow).Error
+	if err != nil {
+		return err
+	}
+
+	accept, err := activitypub.NewActivity("Accept", fmt.Sprintf("%s#accepts/%d", actorURI(api, user), time.Now().UnixNano()), actorURI(api, user), json.RawMessage(body))
+	if err != nil {
+		return err
+	}
+	ctx := context.WithoutCancel(c.Request.Context())
+	background.Go(func() { h.deliverActivity(ctx, api, user, []string{sender.Inbox}, accept) })
+
+	return nil
+}
+
+// receiveUndo removes a remote follower when they undo their Follow.
+func (h *Handler) receiveUndo(c *gin.Context, user models.User, sender models.RemoteActor, activity activitypub.Activity) error {
+	var undone activitypub.Activity
+	if err := json.Unmarshal(activity.Object, &undone); err != nil || undone.Type != "Follow" {
+		// Only follows can be undone; other undos are ignored
+		return nil
+	}
+
+	return h.db(c).Where("user_id = ? AND remote_actor_id = ?", user.ID, sender.ID).Delete(&models.RemoteFollow{}).Error
+}
+
+// receiveReply stores a Note replying to one of our published articles as a comment.
+// Deliveries of the same Note are only stored once.
+func (h *Handler) receiveReply(c *gin.Context, sender models.RemoteActor, activity activitypub.Activity) error {
+	var note activitypub.Object
+	if err := json.Unmarshal(activity.Object, &note); err != nil || note.Type != "Note" {
+		// Only notes are stored; other objects are ignored
+		return nil
+	}
+	if note.ID == "" || note.AttributedTo != sender.URI {
+		return invalidActivityError("Note must have an ID and be attributed to the actor")
+	}
+
+	articleID, ok := articleIDFromURI(h.Config.APIURL, h.Config.SiteURL, note.InReplyTo)
+	if !ok {
+		// Not a reply to one of our articles
+		return nil
+	}
+
+	var article models.Article
+	if err := h.db(c).Where("status = ?", models.ArticleStatusPublished).First(&article, articleID).Error; err != nil {
+		return invalidActivityError("Article not found")
+	}
+
+	content := strings.TrimSpace(sanitize.Strip(note.Content))
+	if content == "" {
+		return invalidActivityError("Note has no content")
+	}
+
+	comment := models.Comment{
+		Content:         content,
+		ArticleID:       article.ID,
+		RemoteActorID:   &sender.ID,
+		RemoteObjectURI: &note.ID,
+	}
+	result := h.db(c).Clauses(clause.OnConflict{DoNothing: true}).Create(&comment)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+
+	// Stream the new comment to clients watching the article
+	comment.RemoteActor = &sender
+	if err := pubsub.Publish(commentsTopic(article.ID), "comment", comment); err != nil {
+		logging.FromContext(c.Request.Context()).Error("failed to publish comment", "comment_id", comment.ID, "error", err)
+	}
+
+	return nil
+}
+
+// receiveDelete removes a deleted federated comment, or everything from a deleted remote actor.
+func (h *Handler) receiveDelete(c *gin.Context, sender models.RemoteActor, activity activitypub.Activity) error {
+	objectID := activity.ObjectID()
+
+	// The actor deleted their account; follows and comments are removed with it
+	if objectID == sender.URI {
+		return h.db(c).Delete(&sender).Error
+	}
+
+	return h.db(c).Where("remote_object_uri = ? AND remote_actor_id = ?", objectID, sender.ID).Delete(&models.Comment{}).Error
+}
+
+// verifyInboxSignature checks the HTTP signature of an inbox request and returns the remote
+// actor who signed it. Unknown actors are fetched from their server and cached; the key
+// must be on the same host as the activity's actor, so an unsigned request cannot make
+// the server fetch arbitrary URLs.
+func (h *Handler) verifyInboxSignature(c *gin.Context, actorURI string, body []byte) (models.RemoteActor, error) {
+	var sender models.RemoteActor
+
+	_, err := activitypub.Verify(c.Request, body, func(keyID string) (*rsa.PublicKey, error) {
+		if !activitypub.SameHost(keyID, actorURI) {
+			return nil, errors.New("key is not on the actor's host")
+		}
+		actor, err := h.remoteActorForKey(c.Request.Context(), keyID)
+		if err != nil {
+			return nil, err
+		}
+		sender = actor
+		return activitypub.ParsePublicKey(actor.PublicKeyPEM)
+	})
+
+	return sender, err
+}
+
+// remoteActorForKey returns the remote actor owning a public key, fetching the actor
+// document when the key is not known yet (new actor or rotated key).
+func (h *Handler) remoteActorForKey(ctx context.Context, keyID string) (models.RemoteActor, error) {
+	var actor models.RemoteActor
+	err := h.DB.WithContext(ctx).Where("public_key_id = ?", keyID).First(&actor).Error
+	if err == nil {
+		return actor, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return actor, err
+	}
+
+	// The key ID is the actor URI followed by a fragment, such as "#main-key"
+	actorURI, _, _ := strings.Cut(keyID, "#")
+	document, err := activitypub.DefaultClient.FetchActor(ctx, actorURI)
+	if err != nil {
+		return actor, err
+	}
+	if document.PublicKey.ID != keyID || document.PublicKey.Owner != document.ID {
+		return actor, errors.New("key does not belong to the actor")
+	}
+
+	actor = models.RemoteActor{
+		URI:          document.ID,
+		Username:     document.PreferredUsername,
+		Name:         document.Name,
+		URL:          document.URL,
+		Inbox:        document.Inbox,
+		PublicKeyID:  document.PublicKey.ID,
+		PublicKeyPEM: document.PublicKey.PublicKeyPem,
+	}
+	if document.Endpoints != nil {
+		actor.SharedInbox = document.Endpoints.SharedInbox
+	}
+
+	err = h.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "uri"}},
+		DoUpdates: clause.AssignmentColumns([]string{"username", "name", "url", "inbox", "shared_inbox", "public_key_id", "public_key_pem", "updated_at"}),
+	}).Create(&actor).Error
+	if err != nil {
+		return actor, err
+	}
+
+	// Reload to get the ID when the actor already existed
+	err = h.DB.WithContext(ctx).Where("uri = ?", actor.URI).First(&actor).Error
+	return actor, err
+}
+
+// federateArticle delivers an article activity ("Create", "Update" or "Delete") to the
+// remote followers of its author in the background.
+func (h *Handler) federateArticle(c *gin.Context, activityType string, article models.Article) {
+	api, site := h.Config.APIURL, h.Config.SiteURL
+	ctx := context.WithoutCancel(c.Request.Context())
+	logger := logging.FromContext(ctx).With("article_id", article.ID, "activity_type", activityType)
+
+	background.Go(func() {
+		var user models.User
+		if err := h.DB.WithContext(ctx).First(&user, article.UserID).Error; err != nil {
+			logger.Error("failed to federate article", "error", err)
+			return
+		}
+		article.User = user
+
+		inboxes, err := h.remoteInboxes(ctx, user.ID)
+		if err != nil {
+			logger.Error("failed to federate article", "error", err)
+			return
+		}
+		if len(inboxes) == 0 {
+			return
+		}
+
+		activity, err := articleActivity(api, site, activityType, article)
+		if err != nil {
+			logger.Error("failed to federate article", "error", err)
+			return
+		}
+		h.deliverActivity(ctx, api, user, inboxes, activity)
+	})
+}
+
+// federateArticleChange federates an article after an update, depending on whether it
+// was published before and after: newly published articles are created remotely,
+// edited ones updated, and unpublished ones deleted.
+func (h *Handler) federateArticleChange(c *gin.Context, wasPublished bool, article models.Article) {
+	isPublished := article.Status == models.ArticleStatusPublished
+
+	switch {
+	case isPublished && !wasPublished:
+		h.federateArticle(c, "Create", article)
+	case isPublished:
+		h.federateArticle(c, "Update", article)
+	case wasPublished:
+		h.federateArticle(c, "Delete", article)
+	}
+}
+
+// deliverActivity signs an activity as the given author and posts it to each inbox.
+// Failures are logged; one unreachable server does not stop delivery to the others.
+// ctx carries the logger and trace of the request that caused the delivery; it must not
+// be cancelled with that request.
+func (h *Handler) deliverActivity(ctx context.Context, api string, user models.User, inboxes []string, activity activitypub.Activity) {
+	logger := logging.FromContext(ctx)
+
+	key, err := h.actorKey(ctx, user)
+	if err != nil {
+		logger.Error("failed to load actor key", "user_id", user.ID, "error", err)
+		return
+	}
+	privateKey, err := activitypub.ParsePrivateKey(key.PrivateKeyPEM)
+	if err != nil {
+		logger.Error("failed to load actor key", "user_id", user.ID, "error", err)
+		return
+	}
+
+	for _, inbox := range inboxes {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		if err := activitypub.DefaultClient.Deliver(ctx, inbox, activity, actorKeyID(api, user), privateKey); err != nil {
+			logger.Warn("failed to deliver activity", "type", activity.Type, "activity", activity.ID, "inbox", inbox, "error", err)
+		}
+		cancel()
+	}
+}
+
+// remoteInboxes returns the inboxes of an author's remote followers, using shared inboxes
+// when available so each server receives an activity only once.
+func (h *Handler) remoteInboxes(ctx context.Context, userID uint) ([]string, error) {
+	var actors []models.RemoteActor
+	err := h.DB.WithContext(ctx).Joins("JOIN remote_follows ON remote_follows.remote_actor_id = remote_actors.id").
+		Where("remote_follows.user_id = ?", userID).
+		Find(&actors).Error
+	if err != nil {
+		return nil, err
+	}
+
+	inboxes := []string{}
+	seen := map[string]bool{}
+	for _, actor := range actors {
+		inbox := actor.Inbox
+		if actor.SharedInbox != "" {
+			inbox = actor.SharedInbox
+		}
+		if !seen[inbox] {
+			seen[inbox] = true
+			inboxes = append(inboxes, inbox)
+		}
+	}
+
+	return inboxes, nil
+}
+
+// actorKey returns the key pair of an author, generating it on first use.
+func (h *Handler) actorKey(ctx context.Context, user models.User) (models.ActorKey, error) {
+	var key models.ActorKey
+	err := h.DB.WithContext(ctx).First(&key, user.ID).Error
+	if err == nil {
+		return key, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return key, err
+	}
+
+	publicPEM, privatePEM, err := activitypub.GenerateKey()
+	if err != nil {
+		return key, err
+	}
+
+	// Another request may have generated the key concurrently; keep the first one
+	key = models.ActorKey{UserID: user.ID, PublicKeyPEM: publicPEM, PrivateKeyPEM: privatePEM}
+	if err := h.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&key).Error; err != nil {
+		return key, err
+	}
+	err = h.DB.WithContext(ctx).First(&key, user.ID).Error
+	return key, err
+}
+
+// articleActivity wraps an article in an activity of the given type. Create and Update
+// embed the Article object; Delete embeds a Tombstone.
+func articleActivity(api, site, activityType string, article models.Article) (activitypub.Activity, error) {
+	objectID := articleObjectURI(api, article)
+	actor := actorURI(api, article.User)
+
+	var object interface{} = articleObject(api, site, article)
+	id := fmt.Sprintf("%s/activity", objectID)
+	switch activityType {
+	case "Update":
+		id = fmt.Sprintf("%s#updates/%d", objectID, article.Version)
+	case "Delete":
+		id = fmt.Sprintf("%s#delete", objectID)
+		object = activitypub.Object{ID: objectID, Type: "Tombstone"}
+	}
+
+	activity, err := activitypub.NewActivity(activityType, id, actor, object)
+	activity.To = []string{activitypub.Public}
+	activity.Cc = []string{actor + "/followers"}
+	activity.Published = activitypub.FormatTime(article.UpdatedAt)
+	return activity, err
+}
+
+// articleObject converts an article to an ActivityPub Article object with sanitized content.
+func articleObject(api, site string, article models.Article) activitypub.Object {
+	actor := actorURI(api, article.User)
+
+	object := activitypub.Object{
+		ID:           articleObjectURI(api, article),
+		Type:         "Article",
+		AttributedTo: actor,
+		Name:         article.Title,
+		Summary:      articleSummary(article),
+		Content:      sanitize.HTML(article.Content),
+		URL:          articleURL(site, article),
+		To:           []string{activitypub.Public},
+		Cc:           []string{actor + "/followers"},
+		Published:    activitypub.FormatTime(article.CreatedAt),
+		Updated:      activitypub.FormatTime(article.UpdatedAt),
+	}
+	if article.CoverURL != "" {
+		object.Image = &activitypub.Image{Type: "Image", URL: article.CoverURL}
+	}
+	for _, tag := range article.Tags {
+		object.Tag = append(object.Tag, activitypub.Tag{Type: "Hashtag", Name: "#" + tag.Name})
+	}
+
+	return object
+}
+
+// articleIDFromURI extracts an article ID from its ActivityPub ID or its public page URL.
+func articleIDFromURI(api, site, uri string) (uint, bool) {
+	for _, prefix := range []string{api + "/ap/articles/", site + "/articles/"} {
+		if rest, ok := strings.CutPrefix(uri, prefix); ok {
+			id, err := strconv.ParseUint(rest, 10, 64)
+			return uint(id), err == nil
+		}
+	}
+	return 0, false
+}
+
+// findActorUser loads the author from the "username" route parameter.
+// It writes a "not found" response and returns false when the user does not exist.
+func (h *Handler) findActorUser(c *gin.Context) (models.User, bool) {
+	var user models.User
+	if err := h.db(c).Where("username = ?", c.Param("username")).First(&user).Error; err != nil {
+		c.Error(apierror.FromDB(err, "User not found"))
+		return user, false
+	}
+	return user, true
+}
+
+// actorURI returns the ActivityPub ID of an author.
+func actorURI(api string, user models.User) string {
+	return fmt.Sprintf("%s/ap/users/%s", api, user.Username)
+}
+
+// actorKeyID returns the ID of an author's public key.
+func actorKeyID(api string, user models.User) string {
+	return actorURI(api, user) + "#main-key"
+}
+
+// articleObjectURI returns the ActivityPub ID of an article.
+func articleObjectURI(api string, article models.Article) string {
+	return fmt.Sprintf("%s/ap/articles/%d", api, article.ID)
+}
+
+// renderActivityJSON writes a JSON document with the given content type.
+func renderActivityJSON(c *gin.Context, contentType string, document interface{}) {
+	body, err := json.Marshal(document)
+	if err != nil {
+		c.Error(apierror.Internal(err, "Failed to render document"))
+		return
+	}
+	c.Data(http.StatusOK, contentType, body)
+}
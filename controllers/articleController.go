@@ -1,177 +1,306 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jasen-devvv/mini-blog-backend/config"
+	"github.com/jasen-devvv/mini-blog-backend/apierror"
 	"github.com/jasen-devvv/mini-blog-backend/models"
+	"github.com/jasen-devvv/mini-blog-backend/services"
 )
 
 // ArticleInput defines the structure for article creation and update requests
 type ArticleInput struct {
-	Title   string `json:"title" binding:"required"`
-	Content string `json:"content" binding:"required"`
+	Title           string   `json:"title" binding:"required,max=255"`
+	Content         string   `json:"content" binding:"required"`
+	Excerpt         string   `json:"excerpt" binding:"max=500"`
+	CoverURL        string   `json:"cover_url" binding:"omitempty,url,max=500"`
+	MetaDescription string   `json:"meta_description" binding:"max=300"`
+	CanonicalURL    string   `json:"canonical_url" binding:"omitempty,url,max=500"`
+	NoIndex         bool     `json:"noindex"`
+	Status          string   `json:"status" binding:"omitempty,oneof=draft published"`
+	Tags            []string `json:"tags" binding:"max=10,dive,min=1,max=50"`
 }
 
-// GetAllArticles retrieves all articles from the database, ordered by creation date (newest first)
-// and includes the associated user information with the password field removed.
+// GetAllArticles retrieves all published articles, ordered by creation date (newest first)
+// and includes the associated user information with private fields removed.
+// Each article carries its reaction counts and the caller's own reactions.
 // Returns a JSON response with the articles or an error message.
-func GetAllArticles(ctx *gin.Context) {
-	var articles []models.Article
-
-	if err := config.DB.Preload("User").Order("created_at desc").Find(&articles).Error; err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get articles"})
+func (h *Handler) GetAllArticles(ctx *gin.Context) {
+	articles, err := h.Articles.List(ctx)
+	if err != nil {
+		ctx.Error(apierror.Internal(err, "Failed to get articles"))
 		return
 	}
 
-	// Remove password from user data for security
+	// Remove private fields from user data for security
 	for i := range articles {
-		articles[i].User.Password = ""
+		articles[i].User.HidePrivate()
+	}
+
+	// Load reaction counts and the caller's reactions in bulk
+	if err := h.Hooks.AttachArticleReactions(ctx, articles); err != nil {
+		ctx.Error(apierror.Internal(err, "Failed to get reactions"))
+		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"data": articles})
 }
 
 // GetArticle retrieves a single article by its ID, including the associated user information.
-// The password field is removed from the user data for security.
+// Private fields are removed from the user data for security.
+// Drafts are only visible to their author and collaborators (user_id is set by the optional
+// auth middleware).
+// The article carries its reaction counts and the caller's own reactions.
+// Sets an ETag header and answers 304 Not Modified when it matches If-None-Match.
 // Returns a JSON response with the article or a "not found" error.
-func GetArticle(ctx *gin.Context) {
-	id := ctx.Param("id")
+func (h *Handler) GetArticle(ctx *gin.Context) {
+	id, ok := paramID(ctx, "id")
+	if !ok {
+		ctx.Error(apierror.New(http.StatusNotFound, "Article not found"))
+		return
+	}
 
-	var article models.Article
+	article, err := h.Articles.Get(ctx, id, viewerID(ctx))
+	if err != nil {
+		ctx.Error(apierror.FromDB(err, "Article not found"))
+		return
+	}
+
+	article.User.HidePrivate()
 
-	if err := config.DB.Preload("User").First(&article, id).Error; err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "Article not found"})
+	// Load reaction counts and the caller's reactions
+	articles := []models.Article{*article}
+	if err := h.Hooks.AttachArticleReactions(ctx, articles); err != nil {
+		ctx.Error(apierror.Internal(err, "Failed to get reactions"))
 		return
 	}
 
-	article.User.Password = ""
+	// Let clients reuse their cached copy when nothing changed
+	etag := articleETag(articles[0])
+	ctx.Header("ETag", etag)
+	ctx.Header("Cache-Control", "private, no-cache")
+	if notModified(ctx, etag) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
 
-	ctx.JSON(http.StatusOK, gin.H{"data": article})
+	ctx.JSON(http.StatusOK, gin.H{"data": articles[0]})
 }
 
-// CreateArticle creates a new article in the database.
+// CreateArticle creates a new article.
 // Requires authentication, as it uses the user_id from the context (set by auth middleware).
+// Published articles are delivered to the author's remote ActivityPub followers, and
+// Webmentions are sent to the sites they link to.
 // Returns a JSON response with the created article (including user info) or an error message.
-func CreateArticle(c *gin.Context) {
+func (h *Handler) CreateArticle(c *gin.Context) {
 	var input ArticleInput
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apierror.Validation(err))
 		return
 	}
 
 	// Get user_id from context (set by auth middleware)
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		c.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
 		return
 	}
 
 	// Create new article
-	article := models.Article{
-		Title:   input.Title,
-		Content: input.Content,
-		UserID:  userID.(uint),
-	}
-
-	if err := config.DB.Create(&article).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create article"})
+	article, err := h.Articles.Create(c, &models.Article{
+		Title:           input.Title,
+		Content:         input.Content,
+		Excerpt:         input.Excerpt,
+		CoverURL:        input.CoverURL,
+		MetaDescription: input.MetaDescription,
+		CanonicalURL:    input.CanonicalURL,
+		NoIndex:         input.NoIndex,
+		Status:          input.Status,
+		UserID:          userID.(uint),
+	}, input.Tags)
+	if err != nil {
+		c.Error(apierror.Internal(err, "Failed to create article"))
 		return
 	}
 
-	// Load user info for the response
-	config.DB.Preload("User").First(&article, article.ID)
+	// Remove private fields from response for security
+	article.User.HidePrivate()
 
-	// Remove password from response for security
-	article.User.Password = ""
+	h.Hooks.ArticleCreated(c, *article)
 
+	c.Header("ETag", articleETag(*article))
 	c.JSON(http.StatusCreated, gin.H{"data": article})
 }
 
-// UpdateArticle updates an existing article in the database.
-// Requires authentication and verifies that the user is the owner of the article.
+// UpdateArticle updates an existing article.
+// Requires authentication and verifies that the user is the author or a collaborator of the article.
+// Refuses the save while another user holds the article's edit lock (see checkEditLock).
+// Requires an If-Match header with the article's current ETag (412 on mismatch, 428 when missing).
+// Remote ActivityPub followers are sent the change (see federateArticleChange), and
+// Webmentions are sent to the sites a published article links to.
 // Returns a JSON response with the updated article or an appropriate error message.
-func UpdateArticle(c *gin.Context) {
-	id := c.Param("id")
-
+func (h *Handler) UpdateArticle(c *gin.Context) {
 	// Get user_id from context (set by auth middleware)
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		c.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
 		return
 	}
 
-	// Check if article exists
-	var article models.Article
-	if err := config.DB.First(&article, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Article not found"})
+	// Check if article exists and the user may edit it
+	article, ok := h.editableArticle(c, userID.(uint), "You are not authorized to update this article")
+	if !ok {
 		return
 	}
 
-	// Check if user is the owner of the article
-	if article.UserID != userID.(uint) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to update this article"})
+	// Make sure the client edited the current version
+	version, ok := requireIfMatch(c, *article)
+	if !ok {
 		return
 	}
 
 	// Bind input
 	var input ArticleInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apierror.Validation(err))
 		return
 	}
 
-	// Update article
-	if err := config.DB.Model(&article).Updates(models.Article{
-		Title:   input.Title,
-		Content: input.Content,
-	}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update article"})
+	wasPublished := article.Status == models.ArticleStatusPublished
+
+	article.Title = input.Title
+	article.Content = input.Content
+	article.Excerpt = input.Excerpt
+	article.CoverURL = input.CoverURL
+	article.MetaDescription = input.MetaDescription
+	article.CanonicalURL = input.CanonicalURL
+	article.NoIndex = input.NoIndex
+	if input.Status != "" {
+		article.Status = input.Status
+	}
+
+	// Replace the article's tags along with its fields
+	tags := input.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	h.saveArticle(c, article, version, tags, wasPublished)
+}
+
+// saveArticle saves a changed article if it is still at the given version, replacing its
+// tags unless tags is nil, and writes the response with the stored article
+// (412 when the version changed).
+func (h *Handler) saveArticle(c *gin.Context, article *models.Article, version uint, tags []string, wasPublished bool) {
+	saved, err := h.Articles.Update(c, article, version, tags)
+	if errors.Is(err, services.ErrConflict) {
+		c.Error(apierror.New(http.StatusPreconditionFailed, "Article has been modified since it was retrieved"))
+		return
+	}
+	if err != nil {
+		c.Error(apierror.Internal(err, "Failed to update article"))
 		return
 	}
 
-	// Load user info for response
-	config.DB.Preload("User").First(&article, article.ID)
+	h.Hooks.ArticleUpdated(c, wasPublished, *saved)
+	h.writeArticle(c, saved)
+}
+
+// writeArticle responds with an article, its reactions and its ETag.
+func (h *Handler) writeArticle(c *gin.Context, article *models.Article) {
+	// Remove private fields from response for security
+	article.User.HidePrivate()
 
-	// Remove password from response for security
-	article.User.Password = ""
+	// Load reaction counts and the caller's reactions
+	articles := []models.Article{*article}
+	if err := h.Hooks.AttachArticleReactions(c, articles); err != nil {
+		c.Error(apierror.Internal(err, "Failed to get reactions"))
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{"data": article})
+	c.Header("ETag", articleETag(articles[0]))
+	c.JSON(http.StatusOK, gin.H{"data": articles[0]})
 }
 
-// DeleteArticle removes an article from the database.
-// Requires authentication and verifies that the user is the owner of the article.
-// Returns a success message or an appropriate error message.
-func DeleteArticle(c *gin.Context) {
-	id := c.Param("id")
+// editableArticle loads the article of the request for a write by the user. It responds
+// with 404 when it does not exist, 403 (with the given message) when the user may not edit
+// it, and 423 or 500 when the edit lock refuses the save or cannot be checked; it returns
+// false then.
+func (h *Handler) editableArticle(c *gin.Context, userID uint, forbidden string) (*models.Article, bool) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		c.Error(apierror.New(http.StatusNotFound, "Article not found"))
+		return nil, false
+	}
+
+	article, err := h.Articles.Editable(c, id, userID)
+	if errors.Is(err, services.ErrForbidden) {
+		c.Error(apierror.New(http.StatusForbidden, forbidden))
+		return nil, false
+	}
+	if err != nil {
+		c.Error(apierror.FromDB(err, "Article not found"))
+		return nil, false
+	}
+
+	// Respect the edit lock held by other editing sessions
+	if !h.Hooks.CheckEditLock(c, *article) {
+		return nil, false
+	}
 
+	return article, true
+}
+
+// DeleteArticle removes an article.
+// Requires authentication and verifies that the user is the author of the article;
+// collaborators may not delete it.
+// Requires an If-Match header with the article's current ETag (412 on mismatch, 428 when missing).
+// Remote ActivityPub followers are told to delete published articles.
+// Returns a success message or an appropriate error message.
+func (h *Handler) DeleteArticle(c *gin.Context) {
 	// Get user_id from context (set by auth middleware)
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		c.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
 		return
 	}
 
 	// Check if article exists
-	var article models.Article
-	if err := config.DB.First(&article, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Article not found"})
+	id, ok := paramID(c, "id")
+	if !ok {
+		c.Error(apierror.New(http.StatusNotFound, "Article not found"))
+		return
+	}
+	article, err := h.Articles.Owned(c, id, userID.(uint))
+	if errors.Is(err, services.ErrForbidden) {
+		c.Error(apierror.New(http.StatusForbidden, "You are not authorized to delete this article"))
+		return
+	}
+	if err != nil {
+		c.Error(apierror.FromDB(err, "Article not found"))
 		return
 	}
 
-	// Check if user is the owner of the article
-	if article.UserID != userID.(uint) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to delete this article"})
+	// Make sure the client saw the current version
+	version, ok := requireIfMatch(c, *article)
+	if !ok {
 		return
 	}
 
-	// Delete article
-	if err := config.DB.Delete(&article).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete article"})
+	// Delete article, unless it changed in the meantime
+	err = h.Articles.Delete(c, article, version)
+	if errors.Is(err, services.ErrConflict) {
+		c.Error(apierror.New(http.StatusPreconditionFailed, "Article has been modified since it was retrieved"))
 		return
 	}
+	if err != nil {
+		c.Error(apierror.Internal(err, "Failed to delete article"))
+		return
+	}
+
+	h.Hooks.ArticleDeleted(c, *article)
 
 	c.JSON(http.StatusOK, gin.H{"data": "Article deleted successfully"})
 }
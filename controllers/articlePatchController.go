@@ -0,0 +1,272 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/apierror"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+)
+
+// articlePatchField describes how a field of an article merge patch is validated.
+//
+// Fields:
+//   - Column: Database column the field is written to ("tags" for the article's tags).
+//   - Nullable: Whether null is accepted; null clears the field (RFC 7396 "remove").
+//   - Bool: Whether the field holds a boolean instead of a string.
+//   - MaxItems: For fields holding a list of strings, how many items it may have (0 for string fields).
+//   - Validate: Checks a non-null string value, or each item of a list, and returns an error message, or "" when valid.
+//
+// Lengths are counted in characters, like the max rules of ArticleInput.
+type articlePatchField struct {
+	Column   string
+	Nullable bool
+	Bool     bool
+	MaxItems int
+	Validate func(value string) string
+}
+
+// articlePatchFields lists the article fields that can be changed with PATCH.
+var articlePatchFields = map[string]articlePatchField{
+	"title": {Column: "title", Validate: func(value string) string {
+		if strings.TrimSpace(value) == "" {
+			return "must not be empty"
+		}
+		if utf8.RuneCountInString(value) > 255 {
+			return "must be at most 255 characters"
+		}
+		return ""
+	}},
+	"content": {Column: "content", Validate: func(value string) string {
+		if strings.TrimSpace(value) == "" {
+			return "must not be empty"
+		}
+		return ""
+	}},
+	"status": {Column: "status", Validate: func(value string) string {
+		if value != models.ArticleStatusDraft && value != models.ArticleStatusPublished {
+			return "must be one of: draft, published"
+		}
+		return ""
+	}},
+	"excerpt": {Column: "excerpt", Nullable: true, Validate: func(value string) string {
+		if utf8.RuneCountInString(value) > 500 {
+			return "must be at most 500 characters"
+		}
+		return ""
+	}},
+	"cover_url":     {Column: "cover_url", Nullable: true, Validate: validatePatchURL},
+	"canonical_url": {Column: "canonical_url", Nullable: true, Validate: validatePatchURL},
+	"meta_description": {Column: "meta_description", Nullable: true, Validate: func(value string) string {
+		if utf8.RuneCountInString(value) > 300 {
+			return "must be at most 300 characters"
+		}
+		return ""
+	}},
+	"noindex": {Column: "noindex", Bool: true},
+	"tags": {Column: "tags", Nullable: true, MaxItems: 10, Validate: func(value string) string {
+		if value == "" || utf8.RuneCountInString(value) > 50 {
+			return "must be between 1 and 50 characters"
+		}
+		return ""
+	}},
+}
+
+// validatePatchURL accepts an empty string or an absolute URL of at most 500 characters.
+func validatePatchURL(value string) string {
+	if value == "" {
+		return ""
+	}
+	if u, err := url.ParseRequestURI(value); err != nil || u.Host == "" {
+		return "must be a valid URL"
+	}
+	if utf8.RuneCountInString(value) > 500 {
+		return "must be at most 500 characters"
+	}
+	return ""
+}
+
+// PatchArticle partially updates an article with a JSON Merge Patch (RFC 7396).
+//
+// Only the fields present in the patch are changed; null clears optional fields such as
+// excerpt, cover_url, meta_description, canonical_url and tags. A tags array replaces
+// the article's tags. Every field is validated, and all problems are reported together
+// under "errors". Unknown fields are rejected.
+// Like UpdateArticle, it requires authentication, the right to edit, the edit lock and an If-Match header.
+// Returns a JSON response with the updated article or an appropriate error message.
+func (h *Handler) PatchArticle(c *gin.Context) {
+	// Get user_id from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	// Merge patches must be sent as application/merge-patch+json (plain JSON is tolerated)
+	mediaType, _, _ := mime.ParseMediaType(c.ContentType())
+	if mediaType != "application/merge-patch+json" && mediaType != "application/json" {
+		c.Error(apierror.New(http.StatusUnsupportedMediaType, "Content-Type must be application/merge-patch+json"))
+		return
+	}
+
+	// Check if article exists and the user may edit it
+	article, ok := h.editableArticle(c, userID.(uint), "You are not authorized to update this article")
+	if !ok {
+		return
+	}
+
+	// Make sure the client edited the current version
+	version, ok := requireIfMatch(c, *article)
+	if !ok {
+		return
+	}
+
+	updates, fieldErrors, err := parseArticlePatch(c.Request.Body)
+	if err != nil {
+		c.Error(apierror.New(http.StatusBadRequest, "The request body must be a JSON object").WithCode("invalid_json"))
+		return
+	}
+	if len(fieldErrors) > 0 {
+		invalid := apierror.New(http.StatusUnprocessableEntity, "The patch has invalid fields").WithCode("validation_failed")
+		invalid.Fields = fieldErrors
+		c.Error(invalid)
+		return
+	}
+
+	// An empty patch changes nothing
+	if len(updates) == 0 {
+		h.writeArticle(c, article)
+		return
+	}
+
+	// Tags are only replaced when the patch sets them
+	tags, _ := updates["tags"].([]string)
+
+	wasPublished := article.Status == models.ArticleStatusPublished
+	applyArticlePatch(article, updates)
+	h.saveArticle(c, article, version, tags, wasPublished)
+}
+
+// applyArticlePatch sets the fields of an article from the column updates of a patch.
+// Tags are not fields of the article and are left to the caller.
+func applyArticlePatch(article *models.Article, updates map[string]interface{}) {
+	for column, value := range updates {
+		switch column {
+		case "title":
+			article.Title = value.(string)
+		case "content":
+			article.Content = value.(string)
+		case "status":
+			article.Status = value.(string)
+		case "excerpt":
+			article.Excerpt = value.(string)
+		case "cover_url":
+			article.CoverURL = value.(string)
+		case "canonical_url":
+			article.CanonicalURL = value.(string)
+		case "meta_description":
+			article.MetaDescription = value.(string)
+		case "noindex":
+			article.NoIndex = value.(bool)
+		}
+	}
+}
+
+// parseArticlePatch decodes a merge patch document into column updates.
+// It returns an error when the body is not a JSON object, and the fields that are
+// unknown or invalid, ordered by name.
+func parseArticlePatch(body io.Reader) (map[string]interface{}, []apierror.FieldError, error) {
+	var patch map[string]json.RawMessage
+	if err := json.NewDecoder(body).Decode(&patch); err != nil || patch == nil {
+		return nil, nil, fmt.Errorf("request body must be a JSON object")
+	}
+
+	updates := map[string]interface{}{}
+	fieldErrors := []apierror.FieldError{}
+	invalid := func(name, code, message string) {
+		fieldErrors = append(fieldErrors, apierror.FieldError{Field: name, Code: code, Message: message})
+	}
+
+	for name, raw := range patch {
+		field, known := articlePatchFields[name]
+		if !known {
+			invalid(name, "unknown", "is not a field that can be changed")
+			continue
+		}
+
+		// null removes the value
+		if string(raw) == "null" {
+			if !field.Nullable {
+				invalid(name, "required", "must not be null")
+				continue
+			}
+			if field.MaxItems > 0 {
+				updates[field.Column] = []string{}
+			} else {
+				updates[field.Column] = ""
+			}
+			continue
+		}
+
+		if field.Bool {
+			var value bool
+			if err := json.Unmarshal(raw, &value); err != nil {
+				invalid(name, "type", "must be a boolean")
+				continue
+			}
+			updates[field.Column] = value
+			continue
+		}
+
+		if field.MaxItems > 0 {
+			var items []string
+			if err := json.Unmarshal(raw, &items); err != nil {
+				invalid(name, "type", "must be an array of strings")
+				continue
+			}
+			if len(items) > field.MaxItems {
+				invalid(name, "invalid", fmt.Sprintf("must have at most %d items", field.MaxItems))
+				continue
+			}
+			if message := validateItems(items, field.Validate); message != "" {
+				invalid(name, "invalid", message)
+				continue
+			}
+			updates[field.Column] = items
+			continue
+		}
+
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			invalid(name, "type", "must be a string")
+			continue
+		}
+		if message := field.Validate(value); message != "" {
+			invalid(name, "invalid", message)
+			continue
+		}
+		updates[field.Column] = value
+	}
+
+	sort.Slice(fieldErrors, func(i, j int) bool { return fieldErrors[i].Field < fieldErrors[j].Field })
+	return updates, fieldErrors, nil
+}
+
+// validateItems checks every item of a list and returns the error message of the first
+// invalid one, naming its position, or "" when all are valid.
+func validateItems(items []string, validate func(value string) string) string {
+	for i, item := range items {
+		if message := validate(item); message != "" {
+			return fmt.Sprintf("item %d %s", i+1, message)
+		}
+	}
+	return ""
+}
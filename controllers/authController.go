@@ -1,15 +1,12 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
-	"os"
-	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
-	"github.com/jasen-devvv/mini-blog-backend/config"
-	"github.com/jasen-devvv/mini-blog-backend/models"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/jasen-devvv/mini-blog-backend/apierror"
+	"github.com/jasen-devvv/mini-blog-backend/services"
 )
 
 // RegisterInput defines the structure for user registration request
@@ -25,35 +22,27 @@ type LoginInput struct {
 	Password string `json:"password" binding:"required"`
 }
 
-// Register creates a new user account in the database.
+// Register creates a new user account.
 // It validates the input, hashes the password, and returns the created user.
-// Password validation ensures it's at least 6 characters long.
-func Register(ctx *gin.Context) {
+// Password validation ensures it's at least 6 characters long. A taken email or
+// username is a 409 Conflict.
+func (h *Handler) Register(ctx *gin.Context) {
 	var input RegisterInput
 
 	// Validate input
 	if err := ctx.ShouldBindJSON(&input); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(apierror.Validation(err))
 		return
 	}
 
-	// Hash password for secure storage
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+	// Create user with hashed password, handle potential duplicate email/username
+	user, err := h.Auth.Register(ctx, input.Username, input.Email, input.Password)
+	if errors.Is(err, services.ErrConflict) {
+		ctx.Error(apierror.New(http.StatusConflict, "Email or username is already taken").WithCode("user_exists"))
 		return
 	}
-
-	// Create user with hashed password
-	user := models.User{
-		Username: input.Username,
-		Email:    input.Email,
-		Password: string(hashedPassword),
-	}
-
-	// Save user to database, handle potential duplicate email/username
-	if err := config.DB.Create(&user).Error; err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to create user. Email or username may already exist."})
+	if err != nil {
+		ctx.Error(apierror.Internal(err, "Failed to create user"))
 		return
 	}
 
@@ -65,39 +54,22 @@ func Register(ctx *gin.Context) {
 // Login authenticates a user and generates a JWT token.
 // It checks email and password, and returns a token and user info on success.
 // The token expires after one week.
-func Login(ctx *gin.Context) {
+func (h *Handler) Login(ctx *gin.Context) {
 	var input LoginInput
 
 	// Validate input
 	if err := ctx.ShouldBindJSON(&input); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Find user by email
-	var user models.User
-	if err := config.DB.Where("email = ?", input.Email).First(&user).Error; err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+		ctx.Error(apierror.Validation(err))
 		return
 	}
 
-	// Verify password against stored hash
-	err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password))
-	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+	tokenString, user, err := h.Auth.Login(ctx, input.Email, input.Password)
+	if errors.Is(err, services.ErrInvalidCredentials) {
+		ctx.Error(apierror.New(http.StatusUnauthorized, "Invalid email or password").WithCode("invalid_credentials"))
 		return
 	}
-
-	// Generate JWT token with user ID and expiration
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.ID,
-		"exp":     time.Now().Add(time.Hour * 24 * 7).Unix(), // Token expires in 1 week
-	})
-
-	// Sign token with secret key
-	tokenString, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		ctx.Error(apierror.Internal(err, "Failed to generate token"))
 		return
 	}
 
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/apierror"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"gorm.io/gorm/clause"
+)
+
+// GetBookmarks retrieves the authenticated user's bookmarks, newest first.
+// Requires authentication, as it uses the user_id from the context (set by auth middleware).
+// Bookmarks of articles that were unpublished are left out; deleted articles are removed by the database.
+// Returns a JSON response with the bookmarks or an error message.
+func (h *Handler) GetBookmarks(c *gin.Context) {
+	// Get user_id from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var bookmarks []models.Bookmark
+	if err := h.db(c).
+		Joins("JOIN articles ON articles.id = bookmarks.article_id AND articles.status = ?", models.ArticleStatusPublished).
+		Where("bookmarks.user_id = ?", userID).
+		Preload("Article.User").
+		Order("bookmarks.created_at desc").
+		Find(&bookmarks).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to get bookmarks"))
+		return
+	}
+
+	// Remove private fields from user data for security
+	for i := range bookmarks {
+		bookmarks[i].Article.User.HidePrivate()
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": bookmarks})
+}
+
+// CreateBookmark saves a published article to the authenticated user's bookmarks.
+// Requires authentication, as it uses the user_id from the context (set by auth middleware).
+// Bookmarking the same article twice is a no-op.
+// Returns a JSON response with the bookmark or an appropriate error message.
+func (h *Handler) CreateBookmark(c *gin.Context) {
+	// Get user_id from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	// Verify the article exists and is published
+	var article models.Article
+	if err := h.db(c).Where("status = ?", models.ArticleStatusPublished).First(&article, c.Param("id")).Error; err != nil {
+		c.Error(apierror.FromDB(err, "Article not found"))
+		return
+	}
+
+	bookmark := models.Bookmark{UserID: userID.(uint), ArticleID: article.ID}
+	if err := h.db(c).Clauses(clause.OnConflict{DoNothing: true}).Create(&bookmark).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to create bookmark"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": gin.H{"article_id": article.ID, "bookmarked": true}})
+}
+
+// DeleteBookmark removes an article from the authenticated user's bookmarks.
+// Requires authentication, as it uses the user_id from the context (set by auth middleware).
+// Returns a success message or an error message.
+func (h *Handler) DeleteBookmark(c *gin.Context) {
+	// Get user_id from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	if err := h.db(c).Where("user_id = ? AND article_id = ?", userID, c.Param("id")).Delete(&models.Bookmark{}).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to delete bookmark"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": "Bookmark deleted successfully"})
+}
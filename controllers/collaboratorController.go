@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/apierror"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"github.com/jasen-devvv/mini-blog-backend/services"
+)
+
+// GetCollaborators lists the users an article is shared with, by username.
+// Requires authentication; the author and the collaborators of the article may see the list.
+// Returns a JSON response with the users (private fields removed) or an error message.
+func (h *Handler) GetCollaborators(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		c.Error(apierror.New(http.StatusNotFound, "Article not found"))
+		return
+	}
+
+	article, err := h.Articles.Editable(c, id, viewerID(c))
+	if errors.Is(err, services.ErrForbidden) {
+		c.Error(apierror.New(http.StatusForbidden, "You are not authorized to see the collaborators of this article"))
+		return
+	}
+	if err != nil {
+		c.Error(apierror.FromDB(err, "Article not found"))
+		return
+	}
+
+	h.writeCollaborators(c, article)
+}
+
+// AddCollaborator shares an article with the user identified by username, who may then
+// view it while it is a draft, edit it and take its edit lock.
+// Requires authentication and verifies that the user is the author of the article.
+// Sharing an article twice is a no-op.
+// Returns a JSON response with the updated list of collaborators or an error message.
+func (h *Handler) AddCollaborator(c *gin.Context) {
+	article, user, ok := h.sharedArticle(c)
+	if !ok {
+		return
+	}
+
+	err := h.Articles.Share(c, article, user)
+	if errors.Is(err, services.ErrInvalidCollaborator) {
+		c.Error(apierror.New(http.StatusBadRequest, "You cannot collaborate on your own article"))
+		return
+	}
+	if err != nil {
+		c.Error(apierror.Internal(err, "Failed to add collaborator"))
+		return
+	}
+
+	h.writeCollaborators(c, article)
+}
+
+// RemoveCollaborator stops sharing an article with the user identified by username.
+// Requires authentication and verifies that the user is the author of the article.
+// Returns a JSON response with the updated list of collaborators or an error message.
+func (h *Handler) RemoveCollaborator(c *gin.Context) {
+	article, user, ok := h.sharedArticle(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Articles.Unshare(c, article, user); err != nil {
+		c.Error(apierror.FromDB(err, "User is not a collaborator"))
+		return
+	}
+
+	h.writeCollaborators(c, article)
+}
+
+// sharedArticle loads the article and the user of a collaborator request. It responds with
+// 404 when either does not exist and 403 when the caller is not the article's author; it
+// returns false then.
+func (h *Handler) sharedArticle(c *gin.Context) (*models.Article, *models.User, bool) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		c.Error(apierror.New(http.StatusNotFound, "Article not found"))
+		return nil, nil, false
+	}
+
+	article, err := h.Articles.Owned(c, id, viewerID(c))
+	if errors.Is(err, services.ErrForbidden) {
+		c.Error(apierror.New(http.StatusForbidden, "You are not authorized to share this article"))
+		return nil, nil, false
+	}
+	if err != nil {
+		c.Error(apierror.FromDB(err, "Article not found"))
+		return nil, nil, false
+	}
+
+	user, err := h.Users.Find(c, c.Param("username"))
+	if err != nil {
+		c.Error(apierror.FromDB(err, "User not found"))
+		return nil, nil, false
+	}
+
+	return article, user, true
+}
+
+// writeCollaborators responds with the collaborators of an article.
+func (h *Handler) writeCollaborators(c *gin.Context, article *models.Article) {
+	users, err := h.Articles.Collaborators(c, article)
+	if err != nil {
+		c.Error(apierror.Internal(err, "Failed to get collaborators"))
+		return
+	}
+
+	// Remove private fields from user data for security
+	for i := range users {
+		users[i].HidePrivate()
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": users})
+}
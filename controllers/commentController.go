@@ -1,34 +1,50 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jasen-devvv/mini-blog-backend/config"
-	"github.com/jasen-devvv/mini-blog-backend/models"
+	"github.com/jasen-devvv/mini-blog-backend/apierror"
+	"github.com/jasen-devvv/mini-blog-backend/logging"
+	"github.com/jasen-devvv/mini-blog-backend/pubsub"
+	"github.com/jasen-devvv/mini-blog-backend/services"
 )
 
 // CommentInput defines the structure for comment creation requests
 type CommentInput struct {
-	Content string `json:"content" binding:"required"`
+	Content  string `json:"content" binding:"required"`
+	ParentID *uint  `json:"parent_id"`
 }
 
 // GetComments retrieves all comments for a specific article, ordered by creation time.
-// Comments include user information with passwords removed for security.
+// Comments include user information with private fields removed for security
+// (or the remote actor for replies received over ActivityPub),
+// along with their reaction counts and the caller's own reactions.
 // Returns a JSON response with the comments or an error message.
-func GetComments(c *gin.Context) {
-	articleID := c.Param("id")
-	var comments []models.Comment
+func (h *Handler) GetComments(c *gin.Context) {
+	articleID, ok := paramID(c, "id")
+	if !ok {
+		c.JSON(http.StatusOK, gin.H{"data": []interface{}{}})
+		return
+	}
 
-	// Preload user data to get comment author information
-	if err := config.DB.Where("article_id = ?", articleID).Preload("User").Order("created_at asc").Find(&comments).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get comments"})
+	comments, err := h.Comments.List(c, articleID)
+	if err != nil {
+		c.Error(apierror.Internal(err, "Failed to get comments"))
 		return
 	}
 
-	// Remove password from user data for security
+	// Remove private fields from user data for security
 	for i := range comments {
-		comments[i].User.Password = ""
+		comments[i].User.HidePrivate()
+	}
+
+	// Load reaction counts and the caller's reactions in bulk
+	if err := h.Hooks.AttachCommentReactions(c, comments); err != nil {
+		c.Error(apierror.Internal(err, "Failed to get reactions"))
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"data": comments})
@@ -36,49 +52,56 @@ func GetComments(c *gin.Context) {
 
 // CreateComment adds a new comment to an article.
 // Requires authentication, as it uses the user_id from the context (set by auth middleware).
-// Validates that the referenced article exists before creating the comment.
+// Validates that the referenced article exists before creating the comment, and that the
+// parent comment belongs to the same article when replying.
+// Notifies the article author and, for replies, the author of the parent comment.
 // Returns a JSON response with the created comment or an appropriate error message.
-func CreateComment(c *gin.Context) {
-	articleID := c.Param("id")
-
+func (h *Handler) CreateComment(c *gin.Context) {
 	// Get user_id from context (set by auth middleware)
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		c.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
 		return
 	}
 
-	// Verify the article exists before adding a comment
-	var article models.Article
-	if err := config.DB.First(&article, articleID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Article not found"})
+	articleID, ok := paramID(c, "id")
+	if !ok {
+		c.Error(apierror.New(http.StatusNotFound, "Article not found"))
 		return
 	}
 
 	// Validate input
 	var input CommentInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apierror.Validation(err))
 		return
 	}
 
-	// Create new comment
-	comment := models.Comment{
-		Content:   input.Content,
-		UserID:    userID.(uint),
-		ArticleID: article.ID,
-	}
-
-	if err := config.DB.Create(&comment).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create comment"})
+	comment, err := h.Comments.Create(c, articleID, userID.(uint), input.Content, input.ParentID)
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		c.Error(apierror.New(http.StatusNotFound, "Article not found"))
+		return
+	case errors.Is(err, services.ErrInvalidParent):
+		c.Error(apierror.New(http.StatusBadRequest, "Parent comment not found on this article"))
+		return
+	case err != nil:
+		c.Error(apierror.Internal(err, "Failed to create comment"))
 		return
 	}
 
-	// Load user info for response
-	config.DB.Preload("User").First(&comment, comment.ID)
+	// Remove private fields from response for security
+	comment.User.HidePrivate()
 
-	// Remove password from response for security
-	comment.User.Password = ""
+	// Stream the new comment to clients watching the article
+	if err := pubsub.Publish(commentsTopic(comment.ArticleID), "comment", comment); err != nil {
+		logging.FromContext(c.Request.Context()).Error("failed to publish comment", "comment_id", comment.ID, "error", err)
+	}
 
 	c.JSON(http.StatusCreated, gin.H{"data": comment})
 }
+
+// commentsTopic returns the pub/sub topic streaming new comments on an article.
+func commentsTopic(articleID uint) string {
+	return fmt.Sprintf("comments:article:%d", articleID)
+}
@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/apierror"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+)
+
+// articleETag builds the strong ETag of an article representation.
+//
+// It has the form "v{version}.{digest}", where the digest covers what responses include
+// besides the article's own fields: the reaction data and the embedded author, through
+// the author's updated_at. Cached copies are therefore revalidated when reactions change
+// or the author edits their profile, while If-Match only compares the version, because
+// neither conflicts with edits.
+func articleETag(article models.Article) string {
+	h := fnv.New32a()
+
+	kinds := make([]string, 0, len(article.ReactionCounts))
+	for kind := range article.ReactionCounts {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		fmt.Fprintf(h, "%s=%d;", kind, article.ReactionCounts[kind])
+	}
+	fmt.Fprintf(h, "|%s", strings.Join(article.MyReactions, ","))
+	fmt.Fprintf(h, "|%d", article.User.UpdatedAt.UnixNano())
+
+	return fmt.Sprintf(`"v%d.%08x"`, article.Version, h.Sum32())
+}
+
+// etagVersion extracts the article version from an ETag produced by articleETag.
+func etagVersion(etag string) (uint, bool) {
+	etag = strings.TrimSpace(etag)
+	if !strings.HasPrefix(etag, `"v`) || !strings.HasSuffix(etag, `"`) || len(etag) < 4 {
+		return 0, false
+	}
+
+	value := etag[2 : len(etag)-1]
+	if dot := strings.IndexByte(value, '.'); dot >= 0 {
+		value = value[:dot]
+	}
+
+	version, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return uint(version), true
+}
+
+// notModified reports whether the request's If-None-Match header matches the ETag,
+// in which case the client's cached copy can be reused. Weak comparison is used,
+// as required for If-None-Match.
+func notModified(c *gin.Context, etag string) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModifiedSince reports whether the request's If-Modified-Since header is at or after
+// lastModified, in which case the client's cached copy can be reused. The header is
+// ignored when If-None-Match is present, which takes precedence (RFC 9110, 13.1.3).
+func notModifiedSince(c *gin.Context, lastModified time.Time) bool {
+	header := c.GetHeader("If-Modified-Since")
+	if header == "" || c.GetHeader("If-None-Match") != "" || lastModified.IsZero() {
+		return false
+	}
+
+	since, err := http.ParseTime(header)
+	if err != nil {
+		return false
+	}
+	// HTTP dates have a precision of one second
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// requireIfMatch checks the If-Match header of a write request against the article version.
+//
+// It writes 428 Precondition Required when the header is missing and 412 Precondition
+// Failed when no listed ETag matches the current version ("*" matches any version).
+// It returns the version the client expects, and false after writing an error response.
+func requireIfMatch(c *gin.Context, article models.Article) (uint, bool) {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		c.Error(apierror.New(http.StatusPreconditionRequired, "If-Match header is required"))
+		return 0, false
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return article.Version, true
+		}
+		if version, ok := etagVersion(candidate); ok && version == article.Version {
+			return version, true
+		}
+	}
+
+	c.Error(apierror.New(http.StatusPreconditionFailed, "Article has been modified since it was retrieved"))
+	return 0, false
+}
@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/apierror"
+	"github.com/jasen-devvv/mini-blog-backend/background"
+	"github.com/jasen-devvv/mini-blog-backend/exporter"
+	"github.com/jasen-devvv/mini-blog-backend/logging"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+)
+
+// ExportInput defines the structure for export requests
+type ExportInput struct {
+	Scope    string `json:"scope" binding:"omitempty,oneof=blog user"`
+	Username string `json:"username" binding:"max=100"`
+}
+
+// CreateExport starts an export as a ZIP archive of Markdown articles with front matter,
+// comments as JSON and the media they reference.
+//
+// Scope "user" (the default) exports the caller's own data, for data portability requests;
+// administrators may export another user by username. Scope "blog" exports every article
+// and requires administrator access. The export runs in the background: poll GetExport
+// for its status and download link.
+// Returns 202 Accepted with the export job or an appropriate error message.
+func (h *Handler) CreateExport(c *gin.Context) {
+	var input ExportInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apierror.Validation(err))
+		return
+	}
+	if input.Scope == "" {
+		input.Scope = exporter.ScopeUser
+	}
+
+	// Get user_id from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var user models.User
+	if err := h.db(c).First(&user, userID.(uint)).Error; err != nil {
+		c.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	job := models.ExportJob{UserID: user.ID, Scope: input.Scope, Status: models.ExportStatusPending}
+
+	// Only administrators may export the whole blog or someone else's data
+	if input.Scope == exporter.ScopeBlog || (input.Username != "" && input.Username != user.Username) {
+		if user.Role != models.RoleAdmin {
+			c.Error(apierror.New(http.StatusForbidden, "Administrator access required"))
+			return
+		}
+	}
+
+	if input.Scope == exporter.ScopeUser {
+		subjectID := user.ID
+		if input.Username != "" && input.Username != user.Username {
+			var subject models.User
+			if err := h.db(c).Where("username = ?", input.Username).First(&subject).Error; err != nil {
+				c.Error(apierror.FromDB(err, "User not found"))
+				return
+			}
+			subjectID = subject.ID
+		}
+		job.SubjectUserID = &subjectID
+	}
+
+	if err := h.db(c).Create(&job).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to create export"))
+		return
+	}
+
+	// Build the archive in the background; shutting down fails the export. Its queries
+	// and logs keep the request's logger and trace, but not its cancellation.
+	jobDB := h.DB.WithContext(context.WithoutCancel(c.Request.Context()))
+	jobCtx := logging.WithLogger(background.Context(), logging.FromContext(c.Request.Context()))
+	background.Go(func() {
+		exporter.Process(jobCtx, jobDB, h.Config.ExportDir, job.ID)
+	})
+
+	c.JSON(http.StatusAccepted, gin.H{"data": job})
+}
+
+// GetExport returns the status of an export requested by the caller (administrators
+// can see every export). Completed exports carry a download_url that expires at expires_at.
+// Returns a JSON response with the export job or an appropriate error message.
+func (h *Handler) GetExport(c *gin.Context) {
+	// Get user_id from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var job models.ExportJob
+	if err := h.db(c).First(&job, c.Param("id")).Error; err != nil {
+		c.Error(apierror.FromDB(err, "Export not found"))
+		return
+	}
+
+	// Check if user requested the export or is an administrator
+	if job.UserID != userID.(uint) {
+		var user models.User
+		if err := h.db(c).Select("id", "role").First(&user, userID).Error; err != nil || user.Role != models.RoleAdmin {
+			c.Error(apierror.New(http.StatusNotFound, "Export not found"))
+			return
+		}
+	}
+
+	data := gin.H{"export": job}
+	if job.Status == models.ExportStatusCompleted && job.ExpiresAt != nil && time.Now().Before(*job.ExpiresAt) {
+		data["download_url"] = fmt.Sprintf("%s/api/exports/%d/download?token=%s", h.Config.APIURL, job.ID, job.Token)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// DownloadExport serves the archive of a completed export.
+// It needs no authentication: the secret token from the download URL grants access until
+// the link expires (410 Gone afterwards).
+func (h *Handler) DownloadExport(c *gin.Context) {
+	var job models.ExportJob
+	if err := h.db(c).First(&job, c.Param("id")).Error; err != nil {
+		c.Error(apierror.FromDB(err, "Export not found"))
+		return
+	}
+
+	// Compare tokens in constant time, and reject jobs without one
+	token := c.Query("token")
+	if job.Token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(job.Token)) != 1 {
+		c.Error(apierror.New(http.StatusNotFound, "Export not found"))
+		return
+	}
+
+	if job.Status != models.ExportStatusCompleted || job.ExpiresAt == nil || time.Now().After(*job.ExpiresAt) {
+		c.Error(apierror.New(http.StatusGone, "Download link has expired"))
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	c.FileAttachment(job.FilePath, fmt.Sprintf("export-%d.zip", job.ID))
+}
@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/apierror"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"github.com/jasen-devvv/mini-blog-backend/notifications"
+	"gorm.io/gorm/clause"
+)
+
+// FollowUser makes the authenticated user follow the author identified by username.
+// Requires authentication, as it uses the user_id from the context (set by auth middleware).
+// Following an author twice is a no-op, and users cannot follow themselves.
+// Returns a JSON response with the author's updated follower count or an error message.
+func (h *Handler) FollowUser(c *gin.Context) {
+	// Get user_id from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	// Find the author to follow
+	var author models.User
+	if err := h.db(c).Where("username = ?", c.Param("username")).First(&author).Error; err != nil {
+		c.Error(apierror.FromDB(err, "User not found"))
+		return
+	}
+
+	if author.ID == userID.(uint) {
+		c.Error(apierror.New(http.StatusBadRequest, "You cannot follow yourself"))
+		return
+	}
+
+	// Create the follow, ignoring duplicates
+	follow := models.Follow{FollowerID: userID.(uint), FolloweeID: author.ID}
+	result := h.db(c).Clauses(clause.OnConflict{DoNothing: true}).Create(&follow)
+	if result.Error != nil {
+		c.Error(apierror.Internal(result.Error, "Failed to follow user"))
+		return
+	}
+
+	// Notify the author about new followers only
+	if result.RowsAffected > 0 {
+		notifications.Notify(c.Request.Context(), notifications.Event{
+			Type:        models.NotificationTypeFollow,
+			RecipientID: author.ID,
+			ActorID:     follow.FollowerID,
+		})
+	}
+
+	followers, err := h.countFollows(c, author.ID)
+	if err != nil {
+		c.Error(apierror.Internal(err, "Failed to count followers"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"following": true, "followers_count": followers}})
+}
+
+// UnfollowUser makes the authenticated user stop following the author identified by username.
+// Requires authentication, as it uses the user_id from the context (set by auth middleware).
+// Unfollowing an author that is not followed is a no-op.
+// Returns a JSON response with the author's updated follower count or an error message.
+func (h *Handler) UnfollowUser(c *gin.Context) {
+	// Get user_id from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	// Find the author to unfollow
+	var author models.User
+	if err := h.db(c).Where("username = ?", c.Param("username")).First(&author).Error; err != nil {
+		c.Error(apierror.FromDB(err, "User not found"))
+		return
+	}
+
+	if err := h.db(c).Where("follower_id = ? AND followee_id = ?", userID, author.ID).Delete(&models.Follow{}).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to unfollow user"))
+		return
+	}
+
+	followers, err := h.countFollows(c, author.ID)
+	if err != nil {
+		c.Error(apierror.Internal(err, "Failed to count followers"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"following": false, "followers_count": followers}})
+}
+
+// GetFeed retrieves the newest published articles written by authors the authenticated user follows.
+// Requires authentication, as it uses the user_id from the context (set by auth middleware).
+//
+// Results use cursor pagination: pass the "next_cursor" value from a response as the
+// "cursor" query parameter to get the following page. The "limit" parameter sets the page size.
+// Returns a JSON response with the articles and the next cursor (null on the last page).
+func (h *Handler) GetFeed(c *gin.Context) {
+	// Get user_id from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	_, limit := getPagination(c)
+
+	// Join on follows so the database can walk the per-author feed index
+	query := h.db(c).
+		Joins("JOIN follows ON follows.followee_id = articles.user_id").
+		Where("follows.follower_id = ? AND articles.status = ?", userID, models.ArticleStatusPublished)
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		createdAt, id, err := decodeCursor(cursor)
+		if err != nil {
+			c.Error(apierror.New(http.StatusBadRequest, err.Error()))
+			return
+		}
+		query = query.Where("(articles.created_at, articles.id) < (?, ?)", createdAt, id)
+	}
+
+	// Fetch one extra row to know whether another page exists
+	var articles []models.Article
+	if err := query.Preload("User").Preload("Tags").Order("articles.created_at desc, articles.id desc").Limit(limit + 1).Find(&articles).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to get feed"))
+		return
+	}
+
+	var nextCursor *string
+	if len(articles) > limit {
+		articles = articles[:limit]
+		last := articles[len(articles)-1]
+		cursor := encodeCursor(last.CreatedAt, last.ID)
+		nextCursor = &cursor
+	}
+
+	// Remove private fields from user data for security
+	for i := range articles {
+		articles[i].User.HidePrivate()
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": articles, "next_cursor": nextCursor})
+}
+
+// countFollows returns how many users follow the given user.
+func (h *Handler) countFollows(c *gin.Context, userID uint) (int64, error) {
+	var count int64
+	err := h.db(c).Model(&models.Follow{}).Where("followee_id = ?", userID).Count(&count).Error
+	return count, err
+}
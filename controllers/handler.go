@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/config"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"github.com/jasen-devvv/mini-blog-backend/notifications"
+	"github.com/jasen-devvv/mini-blog-backend/repository"
+	"github.com/jasen-devvv/mini-blog-backend/services"
+	"gorm.io/gorm"
+)
+
+// Handler serves the API endpoints.
+//
+// The core endpoints (authentication, articles, comments and profiles) go through the
+// services, so tests can build a Handler on in-memory repositories. The other endpoints
+// query DB directly, within the request's context (see db).
+//
+// Fields:
+//   - Auth: Registers users and logs them in.
+//   - Articles: Manages articles.
+//   - Comments: Manages comments.
+//   - Users: Manages profiles.
+//   - DB: Database queried by the endpoints that have no service.
+//   - Hooks: Features the handlers trigger that live outside the services.
+//   - Config: Application settings. Links are built from Config.SiteURL and Config.APIURL,
+//     never from the request, so a forged Host header cannot change them.
+type Handler struct {
+	Auth     *services.AuthService
+	Articles *services.ArticleService
+	Comments *services.CommentService
+	Users    *services.UserService
+	DB       *gorm.DB
+	Hooks    Hooks
+	Config   *config.Config
+}
+
+// NewHandler returns the Handler used in production, with its services working on the
+// database db and signing tokens with cfg.JWTSecret.
+func NewHandler(db *gorm.DB, cfg *config.Config) *Handler {
+	users := repository.NewUserRepository(db)
+	articles := repository.NewArticleRepository(db)
+	comments := repository.NewCommentRepository(db)
+	h := &Handler{
+		Auth:     services.NewAuthService(users, cfg.JWTSecret),
+		Articles: services.NewArticleService(articles),
+		Comments: services.NewCommentService(articles, comments, notifications.Notify),
+		Users:    services.NewUserService(users, articles),
+		DB:       db,
+		Config:   cfg,
+	}
+	h.Hooks = DefaultHooks(h)
+	return h
+}
+
+// db returns the database connection bound to the request's context, so queries are
+// traced as part of the request and their errors logged with its request ID.
+func (h *Handler) db(c *gin.Context) *gorm.DB {
+	return h.DB.WithContext(c.Request.Context())
+}
+
+// Hooks connects the handlers to features that live outside the services: reactions,
+// edit locks, and the ActivityPub and Webmention notifications sent about articles.
+type Hooks interface {
+	// AttachArticleReactions fills the reaction counts and the caller's reactions of articles.
+	AttachArticleReactions(c *gin.Context, articles []models.Article) error
+	// AttachCommentReactions fills the reaction counts and the caller's reactions of comments.
+	AttachCommentReactions(c *gin.Context, comments []models.Comment) error
+	// CheckEditLock applies the article's edit lock before a save, writing an error response
+	// and returning false when the save must not proceed.
+	CheckEditLock(c *gin.Context, article models.Article) bool
+	// ArticleCreated is called after an article was created.
+	ArticleCreated(c *gin.Context, article models.Article)
+	// ArticleUpdated is called after an article was changed.
+	ArticleUpdated(c *gin.Context, wasPublished bool, article models.Article)
+	// ArticleDeleted is called after an article was deleted.
+	ArticleDeleted(c *gin.Context, article models.Article)
+}
+
+// DefaultHooks returns the Hooks used in production, working on h.DB and building the
+// links they send from h.Config.
+func DefaultHooks(h *Handler) Hooks {
+	return defaultHooks{h: h}
+}
+
+type defaultHooks struct {
+	h *Handler
+}
+
+func (hooks defaultHooks) AttachArticleReactions(c *gin.Context, articles []models.Article) error {
+	return hooks.h.attachArticleReactions(c, articles)
+}
+
+func (hooks defaultHooks) AttachCommentReactions(c *gin.Context, comments []models.Comment) error {
+	return hooks.h.attachCommentReactions(c, comments)
+}
+
+func (defaultHooks) CheckEditLock(c *gin.Context, article models.Article) bool {
+	return checkEditLock(c, article)
+}
+
+// ArticleCreated delivers published articles to remote followers and notifies the sites they link to.
+func (hooks defaultHooks) ArticleCreated(c *gin.Context, article models.Article) {
+	if article.Status == models.ArticleStatusPublished {
+		hooks.h.federateArticle(c, "Create", article)
+		hooks.h.sendWebmentions(c, article)
+	}
+}
+
+// ArticleUpdated keeps remote followers in sync with the change and notifies the sites it links to.
+func (hooks defaultHooks) ArticleUpdated(c *gin.Context, wasPublished bool, article models.Article) {
+	hooks.h.federateArticleChange(c, wasPublished, article)
+	if article.Status == models.ArticleStatusPublished {
+		hooks.h.sendWebmentions(c, article)
+	}
+}
+
+// ArticleDeleted removes published articles from remote followers' timelines and records
+// the deletion, so feeds that listed them stop answering 304 Not Modified.
+func (hooks defaultHooks) ArticleDeleted(c *gin.Context, article models.Article) {
+	if article.Status == models.ArticleStatusPublished {
+		hooks.h.recordArticleDeletion(c, article)
+		hooks.h.federateArticle(c, "Delete", article)
+	}
+}
+
+// paramID reads a numeric ID from a path parameter.
+func paramID(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// viewerID returns the ID of the authenticated caller, or 0 for anonymous callers
+// (user_id is set by the auth middlewares).
+func viewerID(c *gin.Context) uint {
+	userID, _ := c.Get("user_id")
+	id, _ := userID.(uint)
+	return id
+}
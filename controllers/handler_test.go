@@ -0,0 +1,589 @@
+package controllers_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/apierror"
+	"github.com/jasen-devvv/mini-blog-backend/config"
+	"github.com/jasen-devvv/mini-blog-backend/controllers"
+	"github.com/jasen-devvv/mini-blog-backend/internal/testutil"
+	"github.com/jasen-devvv/mini-blog-backend/middleware"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"github.com/jasen-devvv/mini-blog-backend/notifications"
+	"github.com/jasen-devvv/mini-blog-backend/repository/memory"
+	"github.com/jasen-devvv/mini-blog-backend/routes"
+	"github.com/jasen-devvv/mini-blog-backend/services"
+)
+
+const testSecret = "test-secret"
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// testHooks records the article events.
+type testHooks struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (h *testHooks) AttachArticleReactions(c *gin.Context, articles []models.Article) error {
+	return nil
+}
+
+func (h *testHooks) AttachCommentReactions(c *gin.Context, comments []models.Comment) error {
+	return nil
+}
+
+func (h *testHooks) CheckEditLock(c *gin.Context, article models.Article) bool {
+	return true
+}
+
+func (h *testHooks) ArticleCreated(c *gin.Context, article models.Article) {
+	h.record("created %d", article.ID)
+}
+
+func (h *testHooks) ArticleUpdated(c *gin.Context, wasPublished bool, article models.Article) {
+	h.record("updated %d", article.ID)
+}
+
+func (h *testHooks) ArticleDeleted(c *gin.Context, article models.Article) {
+	h.record("deleted %d", article.ID)
+}
+
+func (h *testHooks) record(format string, args ...interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, fmt.Sprintf(format, args...))
+}
+
+// testServer is a router serving the core endpoints on in-memory repositories.
+type testServer struct {
+	t             *testing.T
+	router        *gin.Engine
+	store         *memory.Store
+	handler       *controllers.Handler
+	hooks         *testHooks
+	notifications []notifications.Event
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	s := &testServer{t: t, store: memory.NewStore(), hooks: &testHooks{}}
+	users, articles, comments := s.store.Users(), s.store.Articles(), s.store.Comments()
+	s.handler = &controllers.Handler{
+		Auth:     services.NewAuthService(users, testSecret),
+		Articles: services.NewArticleService(articles),
+		Comments: services.NewCommentService(articles, comments, func(ctx context.Context, event notifications.Event) {
+			s.notifications = append(s.notifications, event)
+		}),
+		Users:  services.NewUserService(users, articles),
+		Hooks:  s.hooks,
+		Config: &config.Config{JWTSecret: testSecret},
+	}
+
+	s.router = gin.New()
+	s.router.Use(middleware.ErrorMiddleware())
+	routes.SetupAuthRoutes(s.router, s.handler)
+	routes.SetupArticleRoutes(s.router, s.handler)
+	routes.SetupCommentRoutes(s.router, s.handler)
+	routes.SetupUserRoutes(s.router, s.handler)
+	return s
+}
+
+// user registers a user and returns it with a token authenticating them.
+func (s *testServer) user(username string) (*models.User, string) {
+	s.t.Helper()
+
+	user, err := s.handler.Auth.Register(context.Background(), username, username+"@example.com", "password")
+	if err != nil {
+		s.t.Fatalf("registering %s: %v", username, err)
+	}
+	token, err := s.handler.Auth.IssueToken(user)
+	if err != nil {
+		s.t.Fatalf("issuing token for %s: %v", username, err)
+	}
+	return user, token
+}
+
+// article creates an article through the API and returns it.
+func (s *testServer) article(token string, input gin.H) models.Article {
+	s.t.Helper()
+
+	w := s.do(http.MethodPost, "/api/articles", token, input)
+	if w.Code != http.StatusCreated {
+		s.t.Fatalf("creating article: %d %s", w.Code, w.Body)
+	}
+	var body struct{ Data models.Article }
+	testutil.Decode(s.t, w, &body)
+	return body.Data
+}
+
+// do sends a request to the router, see testutil.Do.
+func (s *testServer) do(method, path, token string, body interface{}, headers ...string) *httptest.ResponseRecorder {
+	s.t.Helper()
+	return testutil.Do(s.t, s.router, method, path, token, body, headers...)
+}
+
+func TestRegister(t *testing.T) {
+	s := newTestServer(t)
+
+	w := s.do(http.MethodPost, "/api/auth/register", "", gin.H{"username": "alice", "email": "alice@example.com", "password": "secret1"})
+	testutil.ExpectStatus(t, w, http.StatusCreated)
+	if bytes.Contains(w.Body.Bytes(), []byte("secret1")) || bytes.Contains(w.Body.Bytes(), []byte(`"password"`)) {
+		t.Errorf("response leaks the password: %s", w.Body)
+	}
+
+	tests := []struct {
+		name   string
+		body   gin.H
+		status int
+		code   string
+		field  string
+	}{
+		{"duplicate username", gin.H{"username": "alice", "email": "other@example.com", "password": "secret1"}, http.StatusConflict, "user_exists", ""},
+		{"duplicate email", gin.H{"username": "other", "email": "alice@example.com", "password": "secret1"}, http.StatusConflict, "user_exists", ""},
+		{"short password", gin.H{"username": "bob", "email": "bob@example.com", "password": "123"}, http.StatusBadRequest, "validation_failed", "password"},
+		{"invalid email", gin.H{"username": "bob", "email": "bob", "password": "secret1"}, http.StatusBadRequest, "validation_failed", "email"},
+		{"missing username", gin.H{"email": "bob@example.com", "password": "secret1"}, http.StatusBadRequest, "validation_failed", "username"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := s.do(http.MethodPost, "/api/auth/register", "", tt.body)
+			testutil.ExpectStatus(t, w, tt.status)
+			if contentType := w.Header().Get("Content-Type"); !strings.HasPrefix(contentType, apierror.ContentType) {
+				t.Errorf("Content-Type = %q, want %s", contentType, apierror.ContentType)
+			}
+			var problem apierror.Problem
+			testutil.Decode(t, w, &problem)
+			if problem.Status != tt.status || problem.Code != tt.code {
+				t.Errorf("problem = %+v, want status %d and code %s", problem, tt.status, tt.code)
+			}
+			if tt.field != "" && (len(problem.Errors) != 1 || problem.Errors[0].Field != tt.field) {
+				t.Errorf("errors = %+v, want one for %s", problem.Errors, tt.field)
+			}
+		})
+	}
+}
+
+func TestLogin(t *testing.T) {
+	s := newTestServer(t)
+	s.user("alice")
+
+	w := s.do(http.MethodPost, "/api/auth/login", "", gin.H{"email": "alice@example.com", "password": "password"})
+	testutil.ExpectStatus(t, w, http.StatusOK)
+	var body struct {
+		Token string
+		User  models.User
+	}
+	testutil.Decode(t, w, &body)
+	if body.Token == "" || body.User.Username != "alice" {
+		t.Fatalf("unexpected login response: %s", w.Body)
+	}
+
+	// The token authenticates protected routes
+	testutil.ExpectStatus(t, s.do(http.MethodPut, "/api/users/me", body.Token, gin.H{"bio": "Hi"}), http.StatusOK)
+
+	for name, input := range map[string]gin.H{
+		"wrong password": {"email": "alice@example.com", "password": "wrong"},
+		"unknown email":  {"email": "nobody@example.com", "password": "password"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			testutil.ExpectStatus(t, s.do(http.MethodPost, "/api/auth/login", "", input), http.StatusUnauthorized)
+		})
+	}
+}
+
+func TestProtectedRoutesRequireToken(t *testing.T) {
+	s := newTestServer(t)
+
+	for _, route := range []struct{ method, path string }{
+		{http.MethodPost, "/api/articles"},
+		{http.MethodPut, "/api/articles/1"},
+		{http.MethodPatch, "/api/articles/1"},
+		{http.MethodDelete, "/api/articles/1"},
+		{http.MethodPost, "/api/articles/1/comments"},
+		{http.MethodPut, "/api/users/me"},
+	} {
+		testutil.ExpectStatus(t, s.do(route.method, route.path, "", gin.H{}), http.StatusUnauthorized)
+		testutil.ExpectStatus(t, s.do(route.method, route.path, "not-a-token", gin.H{}), http.StatusUnauthorized)
+	}
+}
+
+func TestCreateArticle(t *testing.T) {
+	s := newTestServer(t)
+	alice, token := s.user("alice")
+
+	article := s.article(token, gin.H{"title": "Hello World", "content": "Body", "tags": []string{"Go Lang", "go lang", "web"}})
+	if article.UserID != alice.ID || article.User.Username != "alice" {
+		t.Errorf("article author = %d %q, want alice", article.UserID, article.User.Username)
+	}
+	if article.User.Email != "" {
+		t.Errorf("article leaks the author's email")
+	}
+	if article.Slug != "hello-world" || article.Status != models.ArticleStatusPublished || article.Version != 1 {
+		t.Errorf("article = %+v", article)
+	}
+	if len(article.Tags) != 2 || article.Tags[0].Name != "go-lang" || article.Tags[1].Name != "web" {
+		t.Errorf("tags = %+v, want go-lang and web", article.Tags)
+	}
+
+	// Slugs stay unique
+	if second := s.article(token, gin.H{"title": "Hello, World!", "content": "Body"}); second.Slug != "hello-world-2" {
+		t.Errorf("second slug = %q, want hello-world-2", second.Slug)
+	}
+
+	if len(s.hooks.events) != 2 || s.hooks.events[0] != fmt.Sprintf("created %d", article.ID) {
+		t.Errorf("events = %v", s.hooks.events)
+	}
+
+	for name, input := range map[string]gin.H{
+		"missing title":  {"content": "Body"},
+		"missing body":   {"title": "Title"},
+		"invalid status": {"title": "Title", "content": "Body", "status": "archived"},
+		"invalid cover":  {"title": "Title", "content": "Body", "cover_url": "not a url"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			testutil.ExpectStatus(t, s.do(http.MethodPost, "/api/articles", token, input), http.StatusBadRequest)
+		})
+	}
+}
+
+func TestGetArticles(t *testing.T) {
+	s := newTestServer(t)
+	_, aliceToken := s.user("alice")
+	_, bobToken := s.user("bob")
+
+	first := s.article(aliceToken, gin.H{"title": "First", "content": "Body"})
+	draft := s.article(aliceToken, gin.H{"title": "Draft", "content": "Body", "status": "draft"})
+	second := s.article(bobToken, gin.H{"title": "Second", "content": "Body"})
+
+	// The list only shows published articles, newest first
+	w := s.do(http.MethodGet, "/api/articles", "", nil)
+	testutil.ExpectStatus(t, w, http.StatusOK)
+	var list struct{ Data []models.Article }
+	testutil.Decode(t, w, &list)
+	if len(list.Data) != 2 || list.Data[0].ID != second.ID || list.Data[1].ID != first.ID {
+		t.Fatalf("articles = %+v", list.Data)
+	}
+
+	// Drafts are only visible to their author
+	draftPath := fmt.Sprintf("/api/articles/%d", draft.ID)
+	testutil.ExpectStatus(t, s.do(http.MethodGet, draftPath, "", nil), http.StatusNotFound)
+	testutil.ExpectStatus(t, s.do(http.MethodGet, draftPath, bobToken, nil), http.StatusNotFound)
+	testutil.ExpectStatus(t, s.do(http.MethodGet, draftPath, aliceToken, nil), http.StatusOK)
+
+	testutil.ExpectStatus(t, s.do(http.MethodGet, "/api/articles/999", "", nil), http.StatusNotFound)
+	testutil.ExpectStatus(t, s.do(http.MethodGet, "/api/articles/abc", "", nil), http.StatusNotFound)
+
+	// Unchanged articles are not sent again
+	w = s.do(http.MethodGet, fmt.Sprintf("/api/articles/%d", first.ID), "", nil)
+	testutil.ExpectStatus(t, w, http.StatusOK)
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("missing ETag")
+	}
+	testutil.ExpectStatus(t, s.do(http.MethodGet, fmt.Sprintf("/api/articles/%d", first.ID), "", nil, "If-None-Match", etag), http.StatusNotModified)
+
+	// The embedded author changing is a change too, but not a new version
+	time.Sleep(time.Millisecond)
+	testutil.ExpectStatus(t, s.do(http.MethodPut, "/api/users/me", aliceToken, gin.H{"display_name": "Alice"}), http.StatusOK)
+	w = s.do(http.MethodGet, fmt.Sprintf("/api/articles/%d", first.ID), "", nil, "If-None-Match", etag)
+	testutil.ExpectStatus(t, w, http.StatusOK)
+	if changed := w.Header().Get("ETag"); changed == etag || !strings.HasPrefix(changed, `"v1.`) {
+		t.Errorf("ETag = %q after the author changed, was %q", changed, etag)
+	}
+	if !strings.Contains(w.Body.String(), `"display_name":"Alice"`) {
+		t.Errorf("article = %s, want the new display name", w.Body)
+	}
+}
+
+func TestUpdateArticle(t *testing.T) {
+	s := newTestServer(t)
+	_, aliceToken := s.user("alice")
+	_, bobToken := s.user("bob")
+
+	article := s.article(aliceToken, gin.H{"title": "Title", "content": "Body", "excerpt": "Short", "tags": []string{"go"}})
+	path := fmt.Sprintf("/api/articles/%d", article.ID)
+	etag := `"v1"`
+	update := gin.H{"title": "New title", "content": "New body", "tags": []string{"web"}}
+
+	testutil.ExpectStatus(t, s.do(http.MethodPut, path, aliceToken, update), http.StatusPreconditionRequired)
+	testutil.ExpectStatus(t, s.do(http.MethodPut, path, aliceToken, update, "If-Match", `"v7"`), http.StatusPreconditionFailed)
+	testutil.ExpectStatus(t, s.do(http.MethodPut, path, bobToken, update, "If-Match", etag), http.StatusForbidden)
+	testutil.ExpectStatus(t, s.do(http.MethodPut, "/api/articles/999", aliceToken, update, "If-Match", etag), http.StatusNotFound)
+	testutil.ExpectStatus(t, s.do(http.MethodPut, path, aliceToken, gin.H{"title": "No content"}, "If-Match", etag), http.StatusBadRequest)
+
+	w := s.do(http.MethodPut, path, aliceToken, update, "If-Match", etag)
+	testutil.ExpectStatus(t, w, http.StatusOK)
+	var body struct{ Data models.Article }
+	testutil.Decode(t, w, &body)
+	if body.Data.Title != "New title" || body.Data.Excerpt != "" || body.Data.Version != 2 {
+		t.Errorf("updated article = %+v", body.Data)
+	}
+	if len(body.Data.Tags) != 1 || body.Data.Tags[0].Name != "web" {
+		t.Errorf("tags = %+v, want web", body.Data.Tags)
+	}
+	if etag := w.Header().Get("ETag"); !strings.HasPrefix(etag, `"v2.`) {
+		t.Errorf("ETag = %q, want version 2", etag)
+	}
+
+	// The old version can no longer be written
+	testutil.ExpectStatus(t, s.do(http.MethodPut, path, aliceToken, update, "If-Match", etag), http.StatusPreconditionFailed)
+}
+
+func TestPatchArticle(t *testing.T) {
+	s := newTestServer(t)
+	_, token := s.user("alice")
+
+	article := s.article(token, gin.H{"title": "Title", "content": "Body", "excerpt": "Short", "tags": []string{"go"}})
+	path := fmt.Sprintf("/api/articles/%d", article.ID)
+	patch := func(body string, headers ...string) *httptest.ResponseRecorder {
+		return s.do(http.MethodPatch, path, token, body, append([]string{"Content-Type", "application/merge-patch+json"}, headers...)...)
+	}
+
+	testutil.ExpectStatus(t, patch(`{"title": "X"}`), http.StatusPreconditionRequired)
+	testutil.ExpectStatus(t, s.do(http.MethodPatch, path, token, `{"title": "X"}`, "Content-Type", "text/plain", "If-Match", `"v1"`), http.StatusUnsupportedMediaType)
+	testutil.ExpectStatus(t, patch(`[1]`, "If-Match", `"v1"`), http.StatusBadRequest)
+
+	w := patch(`{"title": "", "slug": "x", "noindex": "yes", "content": null}`, "If-Match", `"v1"`)
+	testutil.ExpectStatus(t, w, http.StatusUnprocessableEntity)
+	var invalid apierror.Problem
+	testutil.Decode(t, w, &invalid)
+	if len(invalid.Errors) != 4 {
+		t.Errorf("errors = %v, want title, slug, noindex and content", invalid.Errors)
+	}
+
+	w = patch(`{"title": "Patched", "excerpt": null, "noindex": true}`, "If-Match", `"v1"`)
+	testutil.ExpectStatus(t, w, http.StatusOK)
+	var body struct{ Data models.Article }
+	testutil.Decode(t, w, &body)
+	if body.Data.Title != "Patched" || body.Data.Content != "Body" || body.Data.Excerpt != "" || !body.Data.NoIndex || body.Data.Version != 2 {
+		t.Errorf("patched article = %+v", body.Data)
+	}
+	if len(body.Data.Tags) != 1 {
+		t.Errorf("patch changed the tags: %+v", body.Data.Tags)
+	}
+
+	// An empty patch changes nothing
+	w = patch(`{}`, "If-Match", `"v2"`)
+	testutil.ExpectStatus(t, w, http.StatusOK)
+	testutil.Decode(t, w, &body)
+	if body.Data.Version != 2 {
+		t.Errorf("empty patch changed the version to %d", body.Data.Version)
+	}
+
+	// Lengths are counted in characters, like PUT does
+	testutil.ExpectStatus(t, patch(fmt.Sprintf(`{"title": %q}`, strings.Repeat("é", 256)), "If-Match", `"v2"`), http.StatusUnprocessableEntity)
+	testutil.ExpectStatus(t, patch(`{"tags": ["a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"]}`, "If-Match", `"v2"`), http.StatusUnprocessableEntity)
+	testutil.ExpectStatus(t, patch(`{"tags": ["go", ""]}`, "If-Match", `"v2"`), http.StatusUnprocessableEntity)
+
+	w = patch(fmt.Sprintf(`{"title": %q, "tags": ["Rust", "web"]}`, strings.Repeat("é", 255)), "If-Match", `"v2"`)
+	testutil.ExpectStatus(t, w, http.StatusOK)
+	testutil.Decode(t, w, &body)
+	if len(body.Data.Tags) != 2 || body.Data.Version != 3 {
+		t.Errorf("tags after patch = %+v (version %d), want rust and web", body.Data.Tags, body.Data.Version)
+	}
+
+	// null removes every tag
+	w = patch(`{"tags": null}`, "If-Match", `"v3"`)
+	testutil.ExpectStatus(t, w, http.StatusOK)
+	testutil.Decode(t, w, &body)
+	if len(body.Data.Tags) != 0 {
+		t.Errorf("tags after removing them = %+v", body.Data.Tags)
+	}
+}
+
+func TestDeleteArticle(t *testing.T) {
+	s := newTestServer(t)
+	_, aliceToken := s.user("alice")
+	_, bobToken := s.user("bob")
+
+	article := s.article(aliceToken, gin.H{"title": "Title", "content": "Body"})
+	path := fmt.Sprintf("/api/articles/%d", article.ID)
+
+	testutil.ExpectStatus(t, s.do(http.MethodDelete, path, bobToken, nil, "If-Match", `"v1"`), http.StatusForbidden)
+	testutil.ExpectStatus(t, s.do(http.MethodDelete, path, aliceToken, nil), http.StatusPreconditionRequired)
+	testutil.ExpectStatus(t, s.do(http.MethodDelete, path, aliceToken, nil, "If-Match", `"v2"`), http.StatusPreconditionFailed)
+	testutil.ExpectStatus(t, s.do(http.MethodDelete, path, aliceToken, nil, "If-Match", `"v1"`), http.StatusOK)
+	testutil.ExpectStatus(t, s.do(http.MethodGet, path, aliceToken, nil), http.StatusNotFound)
+	testutil.ExpectStatus(t, s.do(http.MethodDelete, path, aliceToken, nil, "If-Match", "*"), http.StatusNotFound)
+
+	if last := s.hooks.events[len(s.hooks.events)-1]; last != fmt.Sprintf("deleted %d", article.ID) {
+		t.Errorf("last event = %q", last)
+	}
+}
+
+func TestComments(t *testing.T) {
+	s := newTestServer(t)
+	alice, aliceToken := s.user("alice")
+	bob, bobToken := s.user("bob")
+	carol, carolToken := s.user("carol")
+
+	article := s.article(aliceToken, gin.H{"title": "Title", "content": "Body"})
+	other := s.article(aliceToken, gin.H{"title": "Other", "content": "Body"})
+	path := fmt.Sprintf("/api/articles/%d/comments", article.ID)
+
+	w := s.do(http.MethodPost, path, bobToken, gin.H{"content": "Nice post"})
+	testutil.ExpectStatus(t, w, http.StatusCreated)
+	var created struct{ Data models.Comment }
+	testutil.Decode(t, w, &created)
+	if created.Data.User == nil || created.Data.User.Username != "bob" || created.Data.User.Email != "" {
+		t.Errorf("comment author = %+v", created.Data.User)
+	}
+
+	// The article author is notified
+	if len(s.notifications) != 1 || s.notifications[0].RecipientID != alice.ID || s.notifications[0].ActorID != bob.ID {
+		t.Errorf("notifications = %+v", s.notifications)
+	}
+
+	// Replies notify the parent comment's author too
+	w = s.do(http.MethodPost, path, carolToken, gin.H{"content": "Agreed", "parent_id": created.Data.ID})
+	testutil.ExpectStatus(t, w, http.StatusCreated)
+	if len(s.notifications) != 3 || s.notifications[2].Type != models.NotificationTypeReply || s.notifications[2].RecipientID != bob.ID || s.notifications[2].ActorID != carol.ID {
+		t.Errorf("notifications = %+v", s.notifications)
+	}
+
+	// Replies must stay on the same article
+	otherPath := fmt.Sprintf("/api/articles/%d/comments", other.ID)
+	testutil.ExpectStatus(t, s.do(http.MethodPost, otherPath, carolToken, gin.H{"content": "Hm", "parent_id": created.Data.ID}), http.StatusBadRequest)
+	testutil.ExpectStatus(t, s.do(http.MethodPost, "/api/articles/999/comments", carolToken, gin.H{"content": "Hm"}), http.StatusNotFound)
+	testutil.ExpectStatus(t, s.do(http.MethodPost, path, carolToken, gin.H{}), http.StatusBadRequest)
+
+	w = s.do(http.MethodGet, path, "", nil)
+	testutil.ExpectStatus(t, w, http.StatusOK)
+	var list struct{ Data []models.Comment }
+	testutil.Decode(t, w, &list)
+	if len(list.Data) != 2 || list.Data[0].Content != "Nice post" || list.Data[1].Content != "Agreed" {
+		t.Fatalf("comments = %+v", list.Data)
+	}
+	if bytes.Contains(w.Body.Bytes(), []byte("@example.com")) {
+		t.Errorf("comments leak email addresses: %s", w.Body)
+	}
+}
+
+func TestUserProfile(t *testing.T) {
+	s := newTestServer(t)
+	alice, aliceToken := s.user("alice")
+	bob, _ := s.user("bob")
+	s.store.Follow(bob.ID, alice.ID)
+
+	for i := 1; i <= 3; i++ {
+		s.article(aliceToken, gin.H{"title": fmt.Sprintf("Post %d", i), "content": "Body"})
+	}
+	s.article(aliceToken, gin.H{"title": "Draft", "content": "Body", "status": "draft"})
+
+	w := s.do(http.MethodGet, "/api/users/alice?limit=2&page=2", "", nil)
+	testutil.ExpectStatus(t, w, http.StatusOK)
+	var body struct {
+		Data struct {
+			User           models.User
+			FollowersCount int64 `json:"followers_count"`
+			FollowingCount int64 `json:"following_count"`
+			Articles       []models.Article
+		}
+		Pagination struct{ Page, Limit, Total int }
+	}
+	testutil.Decode(t, w, &body)
+	if body.Data.User.Username != "alice" || body.Data.User.Email != "" {
+		t.Errorf("user = %+v", body.Data.User)
+	}
+	if body.Data.FollowersCount != 1 || body.Data.FollowingCount != 0 {
+		t.Errorf("follow counts = %d, %d", body.Data.FollowersCount, body.Data.FollowingCount)
+	}
+	if body.Pagination.Total != 3 || len(body.Data.Articles) != 1 || body.Data.Articles[0].Title != "Post 1" {
+		t.Errorf("articles = %+v, pagination = %+v", body.Data.Articles, body.Pagination)
+	}
+
+	testutil.ExpectStatus(t, s.do(http.MethodGet, "/api/users/nobody", "", nil), http.StatusNotFound)
+}
+
+func TestUpdateProfile(t *testing.T) {
+	s := newTestServer(t)
+	_, token := s.user("alice")
+
+	w := s.do(http.MethodPut, "/api/users/me", token, gin.H{
+		"display_name": "Alice",
+		"website":      "https://alice.example.com",
+		"social_links": gin.H{"github": "https://github.com/alice"},
+	})
+	testutil.ExpectStatus(t, w, http.StatusOK)
+
+	// Omitted fields are cleared
+	w = s.do(http.MethodPut, "/api/users/me", token, gin.H{"bio": "Writer"})
+	testutil.ExpectStatus(t, w, http.StatusOK)
+	var body struct{ Data models.User }
+	testutil.Decode(t, w, &body)
+	if body.Data.DisplayName != "" || body.Data.Bio != "Writer" || len(body.Data.SocialLinks) != 0 {
+		t.Errorf("profile = %+v", body.Data)
+	}
+
+	for name, input := range map[string]gin.H{
+		"invalid website":   {"website": "not a url"},
+		"unknown network":   {"social_links": gin.H{"myspace": "https://myspace.com/alice"}},
+		"long display name": {"display_name": string(bytes.Repeat([]byte("a"), 101))},
+	} {
+		t.Run(name, func(t *testing.T) {
+			testutil.ExpectStatus(t, s.do(http.MethodPut, "/api/users/me", token, input), http.StatusBadRequest)
+		})
+	}
+}
+
+func TestCollaborators(t *testing.T) {
+	s := newTestServer(t)
+	_, aliceToken := s.user("alice")
+	_, bobToken := s.user("bob")
+	_, carolToken := s.user("carol")
+
+	draft := s.article(aliceToken, gin.H{"title": "Shared", "content": "Body", "status": "draft"})
+	path := fmt.Sprintf("/api/articles/%d", draft.ID)
+	collaborators := path + "/collaborators"
+
+	// Only the author shares an article, and not with themselves
+	testutil.ExpectStatus(t, s.do(http.MethodPut, collaborators+"/bob", carolToken, nil), http.StatusForbidden)
+	testutil.ExpectStatus(t, s.do(http.MethodPut, collaborators+"/alice", aliceToken, nil), http.StatusBadRequest)
+	testutil.ExpectStatus(t, s.do(http.MethodPut, collaborators+"/nobody", aliceToken, nil), http.StatusNotFound)
+	testutil.ExpectStatus(t, s.do(http.MethodPut, "/api/articles/999/collaborators/bob", aliceToken, nil), http.StatusNotFound)
+
+	w := s.do(http.MethodPut, collaborators+"/bob", aliceToken, nil)
+	testutil.ExpectStatus(t, w, http.StatusOK)
+	testutil.ExpectStatus(t, s.do(http.MethodPut, collaborators+"/bob", aliceToken, nil), http.StatusOK)
+	var list struct{ Data []models.User }
+	testutil.Decode(t, w, &list)
+	if len(list.Data) != 1 || list.Data[0].Username != "bob" || list.Data[0].Email != "" {
+		t.Fatalf("collaborators = %+v, want bob without private fields", list.Data)
+	}
+
+	// Collaborators see and edit the draft, but cannot delete or reshare it
+	testutil.ExpectStatus(t, s.do(http.MethodGet, path, bobToken, nil), http.StatusOK)
+	testutil.ExpectStatus(t, s.do(http.MethodGet, path, carolToken, nil), http.StatusNotFound)
+	testutil.ExpectStatus(t, s.do(http.MethodGet, collaborators, bobToken, nil), http.StatusOK)
+	testutil.ExpectStatus(t, s.do(http.MethodGet, collaborators, carolToken, nil), http.StatusForbidden)
+	testutil.ExpectStatus(t, s.do(http.MethodPut, path, bobToken, gin.H{"title": "Edited by Bob", "content": "Body", "status": "draft"}, "If-Match", `"v1"`), http.StatusOK)
+	testutil.ExpectStatus(t, s.do(http.MethodPut, path, carolToken, gin.H{"title": "Hijacked", "content": "Body"}, "If-Match", `"v2"`), http.StatusForbidden)
+	testutil.ExpectStatus(t, s.do(http.MethodDelete, path, bobToken, nil, "If-Match", `"v2"`), http.StatusForbidden)
+	testutil.ExpectStatus(t, s.do(http.MethodPut, collaborators+"/carol", bobToken, nil), http.StatusForbidden)
+
+	// Unsharing takes the access away again
+	w = s.do(http.MethodDelete, collaborators+"/bob", aliceToken, nil)
+	testutil.ExpectStatus(t, w, http.StatusOK)
+	testutil.Decode(t, w, &list)
+	if len(list.Data) != 0 {
+		t.Errorf("collaborators after unsharing = %+v", list.Data)
+	}
+	testutil.ExpectStatus(t, s.do(http.MethodDelete, collaborators+"/bob", aliceToken, nil), http.StatusNotFound)
+	testutil.ExpectStatus(t, s.do(http.MethodGet, path, bobToken, nil), http.StatusNotFound)
+	testutil.ExpectStatus(t, s.do(http.MethodPut, path, bobToken, gin.H{"title": "Again", "content": "Body"}, "If-Match", `"v2"`), http.StatusForbidden)
+}
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/logging"
+	"github.com/jasen-devvv/mini-blog-backend/migrations"
+)
+
+// readinessTimeout bounds the checks of a readiness probe, so a stuck database fails the
+// probe instead of hanging it.
+const readinessTimeout = 3 * time.Second
+
+// Healthz reports that the process is alive and serving requests. It checks nothing
+// else, so a failing dependency does not get the instance restarted.
+func (h *Handler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz reports whether the instance can serve traffic: the database must be reachable
+// and every migration applied. It returns 200 OK, or 503 Service Unavailable with the
+// failed checks. Check failures are logged with their cause.
+func (h *Handler) Readyz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+	logger := logging.FromContext(ctx)
+
+	checks := gin.H{"database": "ok", "migrations": "ok"}
+	if err := h.checkReadiness(ctx, checks); err != nil {
+		logger.Warn("readiness check failed", "error", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "checks": checks})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "checks": checks})
+}
+
+// checkReadiness pings the database and checks that no migration is pending, recording
+// the outcome of each check in checks.
+func (h *Handler) checkReadiness(ctx context.Context, checks gin.H) error {
+	sqlDB, err := h.DB.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+	if err != nil {
+		checks["database"] = "unreachable"
+		checks["migrations"] = "unknown"
+		return fmt.Errorf("database: %w", err)
+	}
+
+	states, err := migrations.Status(ctx, sqlDB)
+	if err != nil {
+		checks["migrations"] = "unknown"
+		return fmt.Errorf("migrations: %w", err)
+	}
+	pending := 0
+	for _, state := range states {
+		if state.AppliedAt == nil {
+			pending++
+		}
+	}
+	if pending > 0 {
+		checks["migrations"] = fmt.Sprintf("%d pending", pending)
+		return fmt.Errorf("migrations: %d pending", pending)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"archive/zip"
+	"context"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/apierror"
+	"github.com/jasen-devvv/mini-blog-backend/background"
+	"github.com/jasen-devvv/mini-blog-backend/importer"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+)
+
+// ImportInput defines the structure for import requests (sent as a multipart form with a "file")
+type ImportInput struct {
+	Format        string `form:"format" binding:"required,oneof=wxr markdown"`
+	DryRun        bool   `form:"dry_run"`
+	DefaultAuthor string `form:"default_author"`
+	Source        string `form:"source" binding:"max=255"`
+	MatchUsers    bool   `form:"match_users"`
+}
+
+// ImportContent starts an import of posts from another blogging platform.
+// Requires administrator access (see middleware.AdminMiddleware).
+//
+// The uploaded file is a WordPress WXR export (format "wxr") or a ZIP archive of Markdown
+// files with YAML front matter (format "markdown"). Authors, tags, dates, comments and slugs
+// are mapped onto our models. Authors become new users unless match_users is set, which
+// gives their posts to the existing users with the same username or email. Imports are
+// idempotent: items imported from the same source before are skipped, so a failed import
+// is resumed by uploading the file again. With dry_run, nothing is saved and the report
+// shows what would be imported.
+//
+// The file is parsed right away, but the import runs in the background: poll GetImport
+// for its status and report.
+// Returns 202 Accepted with the import job or an appropriate error message.
+func (h *Handler) ImportContent(c *gin.Context) {
+	var input ImportInput
+	if err := c.ShouldBind(&input); err != nil {
+		c.Error(apierror.Validation(err))
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.Error(apierror.New(http.StatusBadRequest, "An import file is required"))
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.Error(apierror.New(http.StatusBadRequest, "Failed to read the import file"))
+		return
+	}
+	defer file.Close()
+
+	// Parse the upload into posts
+	var posts []importer.Post
+	var source string
+	switch input.Format {
+	case "wxr":
+		var site string
+		posts, site, err = importer.ParseWXR(file)
+		source = "wxr:" + site
+	case "markdown":
+		var archive *zip.Reader
+		archive, err = zip.NewReader(file, header.Size)
+		if err == nil {
+			posts, err = importer.ParseMarkdownDir(archive)
+		}
+		source = "markdown:" + strings.TrimSuffix(path.Base(header.Filename), path.Ext(header.Filename))
+	}
+	if err != nil {
+		c.Error(apierror.New(http.StatusBadRequest, err.Error()))
+		return
+	}
+	if input.Source != "" {
+		source = input.Source
+	}
+
+	job := models.ImportJob{
+		UserID: viewerID(c),
+		Source: source,
+		Format: input.Format,
+		DryRun: input.DryRun,
+		Status: models.ImportStatusPending,
+	}
+	if err := h.db(c).Create(&job).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to create import"))
+		return
+	}
+
+	// Import in the background; shutting down stops the import between posts and fails
+	// the job. Its queries keep the request's logger and trace, but not its cancellation.
+	options := importer.Options{
+		Source:        source,
+		DryRun:        input.DryRun,
+		DefaultAuthor: input.DefaultAuthor,
+		MatchUsers:    input.MatchUsers,
+	}
+	jobDB := h.DB.WithContext(context.WithoutCancel(c.Request.Context()))
+	background.Go(func() {
+		importer.Process(background.Context(), jobDB, job.ID, posts, options)
+	})
+
+	c.JSON(http.StatusAccepted, gin.H{"data": job})
+}
+
+// GetImport returns the status of an import, with its report once it finished.
+// Requires administrator access (see middleware.AdminMiddleware).
+// Returns a JSON response with the import job or an appropriate error message.
+func (h *Handler) GetImport(c *gin.Context) {
+	var job models.ImportJob
+	if err := h.db(c).First(&job, c.Param("id")).Error; err != nil {
+		c.Error(apierror.FromDB(err, "Import not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": job})
+}
@@ -0,0 +1,31 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/apierror"
+	"github.com/jasen-devvv/mini-blog-backend/metrics"
+)
+
+// GetMetrics serves the application's metrics in the Prometheus text format.
+//
+// When Config.MetricsToken is set, scrapers must send it in an
+// "Authorization: Bearer {token}" header; other requests get 401 Unauthorized.
+func (h *Handler) GetMetrics(c *gin.Context) {
+	if h.Config.MetricsToken != "" {
+		token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(h.Config.MetricsToken)) != 1 {
+			c.Error(apierror.New(http.StatusUnauthorized, "Invalid metrics token"))
+			return
+		}
+	}
+
+	c.Header("Content-Type", metrics.ContentType)
+	c.Status(http.StatusOK)
+	if err := metrics.Default.Write(c.Writer); err != nil {
+		c.Error(err)
+	}
+}
@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/apierror"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"github.com/jasen-devvv/mini-blog-backend/notifications"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationPreferencesInput defines the structure for notification preference updates.
+// It maps notification types to whether they are enabled; omitted types are left unchanged.
+type NotificationPreferencesInput struct {
+	Preferences map[string]bool `json:"preferences" binding:"required,dive,keys,oneof=comment reply follow reaction,endkeys"`
+}
+
+// GetNotifications retrieves the authenticated user's notifications, most recent activity first.
+// Requires authentication, as it uses the user_id from the context (set by auth middleware).
+// Supports the "page" and "limit" query parameters, and "unread=true" to only list unread notifications.
+// Returns a JSON response with the notifications, the unread count and pagination metadata.
+func (h *Handler) GetNotifications(c *gin.Context) {
+	// Get user_id from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	page, limit := getPagination(c)
+
+	query := h.db(c).Model(&models.Notification{}).Where("user_id = ?", userID)
+	if c.Query("unread") == "true" {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to get notifications"))
+		return
+	}
+
+	var notificationList []models.Notification
+	if err := query.Preload("Actor").Order("updated_at desc").Offset((page - 1) * limit).Limit(limit).Find(&notificationList).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to get notifications"))
+		return
+	}
+
+	unread, err := h.countUnreadNotifications(c, userID.(uint))
+	if err != nil {
+		c.Error(apierror.Internal(err, "Failed to get notifications"))
+		return
+	}
+
+	// Remove private fields from user data and build messages
+	for i := range notificationList {
+		notificationList[i].Actor.HidePrivate()
+		notificationList[i].Message = notifications.Message(notificationList[i])
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":         notificationList,
+		"unread_count": unread,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// MarkNotificationRead marks a single notification of the authenticated user as read.
+// Requires authentication, as it uses the user_id from the context (set by auth middleware).
+// Returns a JSON response with the remaining unread count or an error message.
+func (h *Handler) MarkNotificationRead(c *gin.Context) {
+	// Get user_id from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var notification models.Notification
+	if err := h.db(c).Where("user_id = ?", userID).First(&notification, c.Param("id")).Error; err != nil {
+		c.Error(apierror.FromDB(err, "Notification not found"))
+		return
+	}
+
+	if notification.ReadAt == nil {
+		if err := h.db(c).Model(&notification).UpdateColumn("read_at", time.Now()).Error; err != nil {
+			c.Error(apierror.Internal(err, "Failed to update notification"))
+			return
+		}
+	}
+
+	unread, err := h.countUnreadNotifications(c, userID.(uint))
+	if err != nil {
+		c.Error(apierror.Internal(err, "Failed to count notifications"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"unread_count": unread}})
+}
+
+// MarkAllNotificationsRead marks every notification of the authenticated user as read.
+// Requires authentication, as it uses the user_id from the context (set by auth middleware).
+// Returns a JSON response with the unread count (always zero) or an error message.
+func (h *Handler) MarkAllNotificationsRead(c *gin.Context) {
+	// Get user_id from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	if err := h.db(c).Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		UpdateColumn("read_at", time.Now()).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to update notifications"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"unread_count": 0}})
+}
+
+// GetNotificationPreferences retrieves the authenticated user's notification preferences.
+// Requires authentication, as it uses the user_id from the context (set by auth middleware).
+// Every notification type is listed; types without a stored preference are enabled.
+// Returns a JSON response mapping types to whether they are enabled.
+func (h *Handler) GetNotificationPreferences(c *gin.Context) {
+	// Get user_id from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	preferences, err := h.loadNotificationPreferences(c, userID.(uint))
+	if err != nil {
+		c.Error(apierror.Internal(err, "Failed to get notification preferences"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": preferences})
+}
+
+// UpdateNotificationPreferences enables or disables notification types for the authenticated user.
+// Requires authentication, as it uses the user_id from the context (set by auth middleware).
+// Returns a JSON response with the full set of preferences or an error message.
+func (h *Handler) UpdateNotificationPreferences(c *gin.Context) {
+	// Get user_id from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var input NotificationPreferencesInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apierror.Validation(err))
+		return
+	}
+
+	err := h.db(c).Transaction(func(tx *gorm.DB) error {
+		for notificationType, enabled := range input.Preferences {
+			preference := models.NotificationPreference{UserID: userID.(uint), Type: notificationType, Enabled: enabled}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}},
+				DoUpdates: clause.AssignmentColumns([]string{"enabled"}),
+			}).Create(&preference).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.Error(apierror.Internal(err, "Failed to update notification preferences"))
+		return
+	}
+
+	preferences, err := h.loadNotificationPreferences(c, userID.(uint))
+	if err != nil {
+		c.Error(apierror.Internal(err, "Failed to get notification preferences"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": preferences})
+}
+
+// countUnreadNotifications returns how many unread notifications the user has.
+func (h *Handler) countUnreadNotifications(c *gin.Context, userID uint) (int64, error) {
+	var count int64
+	err := h.db(c).Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+// loadNotificationPreferences returns whether each notification type is enabled for the user.
+func (h *Handler) loadNotificationPreferences(c *gin.Context, userID uint) (map[string]bool, error) {
+	preferences := make(map[string]bool, len(models.NotificationTypes))
+	for _, notificationType := range models.NotificationTypes {
+		preferences[notificationType] = true
+	}
+
+	var stored []models.NotificationPreference
+	if err := h.db(c).Where("user_id = ?", userID).Find(&stored).Error; err != nil {
+		return nil, err
+	}
+	for _, preference := range stored {
+		preferences[preference.Type] = preference.Enabled
+	}
+
+	return preferences, nil
+}
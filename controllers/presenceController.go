@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/apierror"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"github.com/jasen-devvv/mini-blog-backend/presence"
+	"golang.org/x/net/websocket"
+)
+
+// liveMessage is a message exchanged over the live article WebSocket.
+//
+// Clients send:
+//   - {"type": "edit_start"} to acquire the edit lock
+//   - {"type": "heartbeat"}  to keep the connection and the edit lock alive
+//   - {"type": "edit_stop"}  to release the edit lock
+//
+// The server replies with "welcome" (carrying the session token to send as the
+// X-Edit-Lock header when saving), "presence" state updates, "lock_denied" and "error".
+type liveMessage struct {
+	Type    string `json:"type"`
+	Session string `json:"session,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ArticleLive upgrades the request to a WebSocket reporting who is viewing or editing an article.
+// Requires authentication; browsers may pass the token as the "access_token" query parameter.
+// Drafts can only be joined by the users who may edit them, and only those users (the
+// author and the collaborators) may acquire the edit lock.
+// Connections that send nothing for presence.LockTTL are closed and their lock is released.
+func (h *Handler) ArticleLive(c *gin.Context) {
+	// Get user_id from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	id, ok := paramID(c, "id")
+	if !ok {
+		c.Error(apierror.New(http.StatusNotFound, "Article not found"))
+		return
+	}
+	article, err := h.Articles.Get(c, id, userID.(uint))
+	if err != nil {
+		c.Error(apierror.FromDB(err, "Article not found"))
+		return
+	}
+	canEdit, err := h.Articles.CanEdit(c, article, userID.(uint))
+	if err != nil {
+		c.Error(apierror.Internal(err, "Failed to check permissions"))
+		return
+	}
+
+	user, err := h.Users.Get(c, userID.(uint))
+	if err != nil {
+		c.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	// Clients authenticate with a token rather than cookies, so any origin is accepted
+	server := websocket.Server{
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			serveLive(c.Request.Context(), ws, *article, *user, canEdit)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// serveLive runs a live article connection until the client disconnects or goes silent.
+// Only clients that canEdit the article may take its edit lock.
+func serveLive(ctx context.Context, ws *websocket.Conn, article models.Article, user models.User, canEdit bool) {
+	defer ws.Close()
+
+	client := &presence.Client{
+		Token: presence.NewToken(),
+		User:  user,
+		Send:  make(chan interface{}, 16),
+	}
+
+	// Write messages from a single goroutine
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case msg := <-client.Send:
+				ws.SetWriteDeadline(time.Now().Add(10 * time.Second))
+				if err := websocket.JSON.Send(ws, msg); err != nil {
+					ws.Close()
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	sendLive(client, liveMessage{Type: "welcome", Session: client.Token})
+	presence.DefaultHub.Join(ctx, article.ID, client)
+	defer presence.DefaultHub.Leave(ctx, article.ID, client)
+
+	for {
+		ws.SetReadDeadline(time.Now().Add(presence.LockTTL))
+
+		var msg liveMessage
+		if err := websocket.JSON.Receive(ws, &msg); err != nil {
+			return
+		}
+
+		switch msg.Type {
+		case "edit_start":
+			if !canEdit {
+				sendLive(client, liveMessage{Type: "lock_denied", Error: "You are not authorized to edit this article"})
+				continue
+			}
+			acquired, err := presence.AcquireLock(ctx, article.ID, user.ID, client.Token)
+			if err != nil || !acquired {
+				sendLive(client, liveMessage{Type: "lock_denied", Error: "Article is being edited in another session"})
+				presence.DefaultHub.Broadcast(ctx, article.ID)
+				continue
+			}
+			presence.DefaultHub.SetEditing(ctx, article.ID, client, true)
+
+		case "heartbeat":
+			if client.Editing {
+				if held, err := presence.RefreshLock(ctx, client.Token); err == nil && !held {
+					presence.DefaultHub.SetEditing(ctx, article.ID, client, false)
+				}
+			}
+
+		case "edit_stop":
+			presence.ReleaseLock(ctx, client.Token)
+			presence.DefaultHub.SetEditing(ctx, article.ID, client, false)
+
+		default:
+			sendLive(client, liveMessage{Type: "error", Error: "Unknown message type"})
+		}
+	}
+}
+
+// sendLive queues a message for the client without blocking when its buffer is full.
+func sendLive(client *presence.Client, msg liveMessage) {
+	select {
+	case client.Send <- msg:
+	default:
+	}
+}
+
+// checkEditLock applies the advisory edit lock before an article is saved.
+//
+// Saves carrying the lock's session token in the X-Edit-Lock header, or made while the
+// article is unlocked, go through silently. While another user holds the lock, saves are
+// refused with 423 Locked, naming the holder in the "lock" member of the response. Saves
+// from another session of the holder (a second browser tab, say) go through with a
+// Warning header so the client can tell them.
+// It returns false after writing an error response.
+func checkEditLock(c *gin.Context, article models.Article) bool {
+	lock, err := presence.CurrentLock(c.Request.Context(), article.ID)
+	if err != nil {
+		c.Error(apierror.Internal(err, "Failed to check edit lock"))
+		return false
+	}
+
+	if lock == nil || lock.Token == c.GetHeader("X-Edit-Lock") {
+		return true
+	}
+
+	if lock.UserID != viewerID(c) {
+		c.Error(apierror.New(http.StatusLocked, "Article is being edited by another user").With("lock", lock))
+		return false
+	}
+
+	c.Header("Warning", `199 - "Article is being edited in another session"`)
+	return true
+}
@@ -0,0 +1,240 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/apierror"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"github.com/jasen-devvv/mini-blog-backend/notifications"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AddArticleReaction adds the authenticated user's reaction of the given kind to an article.
+// Requires authentication, as it uses the user_id from the context (set by auth middleware).
+// Returns a JSON response with the article's updated reaction counts or an error message.
+func (h *Handler) AddArticleReaction(c *gin.Context) {
+	h.setReaction(c, models.ReactionTargetArticle, true)
+}
+
+// RemoveArticleReaction removes the authenticated user's reaction of the given kind from an article.
+// Requires authentication, as it uses the user_id from the context (set by auth middleware).
+// Returns a JSON response with the article's updated reaction counts or an error message.
+func (h *Handler) RemoveArticleReaction(c *gin.Context) {
+	h.setReaction(c, models.ReactionTargetArticle, false)
+}
+
+// AddCommentReaction adds the authenticated user's reaction of the given kind to a comment.
+// Requires authentication, as it uses the user_id from the context (set by auth middleware).
+// Returns a JSON response with the comment's updated reaction counts or an error message.
+func (h *Handler) AddCommentReaction(c *gin.Context) {
+	h.setReaction(c, models.ReactionTargetComment, true)
+}
+
+// RemoveCommentReaction removes the authenticated user's reaction of the given kind from a comment.
+// Requires authentication, as it uses the user_id from the context (set by auth middleware).
+// Returns a JSON response with the comment's updated reaction counts or an error message.
+func (h *Handler) RemoveCommentReaction(c *gin.Context) {
+	h.setReaction(c, models.ReactionTargetComment, false)
+}
+
+// setReaction adds or removes a reaction and keeps the aggregate count in sync.
+//
+// The reaction row and its count are changed in one transaction. The count is only
+// touched when the reaction row was actually inserted or deleted, and it is adjusted
+// with an atomic "count = count ± 1" so concurrent writers never lose updates.
+func (h *Handler) setReaction(c *gin.Context, targetType string, add bool) {
+	// Get user_id from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	kind := c.Param("kind")
+	if _, ok := models.ReactionEmojis[kind]; !ok {
+		c.Error(apierror.New(http.StatusBadRequest, "Unknown reaction kind"))
+		return
+	}
+
+	// Verify the target exists
+	target, ok := h.findReactionTarget(c, targetType)
+	if !ok {
+		return
+	}
+	targetID := target.ID
+
+	inserted := false
+	err := h.db(c).Transaction(func(tx *gorm.DB) error {
+		if add {
+			reaction := models.Reaction{UserID: userID.(uint), TargetType: targetType, TargetID: targetID, Kind: kind}
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&reaction)
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			inserted = true
+
+			return tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "target_type"}, {Name: "target_id"}, {Name: "kind"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{"count": gorm.Expr("reaction_counts.count + 1")}),
+			}).Create(&models.ReactionCount{TargetType: targetType, TargetID: targetID, Kind: kind, Count: 1}).Error
+		}
+
+		result := tx.Where("user_id = ? AND target_type = ? AND target_id = ? AND kind = ?", userID, targetType, targetID, kind).
+			Delete(&models.Reaction{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		return tx.Model(&models.ReactionCount{}).
+			Where("target_type = ? AND target_id = ? AND kind = ? AND count > 0", targetType, targetID, kind).
+			Update("count", gorm.Expr("count - 1")).Error
+	})
+	if err != nil {
+		c.Error(apierror.Internal(err, "Failed to update reaction"))
+		return
+	}
+
+	// Notify the author of the article or comment about new reactions
+	if inserted {
+		event := notifications.Event{
+			Type:        models.NotificationTypeReaction,
+			RecipientID: target.OwnerID,
+			ActorID:     userID.(uint),
+			ArticleID:   &target.ArticleID,
+			TargetType:  targetType,
+		}
+		if targetType == models.ReactionTargetComment {
+			event.CommentID = &target.ID
+		}
+		notifications.Notify(c.Request.Context(), event)
+	}
+
+	counts, mine, err := h.loadReactions(c, targetType, []uint{targetID}, userID)
+	if err != nil {
+		c.Error(apierror.Internal(err, "Failed to get reactions"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"reaction_counts": counts[targetID],
+		"my_reactions":    mine[targetID],
+	}})
+}
+
+// reactionTarget identifies the article or comment a reaction applies to.
+type reactionTarget struct {
+	ID        uint // ID of the article or comment
+	OwnerID   uint // ID of the user who wrote it (0 for federated replies)
+	ArticleID uint // ID of the article (the comment's article for comments)
+}
+
+// findReactionTarget loads the article or comment from the "id" route parameter.
+// It writes a "not found" response and returns false when the target does not exist
+// or is an unpublished article.
+func (h *Handler) findReactionTarget(c *gin.Context, targetType string) (reactionTarget, bool) {
+	id := c.Param("id")
+
+	if targetType == models.ReactionTargetArticle {
+		var article models.Article
+		if err := h.db(c).Where("status = ?", models.ArticleStatusPublished).First(&article, id).Error; err != nil {
+			c.Error(apierror.FromDB(err, "Article not found"))
+			return reactionTarget{}, false
+		}
+		return reactionTarget{ID: article.ID, OwnerID: article.UserID, ArticleID: article.ID}, true
+	}
+
+	var comment models.Comment
+	if err := h.db(c).First(&comment, id).Error; err != nil {
+		c.Error(apierror.FromDB(err, "Comment not found"))
+		return reactionTarget{}, false
+	}
+
+	// Federated replies have no local owner to notify
+	target := reactionTarget{ID: comment.ID, ArticleID: comment.ArticleID}
+	if comment.UserID != nil {
+		target.OwnerID = *comment.UserID
+	}
+	return target, true
+}
+
+// loadReactions fetches reaction counts for many targets at once, plus the kinds the
+// given user reacted with. userID may be nil for anonymous callers.
+//
+// It runs at most two queries regardless of how many targets are requested, so list
+// endpoints can use it without N+1 queries.
+func (h *Handler) loadReactions(c *gin.Context, targetType string, targetIDs []uint, userID interface{}) (map[uint]map[string]int64, map[uint][]string, error) {
+	counts := make(map[uint]map[string]int64, len(targetIDs))
+	mine := make(map[uint][]string, len(targetIDs))
+	for _, id := range targetIDs {
+		counts[id] = map[string]int64{}
+		mine[id] = []string{}
+	}
+	if len(targetIDs) == 0 {
+		return counts, mine, nil
+	}
+
+	var rows []models.ReactionCount
+	if err := h.db(c).Where("target_type = ? AND target_id IN ? AND count > 0", targetType, targetIDs).Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+	for _, row := range rows {
+		counts[row.TargetID][row.Kind] = row.Count
+	}
+
+	if userID == nil {
+		return counts, mine, nil
+	}
+
+	var reactions []models.Reaction
+	if err := h.db(c).Where("user_id = ? AND target_type = ? AND target_id IN ?", userID, targetType, targetIDs).
+		Order("created_at asc").Find(&reactions).Error; err != nil {
+		return nil, nil, err
+	}
+	for _, reaction := range reactions {
+		mine[reaction.TargetID] = append(mine[reaction.TargetID], reaction.Kind)
+	}
+
+	return counts, mine, nil
+}
+
+// attachArticleReactions fills ReactionCounts and MyReactions on the given articles.
+func (h *Handler) attachArticleReactions(c *gin.Context, articles []models.Article) error {
+	ids := make([]uint, len(articles))
+	for i := range articles {
+		ids[i] = articles[i].ID
+	}
+
+	userID, _ := c.Get("user_id")
+	counts, mine, err := h.loadReactions(c, models.ReactionTargetArticle, ids, userID)
+	if err != nil {
+		return err
+	}
+
+	for i := range articles {
+		articles[i].ReactionCounts = counts[articles[i].ID]
+		articles[i].MyReactions = mine[articles[i].ID]
+	}
+	return nil
+}
+
+// attachCommentReactions fills ReactionCounts and MyReactions on the given comments.
+func (h *Handler) attachCommentReactions(c *gin.Context, comments []models.Comment) error {
+	ids := make([]uint, len(comments))
+	for i := range comments {
+		ids[i] = comments[i].ID
+	}
+
+	userID, _ := c.Get("user_id")
+	counts, mine, err := h.loadReactions(c, models.ReactionTargetComment, ids, userID)
+	if err != nil {
+		return err
+	}
+
+	for i := range comments {
+		comments[i].ReactionCounts = counts[comments[i].ID]
+		comments[i].MyReactions = mine[comments[i].ID]
+	}
+	return nil
+}
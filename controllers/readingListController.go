@@ -0,0 +1,356 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/apierror"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"gorm.io/gorm"
+)
+
+// ReadingListInput defines the structure for reading list creation and update requests
+type ReadingListInput struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=1000"`
+	IsPublic    bool   `json:"is_public"`
+}
+
+// ReadingListItemInput defines the structure for adding an article to a reading list
+type ReadingListItemInput struct {
+	ArticleID uint   `json:"article_id" binding:"required"`
+	Note      string `json:"note" binding:"max=1000"`
+}
+
+// ReadingListItemUpdateInput defines the structure for updating the note of a list item
+type ReadingListItemUpdateInput struct {
+	Note string `json:"note" binding:"max=1000"`
+}
+
+// ReadingListOrderInput defines the structure for reordering a reading list.
+// ArticleIDs lists the articles in their new order.
+type ReadingListOrderInput struct {
+	ArticleIDs []uint `json:"article_ids" binding:"required"`
+}
+
+// GetReadingLists retrieves the authenticated user's reading lists, without their items.
+// Requires authentication, as it uses the user_id from the context (set by auth middleware).
+// Returns a JSON response with the lists or an error message.
+func (h *Handler) GetReadingLists(c *gin.Context) {
+	// Get user_id from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var lists []models.ReadingList
+	if err := h.db(c).Where("user_id = ?", userID).Preload("User").Order("created_at desc").Find(&lists).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to get reading lists"))
+		return
+	}
+
+	// Remove private fields from user data for security
+	for i := range lists {
+		lists[i].User.HidePrivate()
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": lists})
+}
+
+// GetReadingList retrieves a reading list with its items in order.
+// Public lists can be viewed by anyone with the URL; private lists only by their owner
+// (user_id is set by the optional auth middleware).
+// Items whose article was unpublished are left out; deleted articles are removed by the database.
+// Returns a JSON response with the list or a "not found" error.
+func (h *Handler) GetReadingList(c *gin.Context) {
+	var list models.ReadingList
+	if err := h.db(c).Preload("User").First(&list, c.Param("id")).Error; err != nil {
+		c.Error(apierror.FromDB(err, "Reading list not found"))
+		return
+	}
+
+	// Hide private lists from everyone but their owner
+	if !list.IsPublic {
+		userID, exists := c.Get("user_id")
+		if !exists || userID.(uint) != list.UserID {
+			c.Error(apierror.New(http.StatusNotFound, "Reading list not found"))
+			return
+		}
+	}
+
+	// Only keep items whose article is still published
+	if err := h.db(c).
+		Joins("JOIN articles ON articles.id = reading_list_items.article_id AND articles.status = ?", models.ArticleStatusPublished).
+		Where("reading_list_items.reading_list_id = ?", list.ID).
+		Preload("Article.User").
+		Order("reading_list_items.position asc, reading_list_items.id asc").
+		Find(&list.Items).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to get reading list items"))
+		return
+	}
+
+	// Remove private fields from user data for security
+	list.User.HidePrivate()
+	for i := range list.Items {
+		list.Items[i].Article.User.HidePrivate()
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// CreateReadingList creates a new reading list for the authenticated user.
+// Requires authentication, as it uses the user_id from the context (set by auth middleware).
+// Returns a JSON response with the created list or an error message.
+func (h *Handler) CreateReadingList(c *gin.Context) {
+	var input ReadingListInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apierror.Validation(err))
+		return
+	}
+
+	// Get user_id from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	list := models.ReadingList{
+		UserID:      userID.(uint),
+		Name:        input.Name,
+		Description: input.Description,
+		IsPublic:    input.IsPublic,
+	}
+
+	if err := h.db(c).Create(&list).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to create reading list"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": list})
+}
+
+// UpdateReadingList updates the name, description and visibility of a reading list.
+// Requires authentication and verifies that the user is the owner of the list.
+// Returns a JSON response with the updated list or an appropriate error message.
+func (h *Handler) UpdateReadingList(c *gin.Context) {
+	list, ok := h.findOwnReadingList(c)
+	if !ok {
+		return
+	}
+
+	var input ReadingListInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apierror.Validation(err))
+		return
+	}
+
+	// Update with a map so that is_public can be set to false
+	if err := h.db(c).Model(&list).Updates(map[string]interface{}{
+		"name":        input.Name,
+		"description": input.Description,
+		"is_public":   input.IsPublic,
+	}).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to update reading list"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// DeleteReadingList removes a reading list and all of its items.
+// Requires authentication and verifies that the user is the owner of the list.
+// Returns a success message or an appropriate error message.
+func (h *Handler) DeleteReadingList(c *gin.Context) {
+	list, ok := h.findOwnReadingList(c)
+	if !ok {
+		return
+	}
+
+	if err := h.db(c).Delete(&list).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to delete reading list"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": "Reading list deleted successfully"})
+}
+
+// AddReadingListItem adds a published article to the end of a reading list.
+// Requires authentication and verifies that the user is the owner of the list.
+// Returns a JSON response with the created item or an appropriate error message.
+func (h *Handler) AddReadingListItem(c *gin.Context) {
+	list, ok := h.findOwnReadingList(c)
+	if !ok {
+		return
+	}
+
+	var input ReadingListItemInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apierror.Validation(err))
+		return
+	}
+
+	// Verify the article exists and is published
+	var article models.Article
+	if err := h.db(c).Where("status = ?", models.ArticleStatusPublished).First(&article, input.ArticleID).Error; err != nil {
+		c.Error(apierror.FromDB(err, "Article not found"))
+		return
+	}
+
+	// Reject duplicates so each article appears once per list
+	var count int64
+	if err := h.db(c).Model(&models.ReadingListItem{}).Where("reading_list_id = ? AND article_id = ?", list.ID, article.ID).Count(&count).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to add article to reading list"))
+		return
+	}
+	if count > 0 {
+		c.Error(apierror.New(http.StatusConflict, "Article is already in this reading list"))
+		return
+	}
+
+	// Append after the current last item
+	var maxPosition int
+	if err := h.db(c).Model(&models.ReadingListItem{}).Where("reading_list_id = ?", list.ID).Select("COALESCE(MAX(position), 0)").Scan(&maxPosition).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to add article to reading list"))
+		return
+	}
+
+	item := models.ReadingListItem{
+		ReadingListID: list.ID,
+		ArticleID:     article.ID,
+		Position:      maxPosition + 1,
+		Note:          input.Note,
+	}
+
+	if err := h.db(c).Create(&item).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to add article to reading list"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": item})
+}
+
+// UpdateReadingListItem updates the note of an article in a reading list.
+// Requires authentication and verifies that the user is the owner of the list.
+// Returns a JSON response with the updated item or an appropriate error message.
+func (h *Handler) UpdateReadingListItem(c *gin.Context) {
+	list, ok := h.findOwnReadingList(c)
+	if !ok {
+		return
+	}
+
+	var input ReadingListItemUpdateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apierror.Validation(err))
+		return
+	}
+
+	var item models.ReadingListItem
+	if err := h.db(c).Where("reading_list_id = ? AND article_id = ?", list.ID, c.Param("article_id")).First(&item).Error; err != nil {
+		c.Error(apierror.FromDB(err, "Article is not in this reading list"))
+		return
+	}
+
+	if err := h.db(c).Model(&item).Update("note", input.Note).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to update reading list item"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": item})
+}
+
+// RemoveReadingListItem removes an article from a reading list.
+// Requires authentication and verifies that the user is the owner of the list.
+// Returns a success message or an appropriate error message.
+func (h *Handler) RemoveReadingListItem(c *gin.Context) {
+	list, ok := h.findOwnReadingList(c)
+	if !ok {
+		return
+	}
+
+	if err := h.db(c).Where("reading_list_id = ? AND article_id = ?", list.ID, c.Param("article_id")).Delete(&models.ReadingListItem{}).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to remove article from reading list"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": "Article removed from reading list successfully"})
+}
+
+// ReorderReadingList sets the order of the items in a reading list.
+// Requires authentication and verifies that the user is the owner of the list.
+// Articles are positioned in the order given; items not mentioned keep their relative
+// order after the listed ones.
+// Returns a success message or an appropriate error message.
+func (h *Handler) ReorderReadingList(c *gin.Context) {
+	list, ok := h.findOwnReadingList(c)
+	if !ok {
+		return
+	}
+
+	var input ReadingListOrderInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apierror.Validation(err))
+		return
+	}
+
+	err := h.db(c).Transaction(func(tx *gorm.DB) error {
+		var items []models.ReadingListItem
+		if err := tx.Where("reading_list_id = ?", list.ID).Order("position asc, id asc").Find(&items).Error; err != nil {
+			return err
+		}
+
+		// Listed articles come first, in the requested order
+		positions := make(map[uint]int, len(input.ArticleIDs))
+		for i, articleID := range input.ArticleIDs {
+			if _, seen := positions[articleID]; !seen {
+				positions[articleID] = i + 1
+			}
+		}
+
+		next := len(input.ArticleIDs) + 1
+		for _, item := range items {
+			position, ok := positions[item.ArticleID]
+			if !ok {
+				position = next
+				next++
+			}
+			if err := tx.Model(&item).Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.Error(apierror.Internal(err, "Failed to reorder reading list"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": "Reading list reordered successfully"})
+}
+
+// findOwnReadingList loads the reading list from the "id" route parameter and checks that
+// it belongs to the authenticated user. It writes an error response and returns false otherwise.
+func (h *Handler) findOwnReadingList(c *gin.Context) (models.ReadingList, bool) {
+	var list models.ReadingList
+
+	// Get user_id from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
+		return list, false
+	}
+
+	if err := h.db(c).First(&list, c.Param("id")).Error; err != nil {
+		c.Error(apierror.FromDB(err, "Reading list not found"))
+		return list, false
+	}
+
+	// Check if user is the owner of the list
+	if list.UserID != userID.(uint) {
+		c.Error(apierror.New(http.StatusForbidden, "You are not authorized to modify this reading list"))
+		return list, false
+	}
+
+	return list, true
+}
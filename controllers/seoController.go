@@ -0,0 +1,222 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/apierror"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"github.com/jasen-devvv/mini-blog-backend/sitemap"
+	"gorm.io/gorm"
+)
+
+// Sitemap serves /sitemap.xml.
+//
+// Small blogs get a single sitemap listing the home page and every indexable article.
+// Once there are more articles than fit in one file, it serves a sitemap index pointing
+// to /sitemaps/articles-{n}.xml pages instead. Each lastmod comes from the article's UpdatedAt.
+func (h *Handler) Sitemap(c *gin.Context) {
+	base := h.Config.SiteURL
+
+	var total int64
+	if err := h.indexableArticles(c).Count(&total).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to build sitemap"))
+		return
+	}
+
+	// Everything fits in a single sitemap
+	if total <= sitemap.MaxURLs {
+		urls, err := h.sitemapArticleURLs(c, base, 0)
+		if err != nil {
+			c.Error(apierror.Internal(err, "Failed to build sitemap"))
+			return
+		}
+		body, err := sitemap.URLSet(append([]sitemap.URL{{Loc: base + "/"}}, urls...))
+		renderSitemap(c, body, err)
+		return
+	}
+
+	// Split into pages and list them in an index
+	pages := int((total + sitemap.MaxURLs - 1) / sitemap.MaxURLs)
+	entries := make([]sitemap.Entry, 0, pages)
+	for page := 1; page <= pages; page++ {
+		var lastMod time.Time
+		pageArticles := h.indexableArticles(c).Select("updated_at").Order("articles.id asc").
+			Offset((page - 1) * sitemap.MaxURLs).Limit(sitemap.MaxURLs)
+		if err := h.db(c).Table("(?) AS page", pageArticles).Select("MAX(updated_at)").Scan(&lastMod).Error; err != nil {
+			c.Error(apierror.Internal(err, "Failed to build sitemap"))
+			return
+		}
+
+		entries = append(entries, sitemap.Entry{
+			Loc:     fmt.Sprintf("%s/sitemaps/articles-%d.xml", h.Config.APIURL, page),
+			LastMod: lastMod,
+		})
+	}
+	body, err := sitemap.Index(entries)
+	renderSitemap(c, body, err)
+}
+
+// SitemapPage serves one page of the split sitemap, named "articles-{n}.xml".
+func (h *Handler) SitemapPage(c *gin.Context) {
+	name := c.Param("file")
+	if !strings.HasPrefix(name, "articles-") || !strings.HasSuffix(name, ".xml") {
+		c.Error(apierror.New(http.StatusNotFound, "Sitemap not found"))
+		return
+	}
+
+	page, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, "articles-"), ".xml"))
+	if err != nil || page < 1 {
+		c.Error(apierror.New(http.StatusNotFound, "Sitemap not found"))
+		return
+	}
+
+	urls, err := h.sitemapArticleURLs(c, h.Config.SiteURL, page)
+	if err != nil {
+		c.Error(apierror.Internal(err, "Failed to build sitemap"))
+		return
+	}
+	if len(urls) == 0 {
+		c.Error(apierror.New(http.StatusNotFound, "Sitemap not found"))
+		return
+	}
+
+	body, err := sitemap.URLSet(urls)
+	renderSitemap(c, body, err)
+}
+
+// Robots serves /robots.txt, keeping crawlers out of the JSON API and pointing them to the sitemap.
+func (h *Handler) Robots(c *gin.Context) {
+	body := strings.Join([]string{
+		"User-agent: *",
+		"Disallow: /api/",
+		"",
+		"Sitemap: " + h.Config.APIURL + "/sitemap.xml",
+		"",
+	}, "\n")
+
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(body))
+}
+
+// GetArticleMeta returns the SEO, Open Graph and Twitter card metadata of an article,
+// for server-side rendering of the article page, along with the Webmention endpoint
+// the page should advertise.
+// Drafts are only visible to their author and collaborators (user_id is set by the optional
+// auth middleware).
+// Returns a JSON response with the metadata or a "not found" error.
+func (h *Handler) GetArticleMeta(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		c.Error(apierror.New(http.StatusNotFound, "Article not found"))
+		return
+	}
+
+	article, err := h.Articles.Get(c, id, viewerID(c))
+	if err != nil {
+		c.Error(apierror.FromDB(err, "Article not found"))
+		return
+	}
+
+	base := h.Config.SiteURL
+
+	description := article.MetaDescription
+	if description == "" {
+		description = articleSummary(*article)
+	}
+
+	canonical := article.CanonicalURL
+	if canonical == "" {
+		canonical = articleURL(base, *article)
+	}
+
+	robots := "index, follow"
+	if article.NoIndex || article.Status != models.ArticleStatusPublished {
+		robots = "noindex, nofollow"
+	}
+
+	tags := make([]string, len(article.Tags))
+	for i, tag := range article.Tags {
+		tags[i] = tag.Name
+	}
+
+	twitterCard := "summary"
+	if article.CoverURL != "" {
+		twitterCard = "summary_large_image"
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"title":         article.Title,
+		"description":   description,
+		"canonical_url": canonical,
+		"robots":        robots,
+		"webmention":    h.Config.APIURL + "/webmention",
+		"open_graph": gin.H{
+			"og:type":                "article",
+			"og:title":               article.Title,
+			"og:description":         description,
+			"og:url":                 canonical,
+			"og:image":               article.CoverURL,
+			"og:site_name":           h.siteName(),
+			"article:published_time": article.CreatedAt.UTC().Format(time.RFC3339),
+			"article:modified_time":  article.UpdatedAt.UTC().Format(time.RFC3339),
+			"article:author":         authorURL(base, article.User),
+			"article:tag":            tags,
+		},
+		"twitter": gin.H{
+			"twitter:card":        twitterCard,
+			"twitter:title":       article.Title,
+			"twitter:description": description,
+			"twitter:image":       article.CoverURL,
+		},
+	}})
+}
+
+// indexableArticles returns a query for the published articles search engines may index.
+func (h *Handler) indexableArticles(c *gin.Context) *gorm.DB {
+	return h.db(c).Model(&models.Article{}).Where("articles.status = ? AND articles.noindex = ?", models.ArticleStatusPublished, false)
+}
+
+// sitemapArticleURLs lists the indexable articles of a sitemap page (all of them when page is 0).
+// Articles whose canonical URL points to another site are left out, because only the
+// canonical copy should be indexed.
+func (h *Handler) sitemapArticleURLs(c *gin.Context, base string, page int) ([]sitemap.URL, error) {
+	query := h.indexableArticles(c).Order("articles.id asc")
+	if page > 0 {
+		query = query.Offset((page - 1) * sitemap.MaxURLs).Limit(sitemap.MaxURLs)
+	}
+
+	var articles []models.Article
+	if err := query.Select("id", "canonical_url", "updated_at").Find(&articles).Error; err != nil {
+		return nil, err
+	}
+
+	urls := make([]sitemap.URL, 0, len(articles))
+	for _, article := range articles {
+		loc := articleURL(base, article)
+		if article.CanonicalURL != "" {
+			if hostOf(article.CanonicalURL) != hostOf(base) {
+				continue
+			}
+			loc = article.CanonicalURL
+		}
+		urls = append(urls, sitemap.URL{Loc: loc, LastMod: article.UpdatedAt})
+	}
+
+	return urls, nil
+}
+
+// renderSitemap writes a rendered sitemap document.
+func renderSitemap(c *gin.Context, body []byte, err error) {
+	if err != nil {
+		c.Error(apierror.Internal(err, "Failed to build sitemap"))
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", body)
+}
@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/jasen-devvv/mini-blog-backend/models"
+)
+
+// siteName returns the name of the blog, read from Config.SiteName.
+func (h *Handler) siteName() string {
+	if h.Config.SiteName != "" {
+		return h.Config.SiteName
+	}
+	return "Mini Blog"
+}
+
+// articleURL returns the public page URL of an article.
+func articleURL(base string, article models.Article) string {
+	return fmt.Sprintf("%s/articles/%d", base, article.ID)
+}
+
+// authorURL returns the public page URL of an author.
+func authorURL(base string, user models.User) string {
+	return fmt.Sprintf("%s/authors/%s", base, user.Username)
+}
+
+// displayName returns the user's display name, or the username when it is not set.
+func displayName(user models.User) string {
+	if user.DisplayName != "" {
+		return user.DisplayName
+	}
+	return user.Username
+}
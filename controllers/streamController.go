@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/apierror"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"github.com/jasen-devvv/mini-blog-backend/notifications"
+	"github.com/jasen-devvv/mini-blog-backend/pubsub"
+)
+
+// streamKeepAlive is how often a comment line is sent to keep idle streams open through proxies.
+const streamKeepAlive = 25 * time.Second
+
+var (
+	// streamsClosed is closed by CloseStreams to end every open stream
+	streamsClosed    = make(chan struct{})
+	closeStreamsOnce sync.Once
+)
+
+// CloseStreams ends every open event stream, so a shutting down server is not kept
+// waiting by clients that never disconnect. Clients reconnect with Last-Event-ID and
+// resume on another instance.
+func CloseStreams() {
+	closeStreamsOnce.Do(func() { close(streamsClosed) })
+}
+
+// StreamComments streams new comments on a published article as Server-Sent Events.
+// Each event is named "comment" and carries the comment as JSON; newly verified
+// webmentions are sent as "webmention" events.
+// Clients reconnecting with a Last-Event-ID header receive the comments they missed first.
+func (h *Handler) StreamComments(c *gin.Context) {
+	var article models.Article
+	if err := h.db(c).Where("status = ?", models.ArticleStatusPublished).First(&article, c.Param("id")).Error; err != nil {
+		c.Error(apierror.FromDB(err, "Article not found"))
+		return
+	}
+
+	streamTopic(c, commentsTopic(article.ID))
+}
+
+// StreamNotifications streams the authenticated user's notifications as Server-Sent Events.
+// Requires authentication, as it uses the user_id from the context (set by auth middleware).
+// Each event is named "notification" and carries the new or updated notification as JSON.
+// Clients reconnecting with a Last-Event-ID header receive the notifications they missed first.
+func (h *Handler) StreamNotifications(c *gin.Context) {
+	// Get user_id from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	streamTopic(c, notifications.Topic(userID.(uint)))
+}
+
+// streamTopic writes the messages of a pub/sub topic to the client until it disconnects
+// or the streams are closed.
+//
+// The subscription is opened before missed messages are replayed, so no message
+// published in between is lost; messages already sent are skipped by ID.
+func streamTopic(c *gin.Context, topic string) {
+	messages, cancel := pubsub.Subscribe(topic)
+	defer cancel()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// Replay what the client missed since its last event
+	lastID := c.GetHeader("Last-Event-ID")
+	if lastID != "" {
+		for _, msg := range pubsub.Since(topic, lastID) {
+			writeEvent(c, msg)
+			lastID = msg.ID
+		}
+	}
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-streamsClosed:
+			return false
+		case msg, ok := <-messages:
+			if !ok {
+				return false
+			}
+			if pubsub.After(msg.ID, lastID) {
+				writeEvent(c, msg)
+				lastID = msg.ID
+			}
+		case <-keepAlive.C:
+			io.WriteString(w, ": keep-alive\n\n")
+		}
+		return true
+	})
+}
+
+// writeEvent writes a pub/sub message as a Server-Sent Event.
+func writeEvent(c *gin.Context, msg pubsub.Message) {
+	c.Render(-1, sse.Event{
+		Id:    msg.ID,
+		Event: msg.Event,
+		Data:  []byte(msg.Data),
+	})
+}
@@ -0,0 +1,244 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/apierror"
+	"github.com/jasen-devvv/mini-blog-backend/feeds"
+	"github.com/jasen-devvv/mini-blog-backend/logging"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"github.com/jasen-devvv/mini-blog-backend/sanitize"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// feedSize is the number of articles included in a feed
+	feedSize = 20
+
+	// summaryLength is the maximum length of generated summaries, in characters
+	summaryLength = 280
+)
+
+// RSSFeed serves the newest published articles as an RSS 2.0 feed.
+// The feed covers the whole blog, or a single author or tag when the
+// "username" or "tag" route parameter is set.
+func (h *Handler) RSSFeed(c *gin.Context) {
+	h.serveFeed(c, "rss")
+}
+
+// AtomFeed serves the newest published articles as an Atom 1.0 feed.
+// The feed covers the whole blog, or a single author or tag when the
+// "username" or "tag" route parameter is set.
+func (h *Handler) AtomFeed(c *gin.Context) {
+	h.serveFeed(c, "atom")
+}
+
+// JSONFeed serves the newest published articles as a JSON Feed 1.1 document.
+// The feed covers the whole blog, or a single author or tag when the
+// "username" or "tag" route parameter is set.
+func (h *Handler) JSONFeed(c *gin.Context) {
+	h.serveFeed(c, "json")
+}
+
+// serveFeed builds the feed for the requested scope and renders it in the given format.
+//
+// Feed readers can revalidate their copy either way: the ETag covers the IDs and versions
+// of the listed entries (see feedETag), and Last-Modified is the last time an article in
+// the scope was written, unpublished or deleted (see feedLastModified), so entries leaving
+// the feed advance it too. A matching If-None-Match, or an If-Modified-Since at or after
+// Last-Modified when If-None-Match is absent, yields 304 Not Modified.
+func (h *Handler) serveFeed(c *gin.Context, format string) {
+	base := h.Config.SiteURL
+
+	feed := feeds.Feed{
+		Title:       h.siteName(),
+		Description: "Latest articles",
+		Link:        base,
+		FeedURL:     base + c.Request.URL.Path,
+		ID:          fmt.Sprintf("tag:%s,2024:feed%s", hostOf(base), c.Request.URL.Path),
+	}
+
+	query := h.db(c).Preload("User").Preload("Tags").Where("articles.status = ?", models.ArticleStatusPublished)
+
+	// Narrow the feed to an author or a tag
+	var authorID uint
+	if username := c.Param("username"); username != "" {
+		var author models.User
+		if err := h.db(c).Where("username = ?", username).First(&author).Error; err != nil {
+			c.Error(apierror.FromDB(err, "User not found"))
+			return
+		}
+		query = query.Where("articles.user_id = ?", author.ID)
+		authorID = author.ID
+		feed.Title = fmt.Sprintf("%s - %s", displayName(author), h.siteName())
+		feed.Description = fmt.Sprintf("Latest articles by %s", displayName(author))
+		feed.Link = authorURL(base, author)
+	}
+	if name := c.Param("tag"); name != "" {
+		var tag models.Tag
+		if err := h.db(c).Where("name = ?", models.NormalizeTagName(name)).First(&tag).Error; err != nil {
+			c.Error(apierror.FromDB(err, "Tag not found"))
+			return
+		}
+		query = query.Joins("JOIN article_tags ON article_tags.article_id = articles.id AND article_tags.tag_id = ?", tag.ID)
+		feed.Title = fmt.Sprintf("#%s - %s", tag.Name, h.siteName())
+		feed.Description = fmt.Sprintf("Latest articles tagged %s", tag.Name)
+		feed.Link = fmt.Sprintf("%s/tags/%s", base, url.PathEscape(tag.Name))
+	}
+
+	var articles []models.Article
+	if err := query.Order("articles.created_at desc").Limit(feedSize).Find(&articles).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to get articles"))
+		return
+	}
+
+	for _, article := range articles {
+		if article.UpdatedAt.After(feed.Updated) {
+			feed.Updated = article.UpdatedAt
+		}
+		feed.Items = append(feed.Items, feedItem(base, article))
+	}
+
+	if feed.Updated.IsZero() {
+		feed.Updated = time.Now()
+	}
+
+	// Let feed readers skip unchanged feeds
+	lastModified, err := h.feedLastModified(c, authorID)
+	if err != nil {
+		c.Error(apierror.Internal(err, "Failed to get articles"))
+		return
+	}
+	etag := feedETag(format, articles)
+	c.Header("ETag", etag)
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	if notModified(c, etag) || notModifiedSince(c, lastModified) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	var body []byte
+	var contentType string
+	switch format {
+	case "rss":
+		body, err = feeds.RSS(feed)
+		contentType = "application/rss+xml; charset=utf-8"
+	case "atom":
+		body, err = feeds.Atom(feed)
+		contentType = "application/atom+xml; charset=utf-8"
+	default:
+		body, err = feeds.JSON(feed)
+		contentType = "application/feed+json; charset=utf-8"
+	}
+	if err != nil {
+		c.Error(apierror.Internal(err, "Failed to render feed"))
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// feedETag builds the ETag of a feed from the ID, version and update time of every entry,
+// so it changes when an article is added, edited, deleted or unpublished.
+func feedETag(format string, articles []models.Article) string {
+	h := fnv.New64a()
+	for _, article := range articles {
+		fmt.Fprintf(h, "%d.%d.%d;", article.ID, article.Version, article.UpdatedAt.UnixNano())
+	}
+	return fmt.Sprintf(`"%s.%016x"`, format, h.Sum64())
+}
+
+// feedLastModified returns the last time an article of the author (or of any author when
+// authorID is 0) was created, updated or deleted. Articles of every status count, so
+// unpublishing advances it as well. Tag feeds use the blog-wide time, since removing a tag
+// only changes the article, not the tag.
+func (h *Handler) feedLastModified(c *gin.Context, authorID uint) (time.Time, error) {
+	articles := h.db(c).Model(&models.Article{})
+	deletions := h.db(c).Model(&models.ArticleDeletion{})
+	if authorID != 0 {
+		articles = articles.Where("user_id = ?", authorID)
+		deletions = deletions.Where("user_id = ?", authorID)
+	}
+
+	var updatedAt, deletedAt sql.NullTime
+	if err := articles.Select("MAX(updated_at)").Scan(&updatedAt).Error; err != nil {
+		return time.Time{}, err
+	}
+	if err := deletions.Select("MAX(deleted_at)").Scan(&deletedAt).Error; err != nil {
+		return time.Time{}, err
+	}
+
+	if deletedAt.Time.After(updatedAt.Time) {
+		return deletedAt.Time, nil
+	}
+	return updatedAt.Time, nil
+}
+
+// recordArticleDeletion records that a published article was deleted, for feedLastModified.
+func (h *Handler) recordArticleDeletion(c *gin.Context, article models.Article) {
+	deletion := models.ArticleDeletion{ArticleID: article.ID, UserID: article.UserID, DeletedAt: time.Now()}
+	if err := h.db(c).Clauses(clause.OnConflict{UpdateAll: true}).Create(&deletion).Error; err != nil {
+		logging.FromContext(c.Request.Context()).Error("failed to record article deletion", "article_id", article.ID, "error", err)
+	}
+}
+
+// feedItem converts an article to a feed entry with sanitized content.
+func feedItem(base string, article models.Article) feeds.Item {
+	tags := make([]string, len(article.Tags))
+	for i, tag := range article.Tags {
+		tags[i] = tag.Name
+	}
+
+	return feeds.Item{
+		ID:          articleGUID(base, article),
+		Title:       article.Title,
+		Link:        articleURL(base, article),
+		AuthorName:  displayName(article.User),
+		AuthorURL:   authorURL(base, article.User),
+		Summary:     articleSummary(article),
+		ContentHTML: sanitize.HTML(article.Content),
+		Image:       article.CoverURL,
+		Tags:        tags,
+		Published:   article.CreatedAt,
+		Updated:     article.UpdatedAt,
+	}
+}
+
+// articleGUID returns a permanent tag URI (RFC 4151) identifying an article,
+// which stays the same even if the article's URL changes.
+func articleGUID(base string, article models.Article) string {
+	return fmt.Sprintf("tag:%s,%s:article-%d", hostOf(base), article.CreatedAt.UTC().Format("2006-01-02"), article.ID)
+}
+
+// articleSummary returns the article's excerpt, or the beginning of its text content.
+func articleSummary(article models.Article) string {
+	if article.Excerpt != "" {
+		return article.Excerpt
+	}
+
+	text := sanitize.Strip(article.Content)
+	if utf8.RuneCountInString(text) <= summaryLength {
+		return text
+	}
+	return string([]rune(text)[:summaryLength-1]) + "…"
+}
+
+// hostOf returns the host name of a URL, or the URL itself when it cannot be parsed.
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return rawURL
+	}
+	return u.Hostname()
+}
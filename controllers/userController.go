@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/apierror"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"github.com/jasen-devvv/mini-blog-backend/services"
+)
+
+// ProfileInput defines the structure for profile update requests.
+// Every field is optional; omitted fields are cleared.
+type ProfileInput struct {
+	DisplayName string             `json:"display_name" binding:"max=100"`
+	Bio         string             `json:"bio" binding:"max=1000"`
+	AvatarURL   string             `json:"avatar_url" binding:"omitempty,url,max=500"`
+	Website     string             `json:"website" binding:"omitempty,url,max=255"`
+	SocialLinks models.SocialLinks `json:"social_links" binding:"omitempty,max=10,dive,keys,oneof=twitter github linkedin mastodon facebook instagram youtube,endkeys,url,max=255"`
+}
+
+// GetUserProfile retrieves the public profile of a user by username, together with
+// follower counts and a paginated list of their published articles (newest first).
+// Private fields such as email and password are never included.
+// Returns a JSON response with the profile and articles or a "not found" error.
+func (h *Handler) GetUserProfile(c *gin.Context) {
+	page, limit := getPagination(c)
+
+	profile, err := h.Users.Profile(c, c.Param("username"), page, limit)
+	if errors.Is(err, services.ErrNotFound) {
+		c.Error(apierror.New(http.StatusNotFound, "User not found"))
+		return
+	}
+	if err != nil {
+		c.Error(apierror.Internal(err, "Failed to get profile"))
+		return
+	}
+
+	// Remove private fields from user data for security
+	user := profile.User
+	user.HidePrivate()
+	for i := range profile.Articles {
+		profile.Articles[i].User = user
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"user":            user,
+			"followers_count": profile.Followers,
+			"following_count": profile.Following,
+			"articles":        profile.Articles,
+		},
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": profile.Total,
+		},
+	})
+}
+
+// UpdateProfile updates the profile of the authenticated user.
+// Requires authentication, as it uses the user_id from the context (set by auth middleware).
+// All profile fields are replaced, so omitted fields are cleared.
+// Returns a JSON response with the updated user or an appropriate error message.
+func (h *Handler) UpdateProfile(c *gin.Context) {
+	// Get user_id from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	// Validate input
+	var input ProfileInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apierror.Validation(err))
+		return
+	}
+
+	user, err := h.Users.UpdateProfile(c, userID.(uint), models.User{
+		DisplayName: input.DisplayName,
+		Bio:         input.Bio,
+		AvatarURL:   input.AvatarURL,
+		Website:     input.Website,
+		SocialLinks: input.SocialLinks,
+	})
+	if errors.Is(err, services.ErrNotFound) {
+		c.Error(apierror.New(http.StatusNotFound, "User not found"))
+		return
+	}
+	if err != nil {
+		c.Error(apierror.Internal(err, "Failed to update profile"))
+		return
+	}
+
+	// Hide password in response for security
+	user.Password = ""
+	c.JSON(http.StatusOK, gin.H{"data": user})
+}
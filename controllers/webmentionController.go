@@ -0,0 +1,266 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/apierror"
+	"github.com/jasen-devvv/mini-blog-backend/background"
+	"github.com/jasen-devvv/mini-blog-backend/logging"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"github.com/jasen-devvv/mini-blog-backend/netsafe"
+	"github.com/jasen-devvv/mini-blog-backend/pubsub"
+	"github.com/jasen-devvv/mini-blog-backend/sanitize"
+	"github.com/jasen-devvv/mini-blog-backend/webmention"
+	"gorm.io/gorm/clause"
+)
+
+// WebmentionInput defines the structure of a received Webmention (sent as a form)
+type WebmentionInput struct {
+	Source string `form:"source" binding:"required,url,max=500"`
+	Target string `form:"target" binding:"required,url,max=500"`
+}
+
+// ModerateWebmentionInput defines the structure for webmention moderation requests
+type ModerateWebmentionInput struct {
+	Hidden *bool `json:"hidden" binding:"required"`
+}
+
+// ReceiveWebmention accepts a Webmention telling us that a source page links to one of our articles.
+// The target must be the page URL or ActivityPub ID of a published article.
+// The mention is stored as pending and its source is verified in the background; sending
+// the same mention again triggers a new verification, which is how senders report updates.
+// Returns 202 Accepted with the stored mention or an appropriate error message.
+func (h *Handler) ReceiveWebmention(c *gin.Context) {
+	var input WebmentionInput
+	if err := c.ShouldBind(&input); err != nil {
+		c.Error(apierror.Validation(err))
+		return
+	}
+
+	if !netsafe.IsHTTPURL(input.Source) || !netsafe.IsHTTPURL(input.Target) {
+		c.Error(apierror.New(http.StatusBadRequest, "Source and target must be http(s) URLs"))
+		return
+	}
+	if input.Source == input.Target {
+		c.Error(apierror.New(http.StatusBadRequest, "Source and target must be different"))
+		return
+	}
+
+	// The target must be one of our published articles
+	articleID, ok := articleIDFromURI(h.Config.APIURL, h.Config.SiteURL, input.Target)
+	if !ok {
+		c.Error(apierror.New(http.StatusBadRequest, "Target is not an article on this site"))
+		return
+	}
+	var article models.Article
+	if err := h.db(c).Where("status = ?", models.ArticleStatusPublished).First(&article, articleID).Error; err != nil {
+		c.Error(apierror.New(http.StatusBadRequest, "Target is not an article on this site"))
+		return
+	}
+
+	// Store the mention as pending, keeping the moderation decision of an earlier delivery
+	mention := models.Webmention{
+		ArticleID: article.ID,
+		Source:    input.Source,
+		Target:    input.Target,
+		Status:    models.WebmentionStatusPending,
+	}
+	err := h.db(c).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "source"}, {Name: "target"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "updated_at"}),
+	}).Create(&mention).Error
+	if err != nil {
+		c.Error(apierror.Internal(err, "Failed to save webmention"))
+		return
+	}
+	if err := h.db(c).Where("source = ? AND target = ?", mention.Source, mention.Target).First(&mention).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to save webmention"))
+		return
+	}
+
+	ctx := context.WithoutCancel(c.Request.Context())
+	background.Go(func() { h.verifyWebmention(ctx, mention) })
+
+	c.JSON(http.StatusAccepted, gin.H{"data": mention})
+}
+
+// GetWebmentions retrieves the verified webmentions of an article, to be shown next to its comments.
+// The article author (user_id is set by the optional auth middleware) also gets pending, invalid
+// and hidden mentions, for moderation.
+// Returns a JSON response with the webmentions or an appropriate error message.
+func (h *Handler) GetWebmentions(c *gin.Context) {
+	var article models.Article
+	if err := h.db(c).First(&article, c.Param("id")).Error; err != nil {
+		c.Error(apierror.FromDB(err, "Article not found"))
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	isAuthor := exists && userID.(uint) == article.UserID
+
+	// Hide drafts from everyone but their author
+	if article.Status != models.ArticleStatusPublished && !isAuthor {
+		c.Error(apierror.New(http.StatusNotFound, "Article not found"))
+		return
+	}
+
+	query := h.db(c).Where("article_id = ?", article.ID)
+	if !isAuthor {
+		query = query.Where("status = ? AND hidden = ?", models.WebmentionStatusVerified, false)
+	}
+
+	var mentions []models.Webmention
+	if err := query.Order("created_at asc").Find(&mentions).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to get webmentions"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": mentions})
+}
+
+// ModerateWebmention hides or shows a webmention of one of the authenticated user's articles.
+// Requires authentication and verifies that the user is the author of the mentioned article.
+// Returns a JSON response with the updated webmention or an appropriate error message.
+func (h *Handler) ModerateWebmention(c *gin.Context) {
+	mention, ok := h.findOwnWebmention(c)
+	if !ok {
+		return
+	}
+
+	var input ModerateWebmentionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apierror.Validation(err))
+		return
+	}
+
+	if err := h.db(c).Model(&mention).Update("hidden", *input.Hidden).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to update webmention"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": mention})
+}
+
+// DeleteWebmention removes a webmention of one of the authenticated user's articles.
+// Requires authentication and verifies that the user is the author of the mentioned article.
+// Returns a success message or an appropriate error message.
+func (h *Handler) DeleteWebmention(c *gin.Context) {
+	mention, ok := h.findOwnWebmention(c)
+	if !ok {
+		return
+	}
+
+	if err := h.db(c).Delete(&mention).Error; err != nil {
+		c.Error(apierror.Internal(err, "Failed to delete webmention"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": "Webmention deleted successfully"})
+}
+
+// findOwnWebmention loads the webmention from the "id" route parameter and checks that the
+// authenticated user wrote the mentioned article.
+// It writes an error response and returns false when the check fails.
+func (h *Handler) findOwnWebmention(c *gin.Context) (models.Webmention, bool) {
+	// Get user_id from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
+		return models.Webmention{}, false
+	}
+
+	var mention models.Webmention
+	if err := h.db(c).Preload("Article").First(&mention, c.Param("id")).Error; err != nil {
+		c.Error(apierror.FromDB(err, "Webmention not found"))
+		return mention, false
+	}
+
+	if mention.Article.UserID != userID.(uint) {
+		c.Error(apierror.New(http.StatusForbidden, "You are not authorized to moderate this webmention"))
+		return mention, false
+	}
+
+	return mention, true
+}
+
+// verifyWebmention fetches the source of a received mention and checks that it links to the
+// target. Verified mentions get the source's metadata and are streamed to clients watching
+// the article's comments; anything else is marked invalid. ctx carries the logger and
+// trace of the request that received the mention; it must not be cancelled with it.
+func (h *Handler) verifyWebmention(ctx context.Context, mention models.Webmention) {
+	logger := logging.FromContext(ctx)
+
+	fetchCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	meta, err := webmention.DefaultClient.Verify(fetchCtx, mention.Source, mention.Target)
+	if err != nil {
+		if !errors.Is(err, webmention.ErrNoLink) {
+			logger.Warn("failed to verify webmention", "webmention_id", mention.ID, "source", mention.Source, "error", err)
+		}
+		if err := h.DB.WithContext(ctx).Model(&mention).Update("status", models.WebmentionStatusInvalid).Error; err != nil {
+			logger.Error("failed to update webmention", "webmention_id", mention.ID, "error", err)
+		}
+		return
+	}
+
+	now := time.Now()
+	err = h.DB.WithContext(ctx).Model(&mention).Updates(map[string]interface{}{
+		"status":       models.WebmentionStatusVerified,
+		"type":         meta.Type,
+		"title":        meta.Title,
+		"author_name":  meta.AuthorName,
+		"author_url":   meta.AuthorURL,
+		"author_photo": meta.AuthorPhoto,
+		"excerpt":      meta.Excerpt,
+		"published":    meta.Published,
+		"verified_at":  &now,
+	}).Error
+	if err != nil {
+		logger.Error("failed to update webmention", "webmention_id", mention.ID, "error", err)
+		return
+	}
+
+	// Show the mention to clients watching the article, unless the author hid it
+	if err := h.DB.WithContext(ctx).First(&mention, mention.ID).Error; err != nil {
+		logger.Error("failed to load webmention", "webmention_id", mention.ID, "error", err)
+		return
+	}
+	if !mention.Hidden {
+		if err := pubsub.Publish(commentsTopic(mention.ArticleID), "webmention", mention); err != nil {
+			logger.Error("failed to publish webmention", "webmention_id", mention.ID, "error", err)
+		}
+	}
+}
+
+// sendWebmentions notifies the sites a published article links to, in the background.
+// Links to the blog itself and sites without a Webmention endpoint are skipped.
+func (h *Handler) sendWebmentions(c *gin.Context, article models.Article) {
+	site := h.Config.SiteURL
+	source := articleURL(site, article)
+	ctx := context.WithoutCancel(c.Request.Context())
+	logger := logging.FromContext(ctx)
+
+	background.Go(func() {
+		for _, target := range webmention.Links(sanitize.HTML(article.Content)) {
+			if hostOf(target) == hostOf(site) {
+				continue
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			endpoint, err := webmention.DefaultClient.DiscoverEndpoint(ctx, target)
+			if err == nil {
+				err = webmention.DefaultClient.Send(ctx, endpoint, source, target)
+			}
+			cancel()
+
+			if err != nil && !errors.Is(err, webmention.ErrNoEndpoint) {
+				logger.Error("failed to send webmention", "article_id", article.ID, "target", target, "error", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,407 @@
+// Package exporter builds ZIP exports of the blog or of a single user's data.
+//
+// An export contains:
+//   - articles/<slug>.md: each article as Markdown with YAML front matter (importable with the importer package).
+//   - comments/<slug>.json: the comments of each article.
+//   - media/: images referenced by the exported content, listed in media/index.json.
+//   - manifest.json: what the export contains and when it was made.
+//
+// A user export, used for data portability requests, also contains the user's profile,
+// the comments they wrote on other articles, their bookmarks and their reading lists.
+package exporter
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"github.com/jasen-devvv/mini-blog-backend/netsafe"
+	"github.com/jasen-devvv/mini-blog-backend/webmention"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+// Export scopes.
+const (
+	ScopeBlog = "blog" // every article, for administrators
+	ScopeUser = "user" // a single user's data
+)
+
+// Media limits, so an export cannot grow without bound.
+const (
+	maxMediaFiles = 1000
+	maxMediaSize  = 20 << 20
+)
+
+// HTTPClient downloads media. *http.Client implements it.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// MediaClient is the client used to download media. It shares the Webmention client,
+// which refuses to connect to private addresses.
+var MediaClient HTTPClient = webmention.DefaultClient.HTTP
+
+// Options selects what to export.
+//
+// Fields:
+//   - Scope: ScopeBlog or ScopeUser.
+//   - UserID: ID of the exported user (ScopeUser only).
+type Options struct {
+	Scope  string
+	UserID uint
+}
+
+// Manifest describes the content of an export.
+type Manifest struct {
+	Scope        string    `json:"scope"`
+	Username     string    `json:"username,omitempty"`
+	GeneratedAt  time.Time `json:"generated_at"`
+	Articles     int       `json:"articles"`
+	Comments     int       `json:"comments"`
+	MediaFiles   int       `json:"media_files"`
+	MediaSkipped int       `json:"media_skipped"`
+}
+
+// frontMatter is the YAML front matter of an exported article.
+type frontMatter struct {
+	Title           string    `yaml:"title"`
+	Slug            string    `yaml:"slug"`
+	Date            time.Time `yaml:"date"`
+	Updated         time.Time `yaml:"updated"`
+	Draft           bool      `yaml:"draft"`
+	Author          string    `yaml:"author"`
+	Tags            []string  `yaml:"tags,omitempty"`
+	Summary         string    `yaml:"summary,omitempty"`
+	Cover           string    `yaml:"cover,omitempty"`
+	MetaDescription string    `yaml:"meta_description,omitempty"`
+	CanonicalURL    string    `yaml:"canonical_url,omitempty"`
+	NoIndex         bool      `yaml:"noindex,omitempty"`
+}
+
+// exportedComment is a comment as written to the export.
+type exportedComment struct {
+	ID        uint      `json:"id"`
+	ArticleID uint      `json:"article_id"`
+	ParentID  *uint     `json:"parent_id,omitempty"`
+	Author    string    `json:"author"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// mediaEntry lists a referenced media file and where it was saved.
+type mediaEntry struct {
+	URL   string `json:"url"`
+	File  string `json:"file,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Write builds an export and writes it to w as a ZIP archive.
+func Write(ctx context.Context, db *gorm.DB, w io.Writer, options Options) (Manifest, error) {
+	manifest := Manifest{Scope: options.Scope, GeneratedAt: time.Now().UTC()}
+	archive := zip.NewWriter(w)
+
+	// Select the articles
+	query := db.Preload("User").Preload("Tags").Order("id asc")
+	var user models.User
+	switch options.Scope {
+	case ScopeBlog:
+	case ScopeUser:
+		if err := db.First(&user, options.UserID).Error; err != nil {
+			return manifest, fmt.Errorf("loading user: %w", err)
+		}
+		manifest.Username = user.Username
+		query = query.Where("user_id = ?", user.ID)
+	default:
+		return manifest, fmt.Errorf("unknown export scope %q", options.Scope)
+	}
+
+	var articles []models.Article
+	if err := query.Find(&articles).Error; err != nil {
+		return manifest, err
+	}
+
+	media := newMediaCollector()
+	names := map[uint]string{}
+	for _, article := range articles {
+		name := articleFileName(article)
+		names[article.ID] = name
+
+		if err := writeArticle(archive, name, article); err != nil {
+			return manifest, err
+		}
+		media.addArticle(article)
+
+		var comments []models.Comment
+		if err := db.Preload("User").Preload("RemoteActor").Where("article_id = ?", article.ID).Order("id asc").Find(&comments).Error; err != nil {
+			return manifest, err
+		}
+		if len(comments) > 0 {
+			if err := writeJSON(archive, "comments/"+name+".json", exportComments(comments)); err != nil {
+				return manifest, err
+			}
+		}
+		manifest.Comments += len(comments)
+	}
+	manifest.Articles = len(articles)
+
+	if options.Scope == ScopeUser {
+		if err := writeUserData(db, archive, user, &manifest); err != nil {
+			return manifest, err
+		}
+		if user.AvatarURL != "" {
+			media.add(user.AvatarURL)
+		}
+	}
+
+	files, skipped, err := media.write(ctx, archive)
+	if err != nil {
+		return manifest, err
+	}
+	manifest.MediaFiles, manifest.MediaSkipped = files, skipped
+
+	if err := writeJSON(archive, "manifest.json", manifest); err != nil {
+		return manifest, err
+	}
+	return manifest, archive.Close()
+}
+
+// writeArticle writes an article as Markdown with YAML front matter. The content is kept
+// as stored; HTML is valid Markdown.
+func writeArticle(archive *zip.Writer, name string, article models.Article) error {
+	meta := frontMatter{
+		Title:           article.Title,
+		Slug:            article.Slug,
+		Date:            article.CreatedAt.UTC(),
+		Updated:         article.UpdatedAt.UTC(),
+		Draft:           article.Status != models.ArticleStatusPublished,
+		Author:          article.User.Username,
+		Summary:         article.Excerpt,
+		Cover:           article.CoverURL,
+		MetaDescription: article.MetaDescription,
+		CanonicalURL:    article.CanonicalURL,
+		NoIndex:         article.NoIndex,
+	}
+	for _, tag := range article.Tags {
+		meta.Tags = append(meta.Tags, tag.Name)
+	}
+
+	header, err := yaml.Marshal(meta)
+	if err != nil {
+		return err
+	}
+
+	file, err := create(archive, "articles/"+name+".md", article.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(file, "---\n%s---\n\n%s\n", header, article.Content)
+	return err
+}
+
+// writeUserData writes the profile, the comments on other articles, the bookmarks and
+// the reading lists of a user.
+func writeUserData(db *gorm.DB, archive *zip.Writer, user models.User, manifest *Manifest) error {
+	user.Password = ""
+	if err := writeJSON(archive, "profile.json", user); err != nil {
+		return err
+	}
+
+	var comments []models.Comment
+	err := db.Joins("JOIN articles ON articles.id = comments.article_id").
+		Where("comments.user_id = ? AND articles.user_id <> ?", user.ID, user.ID).
+		Preload("User").Order("comments.id asc").Find(&comments).Error
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(archive, "my-comments.json", exportComments(comments)); err != nil {
+		return err
+	}
+	manifest.Comments += len(comments)
+
+	var bookmarks []models.Bookmark
+	if err := db.Preload("Article").Where("user_id = ?", user.ID).Order("id asc").Find(&bookmarks).Error; err != nil {
+		return err
+	}
+	if err := writeJSON(archive, "bookmarks.json", bookmarks); err != nil {
+		return err
+	}
+
+	var lists []models.ReadingList
+	if err := db.Preload("Items", func(tx *gorm.DB) *gorm.DB { return tx.Order("position asc, id asc") }).
+		Preload("Items.Article").Where("user_id = ?", user.ID).Order("id asc").Find(&lists).Error; err != nil {
+		return err
+	}
+	return writeJSON(archive, "reading-lists.json", lists)
+}
+
+// exportComments converts comments for the export, naming their author.
+func exportComments(comments []models.Comment) []exportedComment {
+	exported := make([]exportedComment, len(comments))
+	for i, comment := range comments {
+		author := comment.AuthorName
+		if comment.User != nil {
+			author = comment.User.Username
+		} else if comment.RemoteActor != nil {
+			author = comment.RemoteActor.URI
+		}
+
+		exported[i] = exportedComment{
+			ID:        comment.ID,
+			ArticleID: comment.ArticleID,
+			ParentID:  comment.ParentID,
+			Author:    author,
+			Content:   comment.Content,
+			CreatedAt: comment.CreatedAt,
+		}
+	}
+	return exported
+}
+
+// articleFileName returns the base file name of an exported article.
+func articleFileName(article models.Article) string {
+	if article.Slug != "" {
+		return fmt.Sprintf("%d-%s", article.ID, article.Slug)
+	}
+	return fmt.Sprintf("%d", article.ID)
+}
+
+// mediaCollector gathers the media URLs referenced by exported content.
+type mediaCollector struct {
+	urls []string
+	seen map[string]bool
+}
+
+func newMediaCollector() *mediaCollector {
+	return &mediaCollector{seen: map[string]bool{}}
+}
+
+// add records a media URL, ignoring duplicates and non-http(s) URLs.
+func (m *mediaCollector) add(rawURL string) {
+	if !netsafe.IsHTTPURL(rawURL) || m.seen[rawURL] {
+		return
+	}
+	m.seen[rawURL] = true
+	m.urls = append(m.urls, rawURL)
+}
+
+// addArticle records the cover and the inline images of an article.
+func (m *mediaCollector) addArticle(article models.Article) {
+	m.add(article.CoverURL)
+
+	doc, err := html.Parse(strings.NewReader(article.Content))
+	if err != nil {
+		return
+	}
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Img {
+			for _, a := range n.Attr {
+				if a.Key == "src" {
+					m.add(a.Val)
+				}
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+}
+
+// write downloads the collected media into the archive and writes media/index.json.
+// Files that cannot be downloaded are listed in the index with the reason, rather than
+// failing the export. It returns the number of saved and skipped files.
+func (m *mediaCollector) write(ctx context.Context, archive *zip.Writer) (int, int, error) {
+	index := make([]mediaEntry, 0, len(m.urls))
+	saved := 0
+
+	for i, rawURL := range m.urls {
+		entry := mediaEntry{URL: rawURL}
+		if i >= maxMediaFiles {
+			entry.Error = "too many media files"
+		} else if file, err := download(ctx, archive, rawURL, fmt.Sprintf("media/%04d", i+1)); err != nil {
+			entry.Error = err.Error()
+		} else {
+			entry.File = file
+			saved++
+		}
+		index = append(index, entry)
+	}
+
+	if len(index) > 0 {
+		if err := writeJSON(archive, "media/index.json", index); err != nil {
+			return saved, 0, err
+		}
+	}
+	return saved, len(index) - saved, nil
+}
+
+// download saves a media file into the archive under the given name, adding an
+// extension from its content type. It returns the file's path in the archive.
+func download(ctx context.Context, archive *zip.Writer, rawURL, name string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := MediaClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	if resp.ContentLength > maxMediaSize {
+		return "", fmt.Errorf("file is larger than %d bytes", maxMediaSize)
+	}
+
+	ext := path.Ext(req.URL.Path)
+	if exts, _ := mime.ExtensionsByType(resp.Header.Get("Content-Type")); len(exts) > 0 {
+		ext = exts[0]
+	}
+
+	file, err := create(archive, name+ext, time.Now())
+	if err != nil {
+		return "", err
+	}
+	written, err := io.Copy(file, io.LimitReader(resp.Body, maxMediaSize+1))
+	if err != nil {
+		return "", err
+	}
+	if written > maxMediaSize {
+		return "", fmt.Errorf("file is larger than %d bytes", maxMediaSize)
+	}
+
+	return name + ext, nil
+}
+
+// writeJSON writes an indented JSON file into the archive.
+func writeJSON(archive *zip.Writer, name string, value interface{}) error {
+	file, err := create(archive, name, time.Now())
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// create adds a compressed file to the archive.
+func create(archive *zip.Writer, name string, modified time.Time) (io.Writer, error) {
+	return archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+}
@@ -0,0 +1,337 @@
+package exporter_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/jasen-devvv/mini-blog-backend/exporter"
+	"github.com/jasen-devvv/mini-blog-backend/internal/testutil"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(testutil.RunWithDatabase(m))
+}
+
+// blog is the content seeded for the export tests.
+//
+// Fields:
+//   - Alice: Author of Published and Draft.
+//   - Bob: Author of Notes, who commented on Published and bookmarked it.
+//   - Published: Alice's published article, with a tag, an image and comments.
+//   - Draft: Alice's draft.
+//   - Notes: Bob's published article.
+type blog struct {
+	Alice     models.User
+	Bob       models.User
+	Published models.Article
+	Draft     models.Article
+	Notes     models.Article
+}
+
+// seed inserts the blog content into db.
+func seed(t *testing.T, db *gorm.DB) blog {
+	t.Helper()
+	var b blog
+
+	b.Alice = models.User{Username: "alice", Email: "alice@example.com", Password: "alice-hash"}
+	b.Bob = models.User{Username: "bob", Email: "bob@example.com", Password: "bob-hash"}
+	b.Published = models.Article{
+		Title:   "Hello",
+		Slug:    "hello",
+		Content: `<p>Hi</p><img src="https://cdn.example/cat.png"><img src="https://cdn.example/missing.png">`,
+		Excerpt: "A greeting",
+		Status:  models.ArticleStatusPublished,
+		Tags:    []models.Tag{{Name: "go"}},
+	}
+	b.Draft = models.Article{Title: "Draft", Slug: "draft", Content: "Later", Status: models.ArticleStatusDraft}
+	b.Notes = models.Article{Title: "Notes", Slug: "notes", Content: "Bob's notes", Status: models.ArticleStatusPublished}
+
+	for _, user := range []*models.User{&b.Alice, &b.Bob} {
+		if err := db.Create(user).Error; err != nil {
+			t.Fatalf("creating %s: %v", user.Username, err)
+		}
+	}
+	b.Published.UserID, b.Draft.UserID, b.Notes.UserID = b.Alice.ID, b.Alice.ID, b.Bob.ID
+	for _, article := range []*models.Article{&b.Published, &b.Draft, &b.Notes} {
+		if err := db.Create(article).Error; err != nil {
+			t.Fatalf("creating %s: %v", article.Title, err)
+		}
+	}
+
+	comment := models.Comment{Content: "Nice post", ArticleID: b.Published.ID, UserID: &b.Bob.ID}
+	if err := db.Create(&comment).Error; err != nil {
+		t.Fatal(err)
+	}
+	reply := models.Comment{Content: "Thanks", ArticleID: b.Published.ID, UserID: &b.Alice.ID, ParentID: &comment.ID}
+	guest := models.Comment{Content: "Hi from afar", ArticleID: b.Published.ID, AuthorName: "Guest"}
+	for _, c := range []*models.Comment{&reply, &guest} {
+		if err := db.Create(c).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := db.Create(&models.Bookmark{UserID: b.Bob.ID, ArticleID: b.Published.ID}).Error; err != nil {
+		t.Fatal(err)
+	}
+	list := models.ReadingList{UserID: b.Bob.ID, Name: "Later", Items: []models.ReadingListItem{{ArticleID: b.Published.ID}}}
+	if err := db.Create(&list).Error; err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// fakeMedia serves the media files it holds, and 404 for any other URL.
+type fakeMedia map[string]string
+
+func (m fakeMedia) Do(req *http.Request) (*http.Response, error) {
+	body, ok := m[req.URL.String()]
+	if !ok {
+		return &http.Response{StatusCode: http.StatusNotFound, Status: "404 Not Found", Body: io.NopCloser(strings.NewReader(""))}, nil
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "200 OK",
+		Header:     http.Header{"Content-Type": {"image/png"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}, nil
+}
+
+// useMedia makes the exporter download media from media until the test ends.
+func useMedia(t *testing.T, media fakeMedia) {
+	previous := exporter.MediaClient
+	exporter.MediaClient = media
+	t.Cleanup(func() { exporter.MediaClient = previous })
+}
+
+// export writes an export into a temporary directory and returns its manifest and the
+// files of the archive, read back from the disk.
+func export(t *testing.T, db *gorm.DB, options exporter.Options) (exporter.Manifest, map[string][]byte) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "export.zip")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := exporter.Write(context.Background(), db, file, options)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		t.Fatalf("writing the export: %v", err)
+	}
+	return manifest, readArchive(t, path)
+}
+
+// readArchive returns the content of each file of a ZIP archive.
+func readArchive(t *testing.T, path string) map[string][]byte {
+	t.Helper()
+
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("opening the archive: %v", err)
+	}
+	defer archive.Close()
+
+	files := map[string][]byte{}
+	for _, f := range archive.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("reading %s: %v", f.Name, err)
+		}
+		files[f.Name] = data
+	}
+	return files
+}
+
+// names returns the sorted names of files.
+func names(files map[string][]byte) []string {
+	list := make([]string, 0, len(files))
+	for name := range files {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// decodeJSON unmarshals a JSON file of the archive into target.
+func decodeJSON(t *testing.T, files map[string][]byte, name string, target interface{}) {
+	t.Helper()
+	data, ok := files[name]
+	if !ok {
+		t.Fatalf("the archive has no %s", name)
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		t.Fatalf("decoding %s: %v", name, err)
+	}
+}
+
+// splitArticle returns the front matter and the body of an exported article.
+func splitArticle(t *testing.T, data []byte) (map[string]interface{}, string) {
+	t.Helper()
+	rest, ok := bytes.CutPrefix(data, []byte("---\n"))
+	header, body, found := bytes.Cut(rest, []byte("---\n\n"))
+	if !ok || !found {
+		t.Fatalf("article has no front matter:\n%s", data)
+	}
+	var meta map[string]interface{}
+	if err := yaml.Unmarshal(header, &meta); err != nil {
+		t.Fatalf("decoding the front matter: %v", err)
+	}
+	return meta, string(body)
+}
+
+func TestWriteBlog(t *testing.T) {
+	db := testutil.Database(t)
+	b := seed(t, db)
+	useMedia(t, fakeMedia{"https://cdn.example/cat.png": "png data"})
+
+	manifest, files := export(t, db, exporter.Options{Scope: exporter.ScopeBlog})
+
+	want := []string{
+		"articles/" + baseName(b.Published) + ".md",
+		"articles/" + baseName(b.Draft) + ".md",
+		"articles/" + baseName(b.Notes) + ".md",
+		"comments/" + baseName(b.Published) + ".json",
+		"manifest.json",
+		"media/0001.png",
+		"media/index.json",
+	}
+	sort.Strings(want)
+	if got := names(files); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("files = %v, want %v", got, want)
+	}
+
+	// Articles are Markdown with front matter the importer reads back
+	meta, body := splitArticle(t, files["articles/"+baseName(b.Published)+".md"])
+	if meta["title"] != "Hello" || meta["slug"] != "hello" || meta["author"] != "alice" || meta["draft"] != false || meta["summary"] != "A greeting" {
+		t.Errorf("front matter = %v", meta)
+	}
+	if tags, _ := meta["tags"].([]interface{}); len(tags) != 1 || tags[0] != "go" {
+		t.Errorf("tags = %v, want [go]", meta["tags"])
+	}
+	if body != b.Published.Content+"\n" {
+		t.Errorf("body = %q, want the stored content", body)
+	}
+	if meta, _ := splitArticle(t, files["articles/"+baseName(b.Draft)+".md"]); meta["draft"] != true {
+		t.Errorf("draft front matter = %v, want draft: true", meta)
+	}
+
+	// Comments keep their thread and author, local or not
+	var comments []struct {
+		ID       uint   `json:"id"`
+		ParentID *uint  `json:"parent_id"`
+		Author   string `json:"author"`
+		Content  string `json:"content"`
+	}
+	decodeJSON(t, files, "comments/"+baseName(b.Published)+".json", &comments)
+	if len(comments) != 3 {
+		t.Fatalf("comments = %+v, want 3", comments)
+	}
+	if comments[0].Author != "bob" || comments[0].Content != "Nice post" || comments[0].ParentID != nil {
+		t.Errorf("first comment = %+v", comments[0])
+	}
+	if comments[1].Author != "alice" || comments[1].ParentID == nil || *comments[1].ParentID != comments[0].ID {
+		t.Errorf("reply = %+v, want a reply to the first comment", comments[1])
+	}
+	if comments[2].Author != "Guest" {
+		t.Errorf("guest comment = %+v, want the guest's name", comments[2])
+	}
+
+	// Media that cannot be downloaded are listed with the reason
+	var media []struct{ URL, File, Error string }
+	decodeJSON(t, files, "media/index.json", &media)
+	if len(media) != 2 || media[0].File != "media/0001.png" || media[1].File != "" || !strings.Contains(media[1].Error, "404") {
+		t.Errorf("media index = %+v", media)
+	}
+	if string(files["media/0001.png"]) != "png data" {
+		t.Errorf("media file = %q", files["media/0001.png"])
+	}
+
+	var written exporter.Manifest
+	decodeJSON(t, files, "manifest.json", &written)
+	if written.Scope != exporter.ScopeBlog || written.Articles != 3 || written.Comments != 3 || written.MediaFiles != 1 || written.MediaSkipped != 1 {
+		t.Errorf("manifest = %+v", written)
+	}
+	if written.Articles != manifest.Articles || written.Comments != manifest.Comments {
+		t.Errorf("returned manifest = %+v, written %+v", manifest, written)
+	}
+}
+
+func TestWriteUser(t *testing.T) {
+	db := testutil.Database(t)
+	b := seed(t, db)
+	useMedia(t, fakeMedia{})
+
+	manifest, files := export(t, db, exporter.Options{Scope: exporter.ScopeUser, UserID: b.Bob.ID})
+
+	// Only Bob's articles, with his data
+	want := []string{"articles/" + baseName(b.Notes) + ".md", "bookmarks.json", "manifest.json", "my-comments.json", "profile.json", "reading-lists.json"}
+	if got := names(files); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("files = %v, want %v", got, want)
+	}
+	if manifest.Username != "bob" || manifest.Articles != 1 || manifest.Comments != 1 {
+		t.Errorf("manifest = %+v", manifest)
+	}
+
+	var profile map[string]interface{}
+	decodeJSON(t, files, "profile.json", &profile)
+	if profile["username"] != "bob" || profile["email"] != "bob@example.com" {
+		t.Errorf("profile = %v", profile)
+	}
+	if bytes.Contains(files["profile.json"], []byte("bob-hash")) {
+		t.Error("profile contains the password hash")
+	}
+
+	// The comments Bob wrote on other authors' articles
+	var comments []struct {
+		ArticleID uint   `json:"article_id"`
+		Content   string `json:"content"`
+	}
+	decodeJSON(t, files, "my-comments.json", &comments)
+	if len(comments) != 1 || comments[0].ArticleID != b.Published.ID || comments[0].Content != "Nice post" {
+		t.Errorf("my comments = %+v", comments)
+	}
+
+	var bookmarks []models.Bookmark
+	decodeJSON(t, files, "bookmarks.json", &bookmarks)
+	if len(bookmarks) != 1 || bookmarks[0].Article.Title != "Hello" {
+		t.Errorf("bookmarks = %+v", bookmarks)
+	}
+	var lists []models.ReadingList
+	decodeJSON(t, files, "reading-lists.json", &lists)
+	if len(lists) != 1 || lists[0].Name != "Later" || len(lists[0].Items) != 1 || lists[0].Items[0].Article.Title != "Hello" {
+		t.Errorf("reading lists = %+v", lists)
+	}
+}
+
+func TestWriteUnknownScope(t *testing.T) {
+	db := testutil.Database(t)
+	if _, err := exporter.Write(context.Background(), db, io.Discard, exporter.Options{Scope: "everything"}); err == nil {
+		t.Error("export with an unknown scope succeeded")
+	}
+}
+
+// baseName returns the name of the files of an article in the archive, without the
+// directory and extension.
+func baseName(article models.Article) string {
+	return fmt.Sprintf("%d-%s", article.ID, article.Slug)
+}
@@ -0,0 +1,171 @@
+package exporter
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jasen-devvv/mini-blog-backend/logging"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"gorm.io/gorm"
+)
+
+// LinkTTL is how long the download link of a finished export stays valid.
+const LinkTTL = 24 * time.Hour
+
+// Process runs an export job: it writes the archive into dir and marks the job completed with
+// a download token that expires after LinkTTL, or failed with the reason. Cancelling ctx
+// stops the export and fails the job.
+func Process(ctx context.Context, db *gorm.DB, dir string, jobID uint) {
+	logger := logging.FromContext(ctx).With("job_id", jobID)
+
+	var job models.ExportJob
+	if err := db.First(&job, jobID).Error; err != nil {
+		logger.Error("failed to load export job", "error", err)
+		return
+	}
+
+	// Claim the job, so it only runs once
+	result := db.Model(&job).Where("status = ?", models.ExportStatusPending).Update("status", models.ExportStatusRunning)
+	if result.Error != nil {
+		logger.Error("failed to claim export job", "error", result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		return
+	}
+
+	fail := func(reason string) {
+		if err := db.Model(&job).Updates(map[string]interface{}{
+			"status":       models.ExportStatusFailed,
+			"error":        reason,
+			"completed_at": time.Now(),
+		}).Error; err != nil {
+			logger.Error("failed to mark export job failed", "error", err)
+		}
+	}
+
+	path, size, err := writeJob(ctx, db, dir, job)
+	if err != nil {
+		logger.Error("failed to export", "error", err)
+		fail(truncate(err.Error(), 500))
+		return
+	}
+
+	token, err := newToken()
+	if err != nil {
+		os.Remove(path)
+		logger.Error("failed to create export token", "error", err)
+		fail("internal error")
+		return
+	}
+
+	now := time.Now()
+	if err := db.Model(&job).Updates(map[string]interface{}{
+		"status":       models.ExportStatusCompleted,
+		"file_path":    path,
+		"size":         size,
+		"token":        token,
+		"expires_at":   now.Add(LinkTTL),
+		"completed_at": now,
+	}).Error; err != nil {
+		os.Remove(path)
+		logger.Error("failed to complete export job", "error", err)
+	}
+}
+
+// writeJob writes the archive of a job into dir and returns its path and size.
+func writeJob(ctx context.Context, db *gorm.DB, dir string, job models.ExportJob) (string, int64, error) {
+	options := Options{Scope: job.Scope}
+	if job.SubjectUserID != nil {
+		options.UserID = *job.SubjectUserID
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", 0, err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("export-%d.zip", job.ID))
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", 0, err
+	}
+
+	_, err = Write(ctx, db, file, options)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", 0, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", 0, err
+	}
+	return path, info.Size(), nil
+}
+
+// Cleanup deletes the archives of expired exports and marks them expired.
+func Cleanup(db *gorm.DB) error {
+	var jobs []models.ExportJob
+	if err := db.Where("status = ? AND expires_at < ?", models.ExportStatusCompleted, time.Now()).Find(&jobs).Error; err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		if err := os.Remove(job.FilePath); err != nil && !os.IsNotExist(err) {
+			slog.Error("failed to delete export", "path", job.FilePath, "error", err)
+			continue
+		}
+		if err := db.Model(&job).Updates(map[string]interface{}{
+			"status":    models.ExportStatusExpired,
+			"file_path": "",
+			"token":     "",
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunCleanup calls Cleanup every interval until ctx is done.
+func RunCleanup(ctx context.Context, db *gorm.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := Cleanup(db); err != nil {
+			slog.Error("failed to clean up exports", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// newToken returns a random download token.
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// truncate shortens a string to at most max bytes.
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
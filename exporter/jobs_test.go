@@ -0,0 +1,129 @@
+package exporter_test
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/jasen-devvv/mini-blog-backend/exporter"
+	"github.com/jasen-devvv/mini-blog-backend/internal/testutil"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"gorm.io/gorm"
+)
+
+// reload reads a job back from the database.
+func reload(t *testing.T, db *gorm.DB, job models.ExportJob) models.ExportJob {
+	t.Helper()
+	if err := db.First(&job, job.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	return job
+}
+
+func TestProcess(t *testing.T) {
+	db := testutil.Database(t)
+	b := seed(t, db)
+	useMedia(t, fakeMedia{})
+	dir := t.TempDir()
+
+	job := models.ExportJob{UserID: b.Bob.ID, Scope: exporter.ScopeUser, SubjectUserID: &b.Bob.ID, Status: models.ExportStatusPending}
+	if err := db.Create(&job).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	started := time.Now()
+	exporter.Process(context.Background(), db, dir, job.ID)
+	job = reload(t, db, job)
+
+	if job.Status != models.ExportStatusCompleted || job.CompletedAt == nil {
+		t.Fatalf("job = %+v, want completed", job)
+	}
+	if !regexp.MustCompile(`^[0-9a-f]{64}$`).MatchString(job.Token) {
+		t.Errorf("token = %q, want 32 random bytes in hex", job.Token)
+	}
+	if job.ExpiresAt == nil || job.ExpiresAt.Before(started.Add(exporter.LinkTTL)) || job.ExpiresAt.After(time.Now().Add(exporter.LinkTTL)) {
+		t.Errorf("expires at %v, want LinkTTL after completion", job.ExpiresAt)
+	}
+
+	// The archive is the user's export
+	info, err := os.Stat(job.FilePath)
+	if err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if info.Size() != job.Size {
+		t.Errorf("size = %d, archive has %d bytes", job.Size, info.Size())
+	}
+	if files := readArchive(t, job.FilePath); files["profile.json"] == nil || files["articles/"+baseName(b.Notes)+".md"] == nil {
+		t.Errorf("archive files = %v, want bob's export", names(files))
+	}
+
+	// A job runs once
+	exporter.Process(context.Background(), db, dir, job.ID)
+	if again := reload(t, db, job); again.Token != job.Token {
+		t.Error("processing a completed job again replaced it")
+	}
+}
+
+func TestProcessFailure(t *testing.T) {
+	db := testutil.Database(t)
+	b := seed(t, db)
+
+	// The exported user does not exist
+	missing := b.Bob.ID + 100
+	job := models.ExportJob{UserID: b.Bob.ID, Scope: exporter.ScopeUser, SubjectUserID: &missing, Status: models.ExportStatusPending}
+	if err := db.Create(&job).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	exporter.Process(context.Background(), db, dir, job.ID)
+	job = reload(t, db, job)
+	if job.Status != models.ExportStatusFailed || job.Error == "" || job.CompletedAt == nil || job.Token != "" {
+		t.Errorf("job = %+v, want failed with the reason", job)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("export directory holds %d files, want the partial archive removed", len(entries))
+	}
+}
+
+func TestCleanup(t *testing.T) {
+	db := testutil.Database(t)
+	b := seed(t, db)
+	dir := t.TempDir()
+
+	// One link expired, the other is still valid
+	jobs := make([]models.ExportJob, 2)
+	for i, expiresAt := range []time.Time{time.Now().Add(-time.Minute), time.Now().Add(exporter.LinkTTL)} {
+		file, err := os.CreateTemp(dir, "export-*.zip")
+		if err != nil {
+			t.Fatal(err)
+		}
+		file.Close()
+		jobs[i] = models.ExportJob{UserID: b.Alice.ID, Scope: exporter.ScopeBlog, Status: models.ExportStatusCompleted, FilePath: file.Name(), Token: "token", ExpiresAt: &expiresAt}
+		if err := db.Create(&jobs[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := exporter.Cleanup(db); err != nil {
+		t.Fatal(err)
+	}
+
+	expired := reload(t, db, jobs[0])
+	if expired.Status != models.ExportStatusExpired || expired.Token != "" || expired.FilePath != "" {
+		t.Errorf("expired job = %+v, want expired without a token or file", expired)
+	}
+	if _, err := os.Stat(jobs[0].FilePath); !os.IsNotExist(err) {
+		t.Errorf("expired archive still exists: %v", err)
+	}
+
+	valid := reload(t, db, jobs[1])
+	if valid.Status != models.ExportStatusCompleted || valid.Token != "token" {
+		t.Errorf("valid job = %+v, want it untouched", valid)
+	}
+	if _, err := os.Stat(jobs[1].FilePath); err != nil {
+		t.Errorf("valid archive: %v", err)
+	}
+}
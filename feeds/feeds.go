@@ -0,0 +1,259 @@
+// Package feeds renders syndication feeds in RSS 2.0, Atom 1.0 and JSON Feed 1.1 formats.
+//
+// Callers build a format independent Feed and pass it to RSS, Atom or JSON.
+// Item content is expected to be sanitized HTML already.
+package feeds
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"time"
+)
+
+// Feed describes a feed independently of its output format.
+//
+// Fields:
+//   - Title: Title of the feed.
+//   - Description: Short description of the feed.
+//   - Link: URL of the HTML page the feed corresponds to.
+//   - FeedURL: URL the feed itself is served from.
+//   - ID: Permanent unique identifier of the feed (Atom id).
+//   - Updated: Time of the most recent change to any item.
+//   - Items: Entries of the feed, newest first.
+type Feed struct {
+	Title       string
+	Description string
+	Link        string
+	FeedURL     string
+	ID          string
+	Updated     time.Time
+	Items       []Item
+}
+
+// Item describes a single feed entry.
+//
+// Fields:
+//   - ID: Permanent globally unique identifier of the entry (GUID).
+//   - Title: Title of the entry.
+//   - Link: URL of the entry's HTML page.
+//   - AuthorName: Display name of the author.
+//   - AuthorURL: URL of the author's page.
+//   - Summary: Plain text summary.
+//   - ContentHTML: Sanitized HTML content.
+//   - Image: URL of the entry's cover image, if any.
+//   - Tags: Tag names of the entry.
+//   - Published: Time the entry was first published.
+//   - Updated: Time the entry was last modified.
+type Item struct {
+	ID          string
+	Title       string
+	Link        string
+	AuthorName  string
+	AuthorURL   string
+	Summary     string
+	ContentHTML string
+	Image       string
+	Tags        []string
+	Published   time.Time
+	Updated     time.Time
+}
+
+// RSS renders the feed as RSS 2.0.
+func RSS(feed Feed) ([]byte, error) {
+	type guid struct {
+		IsPermaLink bool   `xml:"isPermaLink,attr"`
+		Value       string `xml:",chardata"`
+	}
+	type cdata struct {
+		Value string `xml:",cdata"`
+	}
+	type atomLink struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+		Type string `xml:"type,attr"`
+	}
+	type item struct {
+		Title       string   `xml:"title"`
+		Link        string   `xml:"link"`
+		GUID        guid     `xml:"guid"`
+		Author      string   `xml:"dc:creator,omitempty"`
+		Categories  []string `xml:"category"`
+		PubDate     string   `xml:"pubDate"`
+		Description cdata    `xml:"description"`
+		Content     cdata    `xml:"content:encoded"`
+	}
+	type channel struct {
+		Title         string   `xml:"title"`
+		Link          string   `xml:"link"`
+		Description   string   `xml:"description"`
+		AtomLink      atomLink `xml:"atom:link"`
+		LastBuildDate string   `xml:"lastBuildDate"`
+		Items         []item   `xml:"item"`
+	}
+	type rss struct {
+		XMLName   xml.Name `xml:"rss"`
+		Version   string   `xml:"version,attr"`
+		AtomNS    string   `xml:"xmlns:atom,attr"`
+		ContentNS string   `xml:"xmlns:content,attr"`
+		DCNS      string   `xml:"xmlns:dc,attr"`
+		Channel   channel  `xml:"channel"`
+	}
+
+	doc := rss{
+		Version:   "2.0",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		DCNS:      "http://purl.org/dc/elements/1.1/",
+		Channel: channel{
+			Title:         feed.Title,
+			Link:          feed.Link,
+			Description:   feed.Description,
+			AtomLink:      atomLink{Href: feed.FeedURL, Rel: "self", Type: "application/rss+xml"},
+			LastBuildDate: feed.Updated.UTC().Format(time.RFC1123Z),
+		},
+	}
+	for _, it := range feed.Items {
+		doc.Channel.Items = append(doc.Channel.Items, item{
+			Title:       it.Title,
+			Link:        it.Link,
+			GUID:        guid{IsPermaLink: false, Value: it.ID},
+			Author:      it.AuthorName,
+			Categories:  it.Tags,
+			PubDate:     it.Published.UTC().Format(time.RFC1123Z),
+			Description: cdata{Value: it.Summary},
+			Content:     cdata{Value: it.ContentHTML},
+		})
+	}
+
+	return marshalXML(doc)
+}
+
+// Atom renders the feed as Atom 1.0.
+func Atom(feed Feed) ([]byte, error) {
+	type link struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr,omitempty"`
+		Type string `xml:"type,attr,omitempty"`
+	}
+	type person struct {
+		Name string `xml:"name"`
+		URI  string `xml:"uri,omitempty"`
+	}
+	type text struct {
+		Type  string `xml:"type,attr"`
+		Value string `xml:",chardata"`
+	}
+	type category struct {
+		Term string `xml:"term,attr"`
+	}
+	type entry struct {
+		ID         string     `xml:"id"`
+		Title      string     `xml:"title"`
+		Links      []link     `xml:"link"`
+		Author     person     `xml:"author"`
+		Categories []category `xml:"category"`
+		Published  string     `xml:"published"`
+		Updated    string     `xml:"updated"`
+		Summary    text       `xml:"summary"`
+		Content    text       `xml:"content"`
+	}
+	type atomFeed struct {
+		XMLName xml.Name `xml:"feed"`
+		NS      string   `xml:"xmlns,attr"`
+		ID      string   `xml:"id"`
+		Title   string   `xml:"title"`
+		Links   []link   `xml:"link"`
+		Updated string   `xml:"updated"`
+		Entries []entry  `xml:"entry"`
+	}
+
+	doc := atomFeed{
+		NS:    "http://www.w3.org/2005/Atom",
+		ID:    feed.ID,
+		Title: feed.Title,
+		Links: []link{
+			{Href: feed.Link, Rel: "alternate", Type: "text/html"},
+			{Href: feed.FeedURL, Rel: "self", Type: "application/atom+xml"},
+		},
+		Updated: feed.Updated.UTC().Format(time.RFC3339),
+	}
+	for _, it := range feed.Items {
+		e := entry{
+			ID:        it.ID,
+			Title:     it.Title,
+			Links:     []link{{Href: it.Link, Rel: "alternate", Type: "text/html"}},
+			Author:    person{Name: it.AuthorName, URI: it.AuthorURL},
+			Published: it.Published.UTC().Format(time.RFC3339),
+			Updated:   it.Updated.UTC().Format(time.RFC3339),
+			Summary:   text{Type: "text", Value: it.Summary},
+			Content:   text{Type: "html", Value: it.ContentHTML},
+		}
+		for _, tag := range it.Tags {
+			e.Categories = append(e.Categories, category{Term: tag})
+		}
+		doc.Entries = append(doc.Entries, e)
+	}
+
+	return marshalXML(doc)
+}
+
+// JSON renders the feed as JSON Feed 1.1.
+func JSON(feed Feed) ([]byte, error) {
+	type author struct {
+		Name string `json:"name"`
+		URL  string `json:"url,omitempty"`
+	}
+	type item struct {
+		ID            string   `json:"id"`
+		URL           string   `json:"url"`
+		Title         string   `json:"title"`
+		ContentHTML   string   `json:"content_html"`
+		Summary       string   `json:"summary,omitempty"`
+		Image         string   `json:"image,omitempty"`
+		DatePublished string   `json:"date_published"`
+		DateModified  string   `json:"date_modified"`
+		Authors       []author `json:"authors"`
+		Tags          []string `json:"tags,omitempty"`
+	}
+	type jsonFeed struct {
+		Version     string `json:"version"`
+		Title       string `json:"title"`
+		HomePageURL string `json:"home_page_url"`
+		FeedURL     string `json:"feed_url"`
+		Description string `json:"description,omitempty"`
+		Items       []item `json:"items"`
+	}
+
+	doc := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       feed.Title,
+		HomePageURL: feed.Link,
+		FeedURL:     feed.FeedURL,
+		Description: feed.Description,
+		Items:       []item{},
+	}
+	for _, it := range feed.Items {
+		doc.Items = append(doc.Items, item{
+			ID:            it.ID,
+			URL:           it.Link,
+			Title:         it.Title,
+			ContentHTML:   it.ContentHTML,
+			Summary:       it.Summary,
+			Image:         it.Image,
+			DatePublished: it.Published.UTC().Format(time.RFC3339),
+			DateModified:  it.Updated.UTC().Format(time.RFC3339),
+			Authors:       []author{{Name: it.AuthorName, URL: it.AuthorURL}},
+			Tags:          it.Tags,
+		})
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func marshalXML(doc interface{}) ([]byte, error) {
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jasen-devvv/mini-blog-backend/config"
+	"github.com/jasen-devvv/mini-blog-backend/importer"
+)
+
+// runImport runs the "import" command and returns the process exit code:
+//
+//	mini-blog-backend import [flags] wxr <export.xml>
+//	mini-blog-backend import [flags] markdown <directory>
+//
+// It prints the import report as JSON. Running the same import again skips what was
+// already imported, so an interrupted import is resumed by running it again.
+func runImport(args []string) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "report what would be imported without saving anything")
+	author := flags.String("author", "", "username of an existing user owning posts without an author")
+	source := flags.String("source", "", "identifier of the source (defaults to the site URL or directory name)")
+	matchUsers := flags.Bool("match-users", false, "give posts to existing users with the author's username or email")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: import [flags] wxr <export.xml> | markdown <directory>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return 2
+	}
+	format, location := flags.Arg(0), flags.Arg(1)
+
+	// Parse the source into posts
+	var posts []importer.Post
+	var defaultSource string
+	var err error
+	switch format {
+	case "wxr":
+		var file *os.File
+		file, err = os.Open(location)
+		if err == nil {
+			var site string
+			posts, site, err = importer.ParseWXR(file)
+			defaultSource = "wxr:" + site
+			file.Close()
+		}
+	case "markdown":
+		posts, err = importer.ParseMarkdownDir(os.DirFS(location))
+		defaultSource = "markdown:" + filepath.Base(filepath.Clean(location))
+	default:
+		flags.Usage()
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import:", err)
+		return 1
+	}
+	if *source == "" {
+		*source = defaultSource
+	}
+
+	report, err := importer.Run(config.DB, posts, importer.Options{
+		Source:        *source,
+		DryRun:        *dryRun,
+		DefaultAuthor: *author,
+		MatchUsers:    *matchUsers,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import:", err)
+		return 1
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+
+	if report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,498 @@
+// Package importer imports posts, authors, tags and comments from other blogging
+// platforms: WordPress WXR exports and folders of Markdown files with YAML front matter.
+//
+// Parsers turn a source into Posts; Run stores them, and Process runs an import job in
+// the background. Every imported post, comment and author is recorded as a
+// models.ImportItem, so running an import again skips what was already imported:
+// imports are idempotent and resume where a previous run failed.
+package importer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"gorm.io/gorm"
+)
+
+// Post is a post read from an import source.
+type Post struct {
+	ExternalID string
+	Title      string
+	Slug       string
+	Content    string
+	Excerpt    string
+	Published  bool
+	Author     Author
+	Tags       []string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	Comments   []Comment
+	Error      string // set by parsers when the post could not be read
+}
+
+// Author is the author of an imported post or comment.
+type Author struct {
+	Login       string
+	Email       string
+	DisplayName string
+}
+
+// Comment is a comment read from an import source. Comments by registered authors
+// have an Author login; guest comments only have an AuthorName. Comments without an
+// ExternalID are recognized on later imports by their content (see commentKey).
+type Comment struct {
+	ExternalID       string
+	ParentExternalID string
+	Author           Author
+	AuthorName       string
+	Content          string
+	CreatedAt        time.Time
+}
+
+// Options configures an import run.
+//
+// Fields:
+//   - Source: Identifies the source; items are only skipped when re-imported from the same source.
+//   - DryRun: Report what would be imported without saving anything.
+//   - DefaultAuthor: Username of an existing user owning posts without an author.
+//   - MatchUsers: Give posts to existing users with the author's username or email. Off by
+//     default, as nothing proves that an author of the source is the local user of that name.
+type Options struct {
+	Source        string
+	DryRun        bool
+	DefaultAuthor string
+	MatchUsers    bool
+}
+
+// Report summarizes an import run.
+type Report struct {
+	Source       string       `json:"source"`
+	DryRun       bool         `json:"dry_run"`
+	Posts        int          `json:"posts"`
+	Imported     int          `json:"imported"`
+	Skipped      int          `json:"skipped"`
+	Failed       int          `json:"failed"`
+	Comments     int          `json:"comments"`
+	UsersCreated int          `json:"users_created"`
+	Items        []ItemResult `json:"items"`
+}
+
+// ItemResult reports what happened to a single post.
+type ItemResult struct {
+	ExternalID string `json:"external_id"`
+	Title      string `json:"title"`
+	Action     string `json:"action"` // "import", "skip" or "error"
+	ArticleID  uint   `json:"article_id,omitempty"`
+	Comments   int    `json:"comments,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// errDryRun rolls back the transaction of a dry run.
+var errDryRun = errors.New("dry run")
+
+// Run imports posts into the database.
+//
+// Each post is imported in its own transaction together with its comments, so a failing
+// post is reported and skipped without affecting the others. A dry run performs the
+// whole import inside a transaction that is rolled back, so its report is exact.
+// When the context of db is cancelled, Run stops before the next post and returns its error.
+func Run(db *gorm.DB, posts []Post, options Options) (*Report, error) {
+	if options.Source == "" {
+		return nil, errors.New("import source is required")
+	}
+
+	report := &Report{Source: options.Source, DryRun: options.DryRun, Posts: len(posts)}
+	run := func(tx *gorm.DB) error {
+		for _, post := range posts {
+			if err := tx.Statement.Context.Err(); err != nil {
+				return err
+			}
+			report.add(importPost(tx, post, options))
+		}
+		return nil
+	}
+
+	if !options.DryRun {
+		return report, run(db)
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := run(tx); err != nil {
+			return err
+		}
+		return errDryRun
+	})
+	if !errors.Is(err, errDryRun) {
+		return report, err
+	}
+	return report, nil
+}
+
+// add records the result of a post in the report.
+func (r *Report) add(result ItemResult, usersCreated int) {
+	r.Items = append(r.Items, result)
+
+	switch result.Action {
+	case "import":
+		r.Imported++
+	case "skip":
+		r.Skipped++
+	default:
+		r.Failed++
+		return
+	}
+	r.Comments += result.Comments
+	r.UsersCreated += usersCreated
+}
+
+// importPost imports a post and its comments in one transaction (a savepoint during dry runs).
+// It returns the result and the number of users created for it.
+func importPost(db *gorm.DB, post Post, options Options) (ItemResult, int) {
+	result := ItemResult{ExternalID: post.ExternalID, Title: post.Title, Action: "import"}
+	usersCreated := 0
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		usersCreated = 0
+
+		// Skip posts imported by an earlier run
+		var existing models.ImportItem
+		err := tx.Where("source = ? AND kind = ? AND external_id = ?", options.Source, models.ImportKindArticle, post.ExternalID).
+			First(&existing).Error
+		if err == nil {
+			// Still pick up comments added to the source since then
+			result.Action = "skip"
+			result.ArticleID = existing.TargetID
+			comments, users, err := importComments(tx, existing.TargetID, post, options)
+			result.Comments = comments
+			usersCreated += users
+			return err
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if post.Error != "" {
+			return errors.New(post.Error)
+		}
+		if strings.TrimSpace(post.Title) == "" || strings.TrimSpace(post.Content) == "" {
+			return errors.New("post has no title or content")
+		}
+
+		authorID, created, err := resolveAuthor(tx, post.Author, options)
+		if err != nil {
+			return err
+		}
+		if created {
+			usersCreated++
+		}
+
+		tags, err := resolveTags(tx, post.Tags)
+		if err != nil {
+			return err
+		}
+
+		status := models.ArticleStatusDraft
+		if post.Published {
+			status = models.ArticleStatusPublished
+		}
+
+		article := models.Article{
+			Title:     truncate(post.Title, 255),
+			Content:   post.Content,
+			Excerpt:   truncate(post.Excerpt, 500),
+			Status:    status,
+			UserID:    authorID,
+			Tags:      tags,
+			CreatedAt: post.CreatedAt,
+			UpdatedAt: post.UpdatedAt,
+		}
+		if err := createArticle(tx, &article, firstNonEmpty(post.Slug, post.Title)); err != nil {
+			return fmt.Errorf("creating article: %w", err)
+		}
+		if err := recordItem(tx, options.Source, models.ImportKindArticle, post.ExternalID, article.ID); err != nil {
+			return err
+		}
+		result.ArticleID = article.ID
+
+		comments, users, err := importComments(tx, article.ID, post, options)
+		if err != nil {
+			return err
+		}
+		result.Comments = comments
+		usersCreated += users
+		return nil
+	})
+	if err != nil {
+		result.Action = "error"
+		result.Error = err.Error()
+	}
+
+	return result, usersCreated
+}
+
+// importComments imports the comments of a post that were not imported yet into its
+// article, parents before replies. It returns the number of comments and users created.
+func importComments(tx *gorm.DB, articleID uint, post Post, options Options) (int, int, error) {
+	source := options.Source
+	comments := append([]Comment{}, post.Comments...)
+	sort.SliceStable(comments, func(i, j int) bool {
+		return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+	})
+
+	ids := map[string]uint{}
+	imported, usersCreated := 0, 0
+	for _, comment := range comments {
+		if strings.TrimSpace(comment.Content) == "" {
+			continue
+		}
+
+		// Skip comments imported by an earlier run, remembering them as reply parents
+		key := commentKey(post.ExternalID, comment)
+		var existing models.ImportItem
+		err := tx.Where("source = ? AND kind = ? AND external_id = ?", source, models.ImportKindComment, key).
+			First(&existing).Error
+		if err == nil {
+			ids[key] = existing.TargetID
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, 0, err
+		}
+
+		record := models.Comment{
+			Content:    comment.Content,
+			ArticleID:  articleID,
+			AuthorName: truncate(comment.AuthorName, 255),
+			CreatedAt:  comment.CreatedAt,
+			UpdatedAt:  comment.CreatedAt,
+		}
+
+		// Comments by registered authors belong to their user; others keep the guest's name
+		if comment.Author.Login != "" || comment.Author.Email != "" {
+			commentOptions := options
+			commentOptions.DefaultAuthor = ""
+			userID, created, err := resolveAuthor(tx, comment.Author, commentOptions)
+			if err != nil {
+				return 0, 0, err
+			}
+			if created {
+				usersCreated++
+			}
+			record.UserID = &userID
+		}
+		if parentID, ok := ids[comment.ParentExternalID]; ok && comment.ParentExternalID != "" {
+			record.ParentID = &parentID
+		}
+
+		if err := tx.Create(&record).Error; err != nil {
+			return 0, 0, fmt.Errorf("creating comment %s: %w", key, err)
+		}
+		if err := recordItem(tx, source, models.ImportKindComment, key, record.ID); err != nil {
+			return 0, 0, err
+		}
+		ids[key] = record.ID
+		imported++
+	}
+
+	return imported, usersCreated, nil
+}
+
+// commentKey returns the external ID a comment is recorded under: its own, or for sources
+// without comment IDs a hash of the post's external ID and the comment's author, date and
+// content, which stays the same when the source is imported again. Identical comments by
+// the same author at the same time count as one.
+func commentKey(postExternalID string, comment Comment) string {
+	if comment.ExternalID != "" {
+		return comment.ExternalID
+	}
+	hash := sha256.New()
+	for _, part := range []string{
+		postExternalID, comment.Author.Login, comment.Author.Email, comment.AuthorName,
+		comment.CreatedAt.UTC().Format(time.RFC3339Nano), comment.Content,
+	} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// resolveAuthor returns the ID of the user an author was imported as from the same
+// source, recorded as an author ImportItem. Otherwise, it matches an existing user by
+// username or email when options.MatchUsers is set, or creates a user. Created users get
+// an unusable password and must reset it before logging in; their username gets a numeric
+// suffix when it is taken, and their email is left out when another user has it. Posts
+// without an author go to the default author.
+func resolveAuthor(tx *gorm.DB, author Author, options Options) (uint, bool, error) {
+	if author.Login == "" && author.Email == "" {
+		if options.DefaultAuthor == "" {
+			return 0, false, errors.New("post has no author and no default author is set")
+		}
+		var user models.User
+		if err := tx.Where("username = ?", options.DefaultAuthor).First(&user).Error; err != nil {
+			return 0, false, fmt.Errorf("default author %q: %w", options.DefaultAuthor, err)
+		}
+		return user.ID, false, nil
+	}
+
+	// Reuse the user the author was imported as
+	externalID := author.Login
+	if externalID == "" {
+		externalID = "email:" + strings.ToLower(author.Email)
+	}
+	var item models.ImportItem
+	err := tx.Where("source = ? AND kind = ? AND external_id = ?", options.Source, models.ImportKindAuthor, externalID).
+		First(&item).Error
+	if err == nil {
+		return item.TargetID, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, false, err
+	}
+
+	var user models.User
+	created := false
+	if options.MatchUsers {
+		query := tx.Where("username = ?", author.Login)
+		if author.Email != "" {
+			query = query.Or("email = ?", author.Email)
+		}
+		err = query.First(&user).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, false, err
+		}
+	}
+	if user.ID == 0 {
+		if user, err = createAuthor(tx, author); err != nil {
+			return 0, false, err
+		}
+		created = true
+	}
+
+	if err := recordItem(tx, options.Source, models.ImportKindAuthor, truncate(externalID, 500), user.ID); err != nil {
+		return 0, false, err
+	}
+	return user.ID, created, nil
+}
+
+// createAuthor creates a user for an imported author, under a username and email no
+// other user has.
+func createAuthor(tx *gorm.DB, author Author) (models.User, error) {
+	base := truncate(firstNonEmpty(author.Login, strings.Split(author.Email, "@")[0]), 90)
+	username := base
+	for i := 2; ; i++ {
+		var count int64
+		if err := tx.Model(&models.User{}).Where("username = ?", username).Count(&count).Error; err != nil {
+			return models.User{}, err
+		}
+		if count == 0 {
+			break
+		}
+		if i > 100 {
+			return models.User{}, fmt.Errorf("no free username for author %q", base)
+		}
+		username = fmt.Sprintf("%s-%d", base, i)
+	}
+
+	email := username + "@imported.invalid"
+	if author.Email != "" {
+		var count int64
+		if err := tx.Model(&models.User{}).Where("email = ?", author.Email).Count(&count).Error; err != nil {
+			return models.User{}, err
+		}
+		if count == 0 {
+			email = author.Email
+		}
+	}
+
+	user := models.User{
+		Username:    username,
+		Email:       email,
+		Password:    "!", // never matches a bcrypt hash
+		DisplayName: truncate(author.DisplayName, 100),
+	}
+	if err := tx.Create(&user).Error; err != nil {
+		return models.User{}, fmt.Errorf("creating user %q: %w", username, err)
+	}
+	return user, nil
+}
+
+// slugAttempts is how many slugs createArticle tries when concurrent requests take the
+// ones it picked.
+const slugAttempts = 5
+
+// createArticle creates article under a slug for text no other article has. A request
+// can take the slug between the check and the insert, so the insert runs in a savepoint:
+// when it hits the unique index, the import's transaction survives and the next free
+// slug is tried.
+func createArticle(tx *gorm.DB, article *models.Article, text string) error {
+	for attempt := 1; ; attempt++ {
+		slug, err := models.UniqueArticleSlug(tx, text)
+		if err != nil {
+			return err
+		}
+		article.Slug = slug
+
+		err = tx.Transaction(func(tx *gorm.DB) error { return tx.Create(article).Error })
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_articles_slug" && attempt < slugAttempts {
+			continue
+		}
+		return err
+	}
+}
+
+// resolveTags returns the tags with the given names, creating missing ones.
+func resolveTags(tx *gorm.DB, names []string) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	seen := map[string]bool{}
+
+	for _, name := range names {
+		name = models.NormalizeTagName(name)
+		if name == "" || utf8.RuneCountInString(name) > 50 || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		tag := models.Tag{Name: name}
+		if err := tx.Where(models.Tag{Name: name}).FirstOrCreate(&tag).Error; err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+// recordItem records an imported item.
+func recordItem(tx *gorm.DB, source, kind, externalID string, targetID uint) error {
+	if externalID == "" {
+		return nil
+	}
+	return tx.Create(&models.ImportItem{Source: source, Kind: kind, ExternalID: externalID, TargetID: targetID}).Error
+}
+
+// truncate shortens a string to at most max characters, the unit of the column limits,
+// cutting between characters.
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max])
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,363 @@
+package importer_test
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jasen-devvv/mini-blog-backend/importer"
+	"github.com/jasen-devvv/mini-blog-backend/internal/testutil"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(testutil.RunWithDatabase(m))
+}
+
+// parseWXR parses testdata/export.xml.
+func parseWXR(t *testing.T) ([]importer.Post, string) {
+	t.Helper()
+	file, err := os.Open("testdata/export.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	posts, site, err := importer.ParseWXR(file)
+	if err != nil {
+		t.Fatalf("parsing: %v", err)
+	}
+	return posts, site
+}
+
+func TestParseWXR(t *testing.T) {
+	posts, site := parseWXR(t)
+	if site != "https://old.example" {
+		t.Errorf("site = %q, want the channel link without a trailing slash", site)
+	}
+
+	jane := importer.Author{Login: "jane", Email: "jane@old.example", DisplayName: "Jane Doe"}
+	joe := importer.Author{Login: "joe", Email: "joe@old.example", DisplayName: "Joe"}
+	want := []importer.Post{
+		{
+			ExternalID: "https://old.example/?p=1",
+			Title:      "Hello World",
+			Slug:       "hello-world",
+			Content:    "<p>First paragraph<br>\nwith a line break.</p>\n<ul><li>Already HTML</li></ul>",
+			Excerpt:    "A greeting",
+			Published:  true,
+			Author:     jane,
+			Tags:       []string{"News", "Go"},
+			CreatedAt:  time.Date(2020, 1, 2, 10, 4, 5, 0, time.UTC),
+			UpdatedAt:  time.Date(2020, 2, 3, 10, 0, 0, 0, time.UTC),
+			Comments: []importer.Comment{
+				// Unapproved comments and pingbacks are left out
+				{ExternalID: "comment-10", AuthorName: "Guest", Content: "Nice post", CreatedAt: time.Date(2020, 1, 3, 9, 0, 0, 0, time.UTC)},
+				{ExternalID: "comment-11", ParentExternalID: "comment-10", Author: joe, AuthorName: "Joe", Content: "Thanks!", CreatedAt: time.Date(2020, 1, 3, 10, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			// Drafts without a GUID or GMT date fall back to the post ID and local date
+			ExternalID: "post-2",
+			Title:      "Unfinished",
+			Content:    "<p>Draft body</p>",
+			Author:     joe,
+			CreatedAt:  time.Date(2021, 5, 6, 7, 8, 9, 0, time.UTC),
+			UpdatedAt:  time.Date(2021, 5, 6, 7, 8, 9, 0, time.UTC),
+		},
+	}
+
+	// Pages and trashed posts are left out
+	if len(posts) != len(want) {
+		t.Fatalf("got %d posts, want %d: %+v", len(posts), len(want), posts)
+	}
+	for i := range want {
+		t.Run(want[i].Title, func(t *testing.T) {
+			if !reflect.DeepEqual(posts[i], want[i]) {
+				t.Errorf("post =\n%+v\nwant\n%+v", posts[i], want[i])
+			}
+		})
+	}
+}
+
+func TestParseWXRInvalid(t *testing.T) {
+	if _, _, err := importer.ParseWXR(strings.NewReader("<rss><channel><item>")); err == nil {
+		t.Error("parsing a truncated export succeeded")
+	}
+}
+
+func TestParseMarkdownDir(t *testing.T) {
+	posts, err := importer.ParseMarkdownDir(os.DirFS("testdata/markdown"))
+	if err != nil {
+		t.Fatalf("parsing: %v", err)
+	}
+
+	want := []importer.Post{
+		{
+			ExternalID: "2024-01-31-hello-markdown.md",
+			Title:      "Hello Markdown",
+			Slug:       "hello-markdown",
+			Content:    "# Hello\n\nBody text.",
+			Excerpt:    "A first post",
+			Published:  true,
+			Author:     importer.Author{Login: "jane"},
+			Tags:       []string{"go", "web", "notes"},
+			CreatedAt:  time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC),
+			UpdatedAt:  time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{ExternalID: "notes/broken.md", Error: "missing front matter"},
+		{
+			ExternalID: "notes/draft.markdown",
+			Title:      "Draft Note",
+			Slug:       "custom-slug",
+			Content:    "Not ready yet.",
+			CreatedAt:  time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC),
+			UpdatedAt:  time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC),
+		},
+	}
+
+	// Files other than Markdown are ignored
+	if len(posts) != len(want) {
+		t.Fatalf("got %d posts, want %d: %+v", len(posts), len(want), posts)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(posts[i], want[i]) {
+			t.Errorf("post %s =\n%+v\nwant\n%+v", want[i].ExternalID, posts[i], want[i])
+		}
+	}
+}
+
+func TestFrontMatter(t *testing.T) {
+	date := time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		content string
+		want    importer.Post
+	}{
+		{
+			name:    "minimal",
+			content: "---\ntitle: Post\n---\nBody\n",
+			want:    importer.Post{Title: "Post", Slug: "post", Content: "Body", Published: true},
+		},
+		{
+			name:    "windows line endings",
+			content: "---\r\ntitle: Post\r\ndate: 2024-01-31T10:00:00Z\r\n---\r\nBody\r\n",
+			want:    importer.Post{Title: "Post", Slug: "post", Content: "Body", Published: true, CreatedAt: date, UpdatedAt: date},
+		},
+		{
+			name:    "published overrides draft",
+			content: "---\ntitle: Post\ndraft: true\npublished: true\n---\nBody",
+			want:    importer.Post{Title: "Post", Slug: "post", Content: "Body", Published: true},
+		},
+		{
+			name:    "unpublished",
+			content: "---\ntitle: Post\npublished: false\n---\nBody",
+			want:    importer.Post{Title: "Post", Slug: "post", Content: "Body"},
+		},
+		{
+			name:    "updated date",
+			content: "---\ntitle: Post\ndate: 2024-01-31T10:00:00Z\nupdated: 2024-02-01T10:00:00Z\n---\nBody",
+			want:    importer.Post{Title: "Post", Slug: "post", Content: "Body", Published: true, CreatedAt: date, UpdatedAt: date.Add(24 * time.Hour)},
+		},
+		{
+			name:    "description before summary",
+			content: "---\ntitle: Post\nsummary: Summary\ndescription: Description\n---\nBody",
+			want:    importer.Post{Title: "Post", Slug: "post", Content: "Body", Excerpt: "Description", Published: true},
+		},
+		{
+			name:    "no body",
+			content: "---\ntitle: Post\n---",
+			want:    importer.Post{Title: "Post", Slug: "post", Published: true},
+		},
+		{
+			name:    "missing front matter",
+			content: "title: Post\n\nBody",
+			want:    importer.Post{Error: "missing front matter"},
+		},
+		{
+			name:    "unterminated front matter",
+			content: "---\ntitle: Post\nBody",
+			want:    importer.Post{Error: "unterminated front matter"},
+		},
+		{
+			name:    "invalid front matter",
+			content: "---\ntitle: [Post\n---\nBody",
+			want:    importer.Post{Error: "invalid front matter"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posts, err := importer.ParseMarkdownDir(fstest.MapFS{"post.md": {Data: []byte(tt.content)}})
+			if err != nil {
+				t.Fatalf("parsing: %v", err)
+			}
+			if len(posts) != 1 {
+				t.Fatalf("got %d posts, want 1", len(posts))
+			}
+
+			post := posts[0]
+			tt.want.ExternalID = "post.md"
+			if tt.want.Error != "" {
+				if !strings.HasPrefix(post.Error, tt.want.Error) {
+					t.Errorf("error = %q, want %q", post.Error, tt.want.Error)
+				}
+				return
+			}
+			if !reflect.DeepEqual(post, tt.want) {
+				t.Errorf("post =\n%+v\nwant\n%+v", post, tt.want)
+			}
+		})
+	}
+}
+
+// count returns the number of rows of model.
+func count(t *testing.T, db *gorm.DB, model interface{}) int64 {
+	t.Helper()
+	var n int64
+	if err := db.Model(model).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+// run imports posts and fails the test on errors.
+func run(t *testing.T, db *gorm.DB, posts []importer.Post, options importer.Options) *importer.Report {
+	t.Helper()
+	report, err := importer.Run(db, posts, options)
+	if err != nil {
+		t.Fatalf("importing: %v", err)
+	}
+	return report
+}
+
+func TestRunIdempotent(t *testing.T) {
+	db := testutil.Database(t)
+	posts, site := parseWXR(t)
+	options := importer.Options{Source: site}
+
+	// A dry run reports the import without saving it
+	report := run(t, db, posts, importer.Options{Source: site, DryRun: true})
+	if report.Imported != 2 || report.Comments != 2 || report.UsersCreated != 2 {
+		t.Errorf("dry run report = %+v", report)
+	}
+	if n := count(t, db, &models.Article{}); n != 0 {
+		t.Fatalf("dry run saved %d articles", n)
+	}
+
+	report = run(t, db, posts, options)
+	if report.Imported != 2 || report.Failed != 0 || report.Comments != 2 || report.UsersCreated != 2 {
+		t.Fatalf("report = %+v", report)
+	}
+
+	var reply models.Comment
+	if err := db.Where("content = ?", "Thanks!").First(&reply).Error; err != nil {
+		t.Fatal(err)
+	}
+	if reply.ParentID == nil || reply.UserID == nil {
+		t.Errorf("reply = %+v, want a parent and joe as its author", reply)
+	}
+
+	// Importing again changes nothing
+	report = run(t, db, posts, options)
+	if report.Imported != 0 || report.Skipped != 2 || report.Comments != 0 || report.UsersCreated != 0 {
+		t.Errorf("second report = %+v", report)
+	}
+	for model, want := range map[interface{}]int64{&models.Article{}: 2, &models.Comment{}: 2, &models.User{}: 2} {
+		if n := count(t, db, model); n != want {
+			t.Errorf("%T: %d rows after importing twice, want %d", model, n, want)
+		}
+	}
+}
+
+func TestRunCommentsWithoutIDs(t *testing.T) {
+	db := testutil.Database(t)
+	options := importer.Options{Source: "markdown"}
+	created := time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)
+
+	post := importer.Post{
+		ExternalID: "post.md",
+		Title:      "Post",
+		Content:    "Body",
+		Author:     importer.Author{Login: "jane"},
+		Comments: []importer.Comment{
+			{AuthorName: "Guest", Content: "First", CreatedAt: created},
+			{AuthorName: "Guest", Content: "Second", CreatedAt: created.Add(time.Hour)},
+		},
+	}
+	if report := run(t, db, []importer.Post{post}, options); report.Comments != 2 {
+		t.Fatalf("report = %+v, want 2 comments", report)
+	}
+
+	// Comments without IDs are recognized by their content, and new ones are picked up
+	post.Comments = append(post.Comments, importer.Comment{AuthorName: "Guest", Content: "Third", CreatedAt: created.Add(2 * time.Hour)})
+	if report := run(t, db, []importer.Post{post}, options); report.Skipped != 1 || report.Comments != 1 {
+		t.Errorf("report = %+v, want only the new comment imported", report)
+	}
+	if n := count(t, db, &models.Comment{}); n != 3 {
+		t.Errorf("%d comments, want 3", n)
+	}
+
+	// None of them is a reply
+	var replies int64
+	if err := db.Model(&models.Comment{}).Where("parent_id IS NOT NULL").Count(&replies).Error; err != nil {
+		t.Fatal(err)
+	}
+	if replies != 0 {
+		t.Errorf("%d comments without a parent got one", replies)
+	}
+}
+
+func TestRunResume(t *testing.T) {
+	db := testutil.Database(t)
+	posts, site := parseWXR(t)
+	options := importer.Options{Source: site}
+
+	// A run that stopped after the first post, and a post that failed
+	run(t, db, posts[:1], options)
+	failing := posts[1]
+	failing.Error = "could not read the post"
+	if report := run(t, db, []importer.Post{failing}, options); report.Failed != 1 {
+		t.Fatalf("report = %+v, want the post failed", report)
+	}
+
+	// The next run imports what is missing
+	report := run(t, db, posts, options)
+	if report.Skipped != 1 || report.Imported != 1 || report.Failed != 0 {
+		t.Errorf("report = %+v, want the first post skipped and the second imported", report)
+	}
+	if n := count(t, db, &models.Article{}); n != 2 {
+		t.Errorf("%d articles, want 2", n)
+	}
+}
+
+func TestRunTruncatesCharacters(t *testing.T) {
+	db := testutil.Database(t)
+	post := importer.Post{
+		ExternalID: "long.md",
+		Title:      strings.Repeat("é", 300),
+		Content:    "Body",
+		Excerpt:    strings.Repeat("日本", 300),
+		Author:     importer.Author{Login: "jane"},
+	}
+	if report := run(t, db, []importer.Post{post}, importer.Options{Source: "markdown"}); report.Imported != 1 {
+		t.Fatalf("report = %+v", report)
+	}
+
+	var article models.Article
+	if err := db.First(&article).Error; err != nil {
+		t.Fatal(err)
+	}
+	if utf8.RuneCountInString(article.Title) != 255 || utf8.RuneCountInString(article.Excerpt) != 500 {
+		t.Errorf("title and excerpt have %d and %d characters, want 255 and 500",
+			utf8.RuneCountInString(article.Title), utf8.RuneCountInString(article.Excerpt))
+	}
+	if !utf8.ValidString(article.Title) || !utf8.ValidString(article.Excerpt) {
+		t.Error("truncation split a character")
+	}
+}
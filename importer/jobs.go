@@ -0,0 +1,55 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"gorm.io/gorm"
+)
+
+// Process runs an import job: it imports the posts with Run and marks the job completed
+// with its report, or failed with the reason. Cancelling ctx stops the import between
+// posts and fails the job; posts imported until then are kept, so uploading the file
+// again resumes the import.
+func Process(ctx context.Context, db *gorm.DB, jobID uint, posts []Post, options Options) {
+	db = db.WithContext(ctx)
+
+	var job models.ImportJob
+	if err := db.First(&job, jobID).Error; err != nil {
+		slog.Error("failed to load import job", "job_id", jobID, "error", err)
+		return
+	}
+
+	// Claim the job, so it only runs once
+	result := db.Model(&job).Where("status = ?", models.ImportStatusPending).Update("status", models.ImportStatusRunning)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return
+	}
+
+	report, err := Run(db, posts, options)
+	if err == nil {
+		err = ctx.Err()
+	}
+	updates := map[string]interface{}{
+		"status":       models.ImportStatusCompleted,
+		"completed_at": time.Now(),
+	}
+	if err != nil {
+		slog.Error("failed to import", "job_id", job.ID, "error", err)
+		updates["status"] = models.ImportStatusFailed
+		updates["error"] = truncate(err.Error(), 500)
+	}
+	if report != nil {
+		if encoded, err := json.Marshal(report); err == nil {
+			updates["report"] = json.RawMessage(encoded)
+		}
+	}
+
+	// Record the outcome even when ctx was cancelled
+	if err := db.WithContext(context.WithoutCancel(ctx)).Model(&job).Updates(updates).Error; err != nil {
+		slog.Error("failed to complete import job", "job_id", job.ID, "error", err)
+	}
+}
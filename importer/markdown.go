@@ -0,0 +1,123 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// frontMatter is the YAML front matter of a Markdown post, covering the fields used by
+// common static site generators (Hugo, Jekyll, Eleventy).
+type frontMatter struct {
+	Title       string    `yaml:"title"`
+	Slug        string    `yaml:"slug"`
+	Date        time.Time `yaml:"date"`
+	Updated     time.Time `yaml:"updated"`
+	LastMod     time.Time `yaml:"lastmod"`
+	Draft       bool      `yaml:"draft"`
+	Published   *bool     `yaml:"published"`
+	Author      string    `yaml:"author"`
+	Tags        []string  `yaml:"tags"`
+	Categories  []string  `yaml:"categories"`
+	Description string    `yaml:"description"`
+	Summary     string    `yaml:"summary"`
+	Excerpt     string    `yaml:"excerpt"`
+}
+
+// datePrefix matches the date prefix of Jekyll post file names ("2024-01-31-title.md").
+var datePrefix = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}-`)
+
+// ParseMarkdownDir reads every .md and .markdown file under a directory.
+//
+// Each file must start with YAML front matter between "---" lines. The slug defaults to
+// the file name (without a Jekyll date prefix), and the external ID is the file's path
+// within the directory, so the folder can be moved between imports. Files that cannot be
+// parsed are returned with their Error set, so they show up as failures in the report
+// instead of aborting the import. The Markdown body is stored as the article content.
+func ParseMarkdownDir(fsys fs.FS) ([]Post, error) {
+	posts := []Post{}
+
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		ext := strings.ToLower(path.Ext(name))
+		if entry.IsDir() || (ext != ".md" && ext != ".markdown") {
+			return nil
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		post, err := parseMarkdownPost(name, data)
+		if err != nil {
+			// Report the file as a failed post without stopping the import
+			post = Post{ExternalID: name, Error: err.Error()}
+		}
+		posts = append(posts, post)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// parseMarkdownPost parses a Markdown file with YAML front matter.
+func parseMarkdownPost(name string, data []byte) (Post, error) {
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	if !bytes.HasPrefix(data, []byte("---\n")) {
+		return Post{}, fmt.Errorf("missing front matter")
+	}
+
+	header, body, found := bytes.Cut(data[4:], []byte("\n---"))
+	if !found {
+		return Post{}, fmt.Errorf("unterminated front matter")
+	}
+	// Drop the rest of the closing "---" line
+	if i := bytes.IndexByte(body, '\n'); i >= 0 {
+		body = body[i+1:]
+	} else {
+		body = nil
+	}
+
+	var meta frontMatter
+	if err := yaml.Unmarshal(header, &meta); err != nil {
+		return Post{}, fmt.Errorf("invalid front matter: %w", err)
+	}
+
+	base := strings.TrimSuffix(path.Base(name), path.Ext(name))
+	post := Post{
+		ExternalID: name,
+		Title:      meta.Title,
+		Slug:       firstNonEmpty(meta.Slug, datePrefix.ReplaceAllString(base, "")),
+		Content:    strings.TrimSpace(string(body)),
+		Excerpt:    firstNonEmpty(meta.Description, meta.Summary, meta.Excerpt),
+		Published:  !meta.Draft,
+		Tags:       append(meta.Tags, meta.Categories...),
+		CreatedAt:  meta.Date,
+		UpdatedAt:  meta.Date,
+	}
+	if meta.Published != nil {
+		post.Published = *meta.Published
+	}
+	if meta.Author != "" {
+		post.Author = Author{Login: meta.Author}
+	}
+	for _, updated := range []time.Time{meta.Updated, meta.LastMod} {
+		if !updated.IsZero() {
+			post.UpdatedAt = updated
+		}
+	}
+
+	return post, nil
+}
@@ -0,0 +1,189 @@
+package importer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// wxrTimeLayout is the layout of dates in WordPress exports.
+const wxrTimeLayout = "2006-01-02 15:04:05"
+
+// WXR document structure. Elements are matched by local name, so exports from every
+// WXR version (whose "wp" namespace URL differs) are understood.
+type wxrDocument struct {
+	Channel struct {
+		Links   []string    `xml:"link"`
+		Authors []wxrAuthor `xml:"author"`
+		Items   []wxrItem   `xml:"item"`
+	} `xml:"channel"`
+}
+
+type wxrAuthor struct {
+	ID          string `xml:"author_id"`
+	Login       string `xml:"author_login"`
+	Email       string `xml:"author_email"`
+	DisplayName string `xml:"author_display_name"`
+}
+
+type wxrItem struct {
+	Title        string        `xml:"title"`
+	GUID         string        `xml:"guid"`
+	Creator      string        `xml:"creator"`
+	Encoded      []wxrEncoded  `xml:"encoded"`
+	PostID       string        `xml:"post_id"`
+	PostDate     string        `xml:"post_date"`
+	PostDateGMT  string        `xml:"post_date_gmt"`
+	ModifiedGMT  string        `xml:"post_modified_gmt"`
+	PostName     string        `xml:"post_name"`
+	Status       string        `xml:"status"`
+	PostType     string        `xml:"post_type"`
+	Categories   []wxrCategory `xml:"category"`
+	ItemComments []wxrComment  `xml:"comment"`
+}
+
+// wxrEncoded is a content:encoded or excerpt:encoded element, told apart by namespace.
+type wxrEncoded struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type wxrCategory struct {
+	Domain string `xml:"domain,attr"`
+	Name   string `xml:",chardata"`
+}
+
+type wxrComment struct {
+	ID          string `xml:"comment_id"`
+	Author      string `xml:"comment_author"`
+	AuthorEmail string `xml:"comment_author_email"`
+	DateGMT     string `xml:"comment_date_gmt"`
+	Content     string `xml:"comment_content"`
+	Approved    string `xml:"comment_approved"`
+	Type        string `xml:"comment_type"`
+	Parent      string `xml:"comment_parent"`
+	UserID      string `xml:"comment_user_id"`
+}
+
+// ParseWXR reads a WordPress eXtended RSS export.
+//
+// Only posts are imported (not pages, attachments or menu items). Trashed posts and
+// unapproved comments, pingbacks and trackbacks are left out. Categories and tags both
+// become tags. It also returns the site URL of the export, which identifies the source.
+func ParseWXR(r io.Reader) ([]Post, string, error) {
+	var doc wxrDocument
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, "", fmt.Errorf("parsing WXR: %w", err)
+	}
+
+	authors := map[string]wxrAuthor{}
+	authorsByID := map[string]wxrAuthor{}
+	for _, author := range doc.Channel.Authors {
+		authors[author.Login] = author
+		authorsByID[author.ID] = author
+	}
+
+	posts := []Post{}
+	for _, item := range doc.Channel.Items {
+		if item.PostType != "post" || item.Status == "trash" || item.Status == "auto-draft" {
+			continue
+		}
+
+		post := Post{
+			ExternalID: firstNonEmpty(item.GUID, "post-"+item.PostID),
+			Title:      strings.TrimSpace(item.Title),
+			Slug:       item.PostName,
+			Published:  item.Status == "publish",
+			Author:     wxrPostAuthor(authors, item.Creator),
+			CreatedAt:  wxrTime(item.PostDateGMT, item.PostDate),
+		}
+		post.UpdatedAt = wxrTime(item.ModifiedGMT, "")
+		if post.UpdatedAt.IsZero() {
+			post.UpdatedAt = post.CreatedAt
+		}
+
+		for _, encoded := range item.Encoded {
+			if strings.Contains(encoded.XMLName.Space, "excerpt") {
+				post.Excerpt = strings.TrimSpace(encoded.Value)
+			} else {
+				post.Content = autop(encoded.Value)
+			}
+		}
+
+		for _, category := range item.Categories {
+			if category.Domain == "post_tag" || category.Domain == "category" {
+				post.Tags = append(post.Tags, category.Name)
+			}
+		}
+
+		for _, comment := range item.ItemComments {
+			if comment.Approved != "1" || comment.Type == "pingback" || comment.Type == "trackback" {
+				continue
+			}
+
+			imported := Comment{
+				ExternalID: "comment-" + comment.ID,
+				AuthorName: comment.Author,
+				Content:    strings.TrimSpace(comment.Content),
+				CreatedAt:  wxrTime(comment.DateGMT, ""),
+			}
+			if comment.Parent != "" && comment.Parent != "0" {
+				imported.ParentExternalID = "comment-" + comment.Parent
+			}
+			if author, ok := authorsByID[comment.UserID]; ok && comment.UserID != "0" {
+				imported.Author = Author{Login: author.Login, Email: author.Email, DisplayName: author.DisplayName}
+			}
+			post.Comments = append(post.Comments, imported)
+		}
+
+		posts = append(posts, post)
+	}
+
+	return posts, strings.TrimRight(firstNonEmpty(doc.Channel.Links...), "/"), nil
+}
+
+// wxrPostAuthor returns the author of a post from its dc:creator login.
+func wxrPostAuthor(authors map[string]wxrAuthor, login string) Author {
+	if author, ok := authors[login]; ok {
+		return Author{Login: author.Login, Email: author.Email, DisplayName: author.DisplayName}
+	}
+	return Author{Login: login}
+}
+
+// wxrTime parses a GMT date from a WordPress export, falling back to the local date
+// (drafts have a zeroed GMT date). It returns the zero time when neither parses.
+func wxrTime(gmt, local string) time.Time {
+	for _, value := range []string{gmt, local} {
+		if t, err := time.Parse(wxrTimeLayout, strings.TrimSpace(value)); err == nil && t.Year() > 1 {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// blockTagPattern matches content starting with a block-level HTML element.
+var blockTagPattern = regexp.MustCompile(`(?i)^<(p|div|h[1-6]|ul|ol|li|blockquote|pre|table|figure|hr|!--)[\s>/]`)
+
+// autop converts WordPress content, where blank lines separate paragraphs, to HTML
+// paragraphs, leaving blocks that already are HTML block elements untouched.
+func autop(content string) string {
+	content = strings.ReplaceAll(strings.TrimSpace(content), "\r\n", "\n")
+
+	var blocks []string
+	for _, block := range strings.Split(content, "\n\n") {
+		block = strings.TrimSpace(block)
+		if block == "" {
+			continue
+		}
+		if !blockTagPattern.MatchString(block) {
+			block = "<p>" + strings.ReplaceAll(block, "\n", "<br>\n") + "</p>"
+		}
+		blocks = append(blocks, block)
+	}
+	return strings.Join(blocks, "\n")
+}
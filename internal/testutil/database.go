@@ -0,0 +1,179 @@
+package testutil
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jasen-devvv/mini-blog-backend/migrations"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Tests needing Postgres use the server, in order:
+//
+//   - at TEST_DB_URL, where every test gets its own schema that is dropped afterwards;
+//   - of a throwaway cluster started with initdb and pg_ctl, found on the PATH or in
+//     TEST_POSTGRES_BIN, and removed when the tests end.
+//
+// Without either, the tests are skipped, unless the CI variable is set: a CI run must
+// not pass while running none of them.
+var (
+	serverURL  string
+	skipReason string
+)
+
+// RunWithDatabase runs the tests of m with a Postgres server for Database, and returns
+// the exit code. It is called from TestMain.
+func RunWithDatabase(m *testing.M) int {
+	serverURL = os.Getenv("TEST_DB_URL")
+	if serverURL != "" {
+		return m.Run()
+	}
+
+	address, stop, err := startPostgres()
+	if err != nil {
+		skipReason = fmt.Sprintf("no database for integration tests: set TEST_DB_URL or install Postgres (%v)", err)
+		if os.Getenv("CI") != "" {
+			fmt.Fprintln(os.Stderr, "CI is set but there is", skipReason)
+			return 1
+		}
+		return m.Run()
+	}
+	defer stop()
+	serverURL = address
+	return m.Run()
+}
+
+// startPostgres starts a Postgres cluster in a temporary directory and returns its URL
+// and a function stopping and removing it.
+func startPostgres() (string, func(), error) {
+	bin := func(name string) (string, error) {
+		if dir := os.Getenv("TEST_POSTGRES_BIN"); dir != "" {
+			return filepath.Join(dir, name), nil
+		}
+		return exec.LookPath(name)
+	}
+	initdb, err := bin("initdb")
+	if err != nil {
+		return "", nil, err
+	}
+	pgCtl, err := bin("pg_ctl")
+	if err != nil {
+		return "", nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "mini-blog-postgres")
+	if err != nil {
+		return "", nil, err
+	}
+	data := filepath.Join(dir, "data")
+
+	// Reserve a free port for the server
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", nil, err
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	commands := [][]string{
+		{initdb, "-D", data, "-U", "postgres", "-A", "trust", "-E", "UTF8", "--no-sync"},
+		{pgCtl, "-D", data, "-l", filepath.Join(dir, "postgres.log"), "-w", "start",
+			"-o", fmt.Sprintf("-p %d -k %s -c listen_addresses=127.0.0.1 -F", port, dir)},
+	}
+	for _, args := range commands {
+		if output, err := exec.Command(args[0], args[1:]...).CombinedOutput(); err != nil {
+			os.RemoveAll(dir)
+			return "", nil, fmt.Errorf("%s: %v: %s", filepath.Base(args[0]), err, strings.TrimSpace(string(output)))
+		}
+	}
+
+	stop := func() {
+		exec.Command(pgCtl, "-D", data, "-m", "immediate", "stop").Run()
+		os.RemoveAll(dir)
+	}
+	return fmt.Sprintf("postgres://postgres@127.0.0.1:%d/postgres?sslmode=disable", port), stop, nil
+}
+
+// withSearchPath returns the connection string databaseURL with its search path set to
+// schema. Both URLs and key=value connection strings are supported.
+func withSearchPath(databaseURL, schema string) (string, error) {
+	if !strings.Contains(databaseURL, "://") {
+		return databaseURL + " search_path=" + schema, nil
+	}
+	u, err := url.Parse(databaseURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// EmptyDatabase returns a connection to a new, empty schema, which is dropped when the
+// test ends. The test is skipped when RunWithDatabase found no server.
+func EmptyDatabase(t *testing.T) *gorm.DB {
+	t.Helper()
+	if serverURL == "" {
+		t.Skip(skipReason)
+	}
+	quiet := &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+
+	suffix := make([]byte, 6)
+	rand.Read(suffix)
+	schema := "test_" + hex.EncodeToString(suffix)
+
+	admin, err := gorm.Open(postgres.Open(serverURL), quiet)
+	if err != nil {
+		t.Fatalf("connecting to the test database: %v", err)
+	}
+	adminDB, _ := admin.DB()
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		adminDB.Close()
+		t.Fatalf("creating schema %s: %v", schema, err)
+	}
+	t.Cleanup(func() {
+		if err := admin.Exec("DROP SCHEMA " + schema + " CASCADE").Error; err != nil {
+			t.Errorf("dropping schema %s: %v", schema, err)
+		}
+		adminDB.Close()
+	})
+
+	dsn, err := withSearchPath(serverURL, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := gorm.Open(postgres.Open(dsn), quiet)
+	if err != nil {
+		t.Fatalf("connecting to schema %s: %v", schema, err)
+	}
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// Database returns a connection to a new schema migrated to the latest version, which
+// is dropped when the test ends. The test is skipped when RunWithDatabase found no
+// server.
+func Database(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db := EmptyDatabase(t)
+	sqlDB, _ := db.DB()
+	if _, err := migrations.Up(context.Background(), sqlDB); err != nil {
+		t.Fatalf("migrating the test schema: %v", err)
+	}
+	return db
+}
@@ -0,0 +1,58 @@
+// Package testutil provides helpers shared by the tests of several packages: requests
+// against a router, a Postgres database per test and golden files.
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Do sends a request to handler, authenticated when token is set. A string body is sent
+// as is, anything else as JSON. Extra headers are given as name, value pairs.
+func Do(t testing.TB, handler http.Handler, method, path, token string, body interface{}, headers ...string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var data []byte
+	switch body := body.(type) {
+	case nil:
+	case string:
+		data = []byte(body)
+	default:
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+// Decode unmarshals the JSON body of w into target.
+func Decode(t testing.TB, w *httptest.ResponseRecorder, target interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), target); err != nil {
+		t.Fatalf("decoding %s: %v", w.Body, err)
+	}
+}
+
+// ExpectStatus stops the test unless w has the given status.
+func ExpectStatus(t testing.TB, w *httptest.ResponseRecorder, status int) {
+	t.Helper()
+	if w.Code != status {
+		t.Fatalf("status = %d, want %d: %s", w.Code, status, w.Body)
+	}
+}
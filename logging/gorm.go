@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// SlowQueryThreshold is the duration above which queries are logged as slow.
+const SlowQueryThreshold = 200 * time.Millisecond
+
+// unfilledPlaceholder matches the "$1$" GORM leaves of a placeholder it has no value for.
+var unfilledPlaceholder = regexp.MustCompile(`\$(\d+)\$`)
+
+// GormLogger logs GORM's database errors and slow queries through the logger of the
+// query's context, so they carry the request ID when the query was made with
+// db.WithContext. Missing records and unique violations are not errors: callers handle
+// them (as 404 and 409 responses).
+//
+// Logged SQL keeps its placeholders: the values bound to them are never logged, as they
+// include emails, password hashes and tokens.
+type GormLogger struct {
+	level gormlogger.LogLevel
+}
+
+// NewGormLogger returns a GORM logger logging errors and slow queries.
+func NewGormLogger() *GormLogger {
+	return &GormLogger{level: gormlogger.Warn}
+}
+
+// LogMode returns a copy of the logger logging at level.
+func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+func (l *GormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		FromContext(ctx).InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *GormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		FromContext(ctx).WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *GormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		FromContext(ctx).ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// ParamsFilter drops the values bound to a query, so Trace logs its SQL with placeholders.
+func (l *GormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
+
+// Trace logs a query that failed or was slow, with its SQL, affected rows and duration.
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && !expectedError(err) && l.level >= gormlogger.Error:
+		sql, rows := fc()
+		FromContext(ctx).ErrorContext(ctx, "database query failed", queryAttrs(sql, rows, elapsed, err)...)
+	case elapsed > SlowQueryThreshold && l.level >= gormlogger.Warn:
+		sql, rows := fc()
+		FromContext(ctx).WarnContext(ctx, "slow database query", queryAttrs(sql, rows, elapsed, err)...)
+	case l.level >= gormlogger.Info:
+		sql, rows := fc()
+		FromContext(ctx).DebugContext(ctx, "database query", queryAttrs(sql, rows, elapsed, err)...)
+	}
+}
+
+// expectedError reports whether a query error is part of normal operation: a missing
+// record or a unique violation, such as registering a taken username.
+func expectedError(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.Is(err, gorm.ErrRecordNotFound) || (errors.As(err, &pgErr) && pgErr.Code == "23505")
+}
+
+// queryAttrs returns the log attributes describing a query.
+func queryAttrs(sql string, rows int64, elapsed time.Duration, err error) []interface{} {
+	attrs := []interface{}{
+		slog.String("sql", unfilledPlaceholder.ReplaceAllString(sql, "$$$1")),
+		slog.Int64("rows", rows),
+		slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	return attrs
+}
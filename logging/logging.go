@@ -0,0 +1,34 @@
+// Package logging sets up structured JSON logging with log/slog.
+//
+// Every request gets a logger carrying its request ID (see middleware.RequestIDMiddleware),
+// stored in the request's context. Code handling a request logs through FromContext, so
+// its entries can be matched with the request's access log entry.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+)
+
+// contextKey is the key the request logger is stored under in a context.
+type contextKey struct{}
+
+// Setup makes a JSON logger writing to w the default logger, for slog and the log
+// package alike. Entries below level are dropped.
+func Setup(w io.Writer, level slog.Level) {
+	slog.SetDefault(slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})))
+}
+
+// WithLogger returns a copy of ctx carrying logger.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, or the default logger.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
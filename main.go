@@ -1,52 +1,174 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
-	"github.com/gin-contrib/cors"
-	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/background"
 	"github.com/jasen-devvv/mini-blog-backend/config"
+	"github.com/jasen-devvv/mini-blog-backend/controllers"
+	"github.com/jasen-devvv/mini-blog-backend/exporter"
+	"github.com/jasen-devvv/mini-blog-backend/logging"
+	"github.com/jasen-devvv/mini-blog-backend/pubsub"
+	"github.com/jasen-devvv/mini-blog-backend/ratelimit"
 	"github.com/jasen-devvv/mini-blog-backend/routes"
-	"github.com/joho/godotenv"
+	"github.com/jasen-devvv/mini-blog-backend/tracing"
 )
 
 func main() {
-	// Load .env file
-	err := godotenv.Load()
+	// Load configuration from flags, the environment and the optional .env file
+	cfg, args, err := config.Load(os.Args[1:])
 	if err != nil {
-		log.Fatal("Error loading .env file")
+		log.Fatal(err)
+	}
+	// The server needs more settings than the commands
+	if len(args) == 0 {
+		if err := cfg.ValidateServer(); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Log structured JSON entries
+	logging.Setup(os.Stdout, cfg.LogLevel)
+
+	// Send traces to the configured exporter
+	switch cfg.TracesExporter {
+	case "stdout":
+		tracing.Setup(tracing.NewWriterExporter(os.Stdout))
+	case "file":
+		traceExporter, err := tracing.NewFileExporter(cfg.TracesFile)
+		if err != nil {
+			log.Fatalf("Failed to open traces file: %v", err)
+		}
+		tracing.Setup(traceExporter)
+	case "otlp":
+		tracing.Setup(tracing.NewOTLPExporter(cfg.OTLPEndpoint, cfg.ServiceName))
 	}
 
 	// Connect to database
-	config.ConnectDatabase()
+	config.ConnectDatabase(cfg.DatabaseURL)
+
+	// Run a command instead of the server when one is given
+	if len(args) > 0 {
+		os.Exit(runCommand(args, cfg.ShutdownTimeout))
+	}
+
+	// Apply pending migrations when configured; otherwise run "migrate up" before deploying
+	if cfg.MigrateOnStart {
+		if err := config.MigrateDatabase(context.Background()); err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+	}
+
+	// Share real-time events between instances when configured
+	if cfg.PubSubBroker == "postgres" {
+		broker, err := pubsub.NewPostgresBroker(background.Context(), cfg.DatabaseURL, 100)
+		if err != nil {
+			log.Fatal("Failed to start Postgres pub/sub broker")
+		}
+		pubsub.Default = broker
+	}
 
-	// Setup router
-	r := gin.Default()
+	// Share rate limits between instances when configured
+	if cfg.RateLimitStore == "postgres" {
+		store := ratelimit.NewPostgresStore(config.DB)
+		ratelimit.Default = store
+		background.Go(func() {
+			store.RunCleanup(background.Context(), 10*time.Minute)
+		})
+	}
 
-	// Setup Proxy
-	r.SetTrustedProxies(nil)
+	// Delete expired exports in the background
+	background.Go(func() {
+		exporter.RunCleanup(background.Context(), config.DB, time.Hour)
+	})
 
-	// Setup CORS
-	r.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-	}))
+	// Setup router with the core endpoints on the database and the configuration
+	r := routes.SetupRouter(controllers.NewHandler(config.DB, cfg))
 
-	// Setup routes
-	routes.SetupAuthRoutes(r)
-	routes.SetupArticleRoutes(r)
-	routes.SetupCommentRoutes(r) // Opsional
+	// Start server, until it fails or SIGINT or SIGTERM asks it to stop
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 
-	// Get port from environment or default to 8080
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	srv := &http.Server{Addr: ":" + cfg.Port, Handler: r}
+	srv.RegisterOnShutdown(controllers.CloseStreams)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+	slog.Info("server started", "port", cfg.Port)
+
+	exitCode := 0
+	select {
+	case err := <-serveErr:
+		slog.Error("server failed", "error", err)
+		exitCode = 1
+	case <-ctx.Done():
+		slog.Info("shutting down", "timeout", cfg.ShutdownTimeout.String())
 	}
+	// From now on, a second signal stops the process immediately
+	stop()
 
-	// Start server
-	r.Run(":" + port)
+	os.Exit(shutdown(srv, cfg.ShutdownTimeout, exitCode))
+}
+
+// runCommand runs the command named by args[0] and returns the process exit code. It then
+// releases the resources within timeout, like shutdown does for the server.
+func runCommand(args []string, timeout time.Duration) int {
+	var exitCode int
+	switch args[0] {
+	case "import":
+		exitCode = runImport(args[1:])
+	case "migrate":
+		exitCode = runMigrate(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n", args[0])
+		exitCode = 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return release(ctx, exitCode)
+}
+
+// shutdown stops the server gracefully within timeout and returns exitCode, or 1 when
+// something could not be stopped in time: it drains in-flight requests, then releases
+// the resources (see release).
+func shutdown(srv *http.Server, timeout time.Duration, exitCode int) int {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		slog.Error("failed to drain requests", "error", err)
+		exitCode = 1
+	}
+	exitCode = release(ctx, exitCode)
+
+	slog.Info("server stopped")
+	return exitCode
+}
+
+// release waits for background work, flushes the pending traces and closes the database
+// connections before ctx is done. It returns exitCode, or 1 when something failed.
+func release(ctx context.Context, exitCode int) int {
+	if err := background.Shutdown(ctx); err != nil {
+		slog.Error("failed to finish background work", "error", err)
+		exitCode = 1
+	}
+	if err := tracing.Shutdown(ctx); err != nil {
+		slog.Error("failed to flush traces", "error", err)
+		exitCode = 1
+	}
+	if err := config.CloseDatabase(); err != nil {
+		slog.Error("failed to close database", "error", err)
+		exitCode = 1
+	}
+	return exitCode
 }
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"database/sql"
+	"sync/atomic"
+)
+
+// HTTP metrics, recorded by middleware.MetricsMiddleware and middleware.RateLimitMiddleware.
+var (
+	// HTTPRequestDuration is the latency of HTTP requests by method, route template and status.
+	HTTPRequestDuration = Default.Histogram("http_request_duration_seconds",
+		"Duration of HTTP requests by method, route template and status code.", DefaultBuckets,
+		"method", "route", "status")
+
+	// RateLimited counts requests rejected by middleware.RateLimitMiddleware, by policy.
+	RateLimited = Default.Counter("http_rate_limited_total",
+		"Number of requests rejected by a rate limit, by policy.",
+		"policy")
+)
+
+// Database metrics, recorded by GormPlugin.
+var (
+	// DBQueryDuration is the duration of database queries by operation.
+	DBQueryDuration = Default.Histogram("db_query_duration_seconds",
+		"Duration of database queries by operation (create, query, update, delete, row or raw).", DefaultBuckets,
+		"operation")
+
+	// DBQueryErrors counts failed database queries by operation. Missing records are not errors.
+	DBQueryErrors = Default.Counter("db_query_errors_total",
+		"Number of failed database queries by operation.",
+		"operation")
+)
+
+// Business metrics.
+var (
+	// Registrations counts new user accounts.
+	Registrations = Default.Counter("miniblog_registrations_total", "Number of users registered.")
+	// Logins counts successful logins.
+	Logins = Default.Counter("miniblog_logins_total", "Number of successful logins.")
+	// LoginFailures counts logins rejected for wrong credentials.
+	LoginFailures = Default.Counter("miniblog_login_failures_total", "Number of logins rejected for invalid credentials.")
+	// ArticlesCreated counts articles created through the API.
+	ArticlesCreated = Default.Counter("miniblog_articles_created_total", "Number of articles created.")
+	// CommentsPosted counts comments posted by local users.
+	CommentsPosted = Default.Counter("miniblog_comments_posted_total", "Number of comments posted.")
+)
+
+// db is the connection pool whose statistics are reported (see RegisterDBStats).
+var db atomic.Pointer[sql.DB]
+
+// RegisterDBStats reports the statistics of a database connection pool. Calling it again
+// replaces the pool.
+func RegisterDBStats(pool *sql.DB) {
+	db.Store(pool)
+}
+
+func init() {
+	stat := func(read func(sql.DBStats) float64) func() float64 {
+		return func() float64 {
+			pool := db.Load()
+			if pool == nil {
+				return 0
+			}
+			return read(pool.Stats())
+		}
+	}
+
+	Default.GaugeFunc("db_connections_max_open", "Maximum number of open connections to the database.",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) }))
+	Default.GaugeFunc("db_connections_open", "Number of established connections, in use and idle.",
+		stat(func(s sql.DBStats) float64 { return float64(s.OpenConnections) }))
+	Default.GaugeFunc("db_connections_in_use", "Number of connections currently in use.",
+		stat(func(s sql.DBStats) float64 { return float64(s.InUse) }))
+	Default.GaugeFunc("db_connections_idle", "Number of idle connections.",
+		stat(func(s sql.DBStats) float64 { return float64(s.Idle) }))
+	Default.CounterFunc("db_connections_wait_total", "Number of times a query waited for a connection.",
+		stat(func(s sql.DBStats) float64 { return float64(s.WaitCount) }))
+	Default.CounterFunc("db_connections_wait_seconds_total", "Total time spent waiting for a connection.",
+		stat(func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }))
+	Default.CounterFunc("db_connections_closed_max_idle_total", "Number of connections closed because the pool had too many idle ones.",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) }))
+	Default.CounterFunc("db_connections_closed_max_lifetime_total", "Number of connections closed because they reached their maximum lifetime.",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) }))
+}
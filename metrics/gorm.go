@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// startKey is the statement setting holding the time a query started.
+const startKey = "metrics:start"
+
+// GormPlugin records the duration and errors of every GORM query in DBQueryDuration and
+// DBQueryErrors. Install it with db.Use(metrics.GormPlugin{}).
+type GormPlugin struct{}
+
+// Name returns the name of the plugin.
+func (GormPlugin) Name() string {
+	return "metrics"
+}
+
+// Initialize registers the plugin's callbacks around every kind of query.
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	type registerer interface {
+		Register(name string, fn func(*gorm.DB)) error
+	}
+
+	callbacks := db.Callback()
+	operations := []struct {
+		name          string
+		before, after registerer
+	}{
+		{"create", callbacks.Create().Before("*"), callbacks.Create().After("*")},
+		{"query", callbacks.Query().Before("*"), callbacks.Query().After("*")},
+		{"update", callbacks.Update().Before("*"), callbacks.Update().After("*")},
+		{"delete", callbacks.Delete().Before("*"), callbacks.Delete().After("*")},
+		{"row", callbacks.Row().Before("*"), callbacks.Row().After("*")},
+		{"raw", callbacks.Raw().Before("*"), callbacks.Raw().After("*")},
+	}
+
+	for _, operation := range operations {
+		if err := operation.before.Register("metrics:before_"+operation.name, startQuery); err != nil {
+			return err
+		}
+		if err := operation.after.Register("metrics:after_"+operation.name, finishQuery(operation.name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startQuery records the time a query starts.
+func startQuery(db *gorm.DB) {
+	db.InstanceSet(startKey, time.Now())
+}
+
+// finishQuery returns a callback recording the duration and outcome of a query.
+func finishQuery(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if start, ok := db.InstanceGet(startKey); ok {
+			DBQueryDuration.Observe(time.Since(start.(time.Time)).Seconds(), operation)
+		}
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			DBQueryErrors.Inc(operation)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+// Package metrics collects application metrics and exposes them in the Prometheus text
+// format.
+//
+// It implements the few metric types the application needs: counters and histograms,
+// optionally partitioned by labels, and gauges and counters read from a function when
+// scraped. The application's metrics are declared in app.go and registered with Default.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the content type of the Prometheus text format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are the upper bounds, in seconds, of the latency histograms.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// collector writes the samples of one metric.
+type collector interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+// Registry holds metrics and writes them in the Prometheus text format.
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Default is the registry the application's metrics are registered with.
+var Default = NewRegistry()
+
+// register adds a metric to the registry. Names must be unique.
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.collectors {
+		if existing.name() == c.name() {
+			panic(fmt.Sprintf("metrics: %s registered twice", c.name()))
+		}
+	}
+	r.collectors = append(r.collectors, c)
+	sort.Slice(r.collectors, func(i, j int) bool { return r.collectors[i].name() < r.collectors[j].name() })
+}
+
+// Write writes every metric of the registry to w.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	collectors := append([]collector{}, r.collectors...)
+	r.mu.Unlock()
+
+	buffered := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(buffered)
+	}
+	return buffered.Flush()
+}
+
+// CounterVec is a counter, partitioned by the values of its labels.
+type CounterVec struct {
+	metric
+	mu     sync.Mutex
+	values map[string]*counterSeries
+}
+
+type counterSeries struct {
+	labelValues []string
+	value       float64
+}
+
+// Counter registers a counter. Without labels, Inc and Add take no label values.
+func (r *Registry) Counter(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{metric: metric{metricName: name, help: help, labels: labels}, values: map[string]*counterSeries{}}
+	if len(labels) == 0 {
+		// Report counters without labels from the start, not only once they changed
+		c.values[""] = &counterSeries{}
+	}
+	r.register(c)
+	return c
+}
+
+// Inc adds one to the counter with the given label values.
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds delta, which must not be negative, to the counter with the given label values.
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	c.checkLabels(labelValues)
+	key := seriesKey(labelValues)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	series, ok := c.values[key]
+	if !ok {
+		series = &counterSeries{labelValues: append([]string{}, labelValues...)}
+		c.values[key] = series
+	}
+	series.value += delta
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.writeHeader(w, "counter")
+	for _, key := range sortedKeys(c.values) {
+		series := c.values[key]
+		c.writeSample(w, "", series.labelValues, "", series.value)
+	}
+}
+
+// HistogramVec is a histogram, partitioned by the values of its labels.
+type HistogramVec struct {
+	metric
+	buckets []float64
+	mu      sync.Mutex
+	values  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	labelValues []string
+	counts      []uint64 // per bucket, not cumulative
+	sum         float64
+	count       uint64
+}
+
+// Histogram registers a histogram with the given bucket upper bounds, in increasing order.
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{metric: metric{metricName: name, help: help, labels: labels}, buckets: buckets, values: map[string]*histogramSeries{}}
+	r.register(h)
+	return h
+}
+
+// Observe records a value in the histogram with the given label values.
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	h.checkLabels(labelValues)
+	key := seriesKey(labelValues)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	series, ok := h.values[key]
+	if !ok {
+		series = &histogramSeries{labelValues: append([]string{}, labelValues...), counts: make([]uint64, len(h.buckets))}
+		h.values[key] = series
+	}
+	if i := sort.SearchFloat64s(h.buckets, value); i < len(h.buckets) {
+		series.counts[i]++
+	}
+	series.sum += value
+	series.count++
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.writeHeader(w, "histogram")
+	for _, key := range sortedKeys(h.values) {
+		series := h.values[key]
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += series.counts[i]
+			h.writeSample(w, "_bucket", series.labelValues, formatFloat(bound), float64(cumulative))
+		}
+		h.writeSample(w, "_bucket", series.labelValues, "+Inf", float64(series.count))
+		h.writeSample(w, "_sum", series.labelValues, "", series.sum)
+		h.writeSample(w, "_count", series.labelValues, "", float64(series.count))
+	}
+}
+
+// funcMetric is a gauge or counter whose value is read when the metrics are written.
+type funcMetric struct {
+	metric
+	kind  string
+	value func() float64
+}
+
+// GaugeFunc registers a gauge whose value is returned by fn.
+func (r *Registry) GaugeFunc(name, help string, fn func() float64) {
+	r.register(&funcMetric{metric: metric{metricName: name, help: help}, kind: "gauge", value: fn})
+}
+
+// CounterFunc registers a counter whose value is returned by fn, for counts kept elsewhere.
+func (r *Registry) CounterFunc(name, help string, fn func() float64) {
+	r.register(&funcMetric{metric: metric{metricName: name, help: help}, kind: "counter", value: fn})
+}
+
+func (f *funcMetric) write(w *bufio.Writer) {
+	f.writeHeader(w, f.kind)
+	f.writeSample(w, "", nil, "", f.value())
+}
+
+// metric holds what all metric types have in common.
+type metric struct {
+	metricName string
+	help       string
+	labels     []string
+}
+
+func (m *metric) name() string {
+	return m.metricName
+}
+
+// checkLabels panics when the number of label values does not match the labels, which
+// is a programming error.
+func (m *metric) checkLabels(labelValues []string) {
+	if len(labelValues) != len(m.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", m.metricName, len(m.labels), len(labelValues)))
+	}
+}
+
+func (m *metric) writeHeader(w *bufio.Writer, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", m.metricName, strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(m.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", m.metricName, kind)
+}
+
+// writeSample writes one sample line. Histogram buckets pass their upper bound as le.
+func (m *metric) writeSample(w *bufio.Writer, suffix string, labelValues []string, le string, value float64) {
+	w.WriteString(m.metricName)
+	w.WriteString(suffix)
+
+	pairs := make([]string, 0, len(m.labels)+1)
+	for i, name := range m.labels {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", name, escapeLabelValue(labelValues[i])))
+	}
+	if le != "" {
+		pairs = append(pairs, fmt.Sprintf("le=\"%s\"", le))
+	}
+	if len(pairs) > 0 {
+		w.WriteString("{" + strings.Join(pairs, ",") + "}")
+	}
+
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+// seriesKey identifies the series of a set of label values.
+func seriesKey(labelValues []string) string {
+	return strings.Join(labelValues, "\xff")
+}
+
+func sortedKeys[T any](values map[string]T) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
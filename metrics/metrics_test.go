@@ -0,0 +1,117 @@
+package metrics_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jasen-devvv/mini-blog-backend/internal/testutil"
+	"github.com/jasen-devvv/mini-blog-backend/metrics"
+)
+
+// write returns the exposition of a registry.
+func write(t *testing.T, registry *metrics.Registry) string {
+	t.Helper()
+	var out bytes.Buffer
+	if err := registry.Write(&out); err != nil {
+		t.Fatalf("writing metrics: %v", err)
+	}
+	return out.String()
+}
+
+func TestWriteGolden(t *testing.T) {
+	registry := metrics.NewRegistry()
+
+	requests := registry.Counter("http_requests_total", "Number of requests\nby method and path, with \\ escaped.", "method", "path")
+	requests.Inc("GET", "/articles")
+	requests.Add(2, "GET", "/articles")
+	requests.Inc("POST", `/search?q="go"`)
+	requests.Inc("GET", "C:\\temp\nline")
+
+	registry.Counter("logins_total", "Number of logins.")
+
+	duration := registry.Histogram("request_duration_seconds", "Duration of requests.", []float64{0.005, 0.1, 1, 2.5}, "route")
+	duration.Observe(0.005, "/b")
+	duration.Observe(0.05, "/b")
+	duration.Observe(3, "/b")
+	duration.Observe(0.75, "/a")
+
+	registry.GaugeFunc("connections_open", "Number of open connections.", func() float64 { return 7 })
+	registry.CounterFunc("bytes_sent_total", "Number of bytes sent.", func() float64 { return 1.5e9 })
+
+	testutil.Golden(t, "exposition.golden", []byte(write(t, registry)))
+}
+
+func TestHistogramBuckets(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   []string
+	}{
+		{
+			name:   "no observations yet",
+			values: nil,
+			want:   nil,
+		},
+		{
+			// Bounds are inclusive: a value equal to a bound falls in that bucket
+			name:   "value on a bound",
+			values: []float64{0.1},
+			want:   []string{`latency_bucket{le="0.01"} 0`, `latency_bucket{le="0.1"} 1`, `latency_bucket{le="1"} 1`, `latency_bucket{le="+Inf"} 1`, "latency_sum 0.1", "latency_count 1"},
+		},
+		{
+			name:   "cumulative counts",
+			values: []float64{0, 0.01, 0.02, 0.5, 0.5},
+			want:   []string{`latency_bucket{le="0.01"} 2`, `latency_bucket{le="0.1"} 3`, `latency_bucket{le="1"} 5`, `latency_bucket{le="+Inf"} 5`, "latency_sum 1.03", "latency_count 5"},
+		},
+		{
+			// Values above the largest bound are only counted in +Inf
+			name:   "above every bound",
+			values: []float64{1.0001, 60},
+			want:   []string{`latency_bucket{le="0.01"} 0`, `latency_bucket{le="0.1"} 0`, `latency_bucket{le="1"} 0`, `latency_bucket{le="+Inf"} 2`, "latency_sum 61.0001", "latency_count 2"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			registry := metrics.NewRegistry()
+			histogram := registry.Histogram("latency", "Latency.", []float64{0.01, 0.1, 1})
+			for _, value := range test.values {
+				histogram.Observe(value)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(write(t, registry), "\n"), "\n")
+			if len(lines) < 2 || lines[0] != "# HELP latency Latency." || lines[1] != "# TYPE latency histogram" {
+				t.Fatalf("unexpected header: %q", lines)
+			}
+			if got := lines[2:]; strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+				t.Errorf("got samples\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(test.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestDefaultBucketsIncrease(t *testing.T) {
+	for i := 1; i < len(metrics.DefaultBuckets); i++ {
+		if metrics.DefaultBuckets[i] <= metrics.DefaultBuckets[i-1] {
+			t.Fatalf("DefaultBuckets are not increasing at %d: %v", i, metrics.DefaultBuckets)
+		}
+	}
+}
+
+func TestRegistryPanics(t *testing.T) {
+	expectPanic := func(name string, fn func()) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s did not panic", name)
+			}
+		}()
+		fn()
+	}
+
+	registry := metrics.NewRegistry()
+	counter := registry.Counter("events_total", "Events.", "kind")
+	expectPanic("registering a name twice", func() { registry.Counter("events_total", "Events.") })
+	expectPanic("passing too few label values", func() { counter.Inc() })
+	expectPanic("passing too many label values", func() { counter.Inc("a", "b") })
+}
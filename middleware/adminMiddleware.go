@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/apierror"
+	"github.com/jasen-devvv/mini-blog-backend/config"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+)
+
+// AdminMiddleware restricts routes to administrators.
+//
+// It must run after AuthMiddleware. The user's role is loaded from the database
+// rather than the token, so promoting or demoting a user takes effect immediately.
+//
+// If the user is not an administrator, it returns a 403 Forbidden response.
+func AdminMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, exists := ctx.Get("user_id")
+		if !exists {
+			ctx.Error(apierror.New(http.StatusUnauthorized, "Unauthorized"))
+			ctx.Abort()
+			return
+		}
+
+		var user models.User
+		if err := config.DB.WithContext(ctx.Request.Context()).Select("id", "role").First(&user, userID).Error; err != nil || user.Role != models.RoleAdmin {
+			ctx.Error(apierror.New(http.StatusForbidden, "Administrator access required"))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -1,16 +1,19 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
-	"os"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/jasen-devvv/mini-blog-backend/apierror"
+	"github.com/jasen-devvv/mini-blog-backend/config"
 )
 
-// AuthMiddleware is a JWT authentication middleware.
+// AuthMiddleware is a JWT authentication middleware, checking tokens signed with
+// cfg.JWTSecret.
 //
 // It validates the "Authorization" header in the format:
 //   - "Bearer {token}"
@@ -18,12 +21,13 @@ import (
 // If the token is valid, it extracts the `user_id` from the claims
 // and stores it in the context for further use in protected routes.
 //
-// If authentication fails, it returns a 401 Unauthorized response.
-func AuthMiddleware() gin.HandlerFunc {
+// If authentication fails, it returns a 401 Unauthorized response with the code
+// "missing_token" or "invalid_token".
+func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authHeader := ctx.GetHeader("Authorization")
 		if authHeader == "" {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+			ctx.Error(apierror.New(http.StatusUnauthorized, "Authorization header is required").WithCode("missing_token"))
 			ctx.Abort()
 			return
 		}
@@ -31,38 +35,103 @@ func AuthMiddleware() gin.HandlerFunc {
 		// Check if the header has the format "Bearer {token}"
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header format must be Bearer {token}"})
+			ctx.Error(apierror.New(http.StatusUnauthorized, "Authorization header format must be Bearer {token}").WithCode("invalid_token"))
 			ctx.Abort()
 			return
 		}
 
-		tokenString := parts[1]
+		userID, err := parseToken(cfg.JWTSecret, parts[1])
+		if err != nil {
+			ctx.Error(apierror.New(http.StatusUnauthorized, err.Error()).WithCode("invalid_token"))
+			ctx.Abort()
+			return
+		}
 
-		// Parse the JWT token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Validate the signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		setUser(ctx, userID)
+		ctx.Next()
+	}
+}
+
+// OptionalAuthMiddleware behaves like AuthMiddleware but never rejects a request.
+//
+// When a valid "Bearer {token}" header is present, the `user_id` is stored in
+// the context. Otherwise the request continues anonymously, which lets public
+// routes tailor their response to the caller. Public article routes need it because
+// drafts stay readable by their author only (see models.ArticleStatusDraft).
+func OptionalAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		parts := strings.Split(ctx.GetHeader("Authorization"), " ")
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			if userID, err := parseToken(cfg.JWTSecret, parts[1]); err == nil {
+				setUser(ctx, userID)
 			}
+		}
+		ctx.Next()
+	}
+}
 
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
+// StreamAuthMiddleware authenticates streaming routes (WebSocket and Server-Sent Events).
+//
+// Browsers cannot set headers on WebSocket and EventSource connections, so besides
+// the "Authorization: Bearer {token}" header it also accepts the token in the
+// "access_token" query parameter. It returns a 401 Unauthorized response otherwise.
+func StreamAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tokenString := ctx.Query("access_token")
+		if parts := strings.Split(ctx.GetHeader("Authorization"), " "); len(parts) == 2 && parts[0] == "Bearer" {
+			tokenString = parts[1]
+		}
 
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		if tokenString == "" {
+			ctx.Error(apierror.New(http.StatusUnauthorized, "Authorization header or access_token is required").WithCode("missing_token"))
 			ctx.Abort()
 			return
 		}
 
-		// Check if the token is valid
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			// Set user_id in the context
-			ctx.Set("user_id", uint(claims["user_id"].(float64)))
-			ctx.Next()
-		} else {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+		userID, err := parseToken(cfg.JWTSecret, tokenString)
+		if err != nil {
+			ctx.Error(apierror.New(http.StatusUnauthorized, err.Error()).WithCode("invalid_token"))
 			ctx.Abort()
 			return
 		}
+
+		setUser(ctx, userID)
+		ctx.Next()
 	}
 }
+
+// setUser stores the authenticated user's ID in the context as `user_id` and adds it to
+// the request's logger.
+func setUser(ctx *gin.Context, userID uint) {
+	ctx.Set("user_id", userID)
+	addLogFields(ctx, "user_id", userID)
+}
+
+// parseToken validates a JWT signed with secret and returns the user ID stored in its claims.
+func parseToken(secret, tokenString string) (uint, error) {
+	// Parse the JWT token
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// Validate the signing method
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+
+		return []byte(secret), nil
+	})
+	if err != nil {
+		return 0, errors.New("Invalid token")
+	}
+
+	// Check if the token is valid
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return 0, errors.New("Invalid token claims")
+	}
+
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		return 0, errors.New("Invalid token claims")
+	}
+
+	return uint(userID), nil
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/apierror"
+)
+
+// ErrorMiddleware renders the errors of requests as problem details responses.
+//
+// When the handlers attached errors with ctx.Error without writing a response, the last
+// *apierror.Error is rendered (see apierror.Render); errors of other types become 500
+// Internal Server Error responses. All the errors are still logged by LoggerMiddleware.
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		if ctx.Writer.Written() || len(ctx.Errors) == 0 {
+			return
+		}
+
+		err := ctx.Errors.Last().Err
+		for i := len(ctx.Errors) - 1; i >= 0; i-- {
+			if apiErr, ok := ctx.Errors[i].Err.(*apierror.Error); ok {
+				err = apiErr
+				break
+			}
+		}
+		apierror.Render(ctx, err)
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/apierror"
+	"github.com/jasen-devvv/mini-blog-backend/logging"
+)
+
+// LoggerMiddleware writes a structured access log entry for every request.
+//
+// It must run after RequestIDMiddleware. Each entry has the request ID, method, route
+// template, path, status, latency, response size and client IP, and the errors handlers
+// attached with ctx.Error. The authentication middleware adds the user ID to the request's
+// logger, so it is included for authenticated requests. Server errors are logged at the
+// error level.
+func LoggerMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		attrs := []slog.Attr{
+			slog.String("method", ctx.Request.Method),
+			slog.String("route", ctx.FullPath()),
+			slog.String("path", ctx.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", max(ctx.Writer.Size(), 0)),
+			slog.String("client_ip", ctx.ClientIP()),
+		}
+		if len(ctx.Errors) > 0 {
+			attrs = append(attrs, slog.Any("errors", ctx.Errors.Errors()))
+		}
+
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		requestContext := ctx.Request.Context()
+		logging.FromContext(requestContext).LogAttrs(requestContext, level, "request", attrs...)
+	}
+}
+
+// RecoveryMiddleware turns panics into 500 Internal Server Error responses, logging the
+// panic and its stack trace with the request's logger. It must run after ErrorMiddleware,
+// which renders the response.
+func RecoveryMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				requestContext := ctx.Request.Context()
+				logging.FromContext(requestContext).ErrorContext(requestContext, "panic while handling request",
+					slog.Any("panic", recovered), slog.String("stack", string(debug.Stack())))
+				ctx.Error(apierror.New(http.StatusInternalServerError, "Internal server error"))
+				ctx.Abort()
+			}
+		}()
+		ctx.Next()
+	}
+}
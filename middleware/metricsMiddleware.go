@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/metrics"
+)
+
+// MetricsMiddleware records the duration of every request in metrics.HTTPRequestDuration.
+//
+// Requests are labelled with the route template (e.g. "/api/articles/:id") rather than
+// the path, so the number of series stays bounded; requests matching no route are
+// labelled "unmatched".
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.HTTPRequestDuration.Observe(time.Since(start).Seconds(), ctx.Request.Method, route, strconv.Itoa(ctx.Writer.Status()))
+	}
+}
@@ -0,0 +1,171 @@
+package middleware_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/config"
+	"github.com/jasen-devvv/mini-blog-backend/internal/testutil"
+	"github.com/jasen-devvv/mini-blog-backend/middleware"
+	"github.com/jasen-devvv/mini-blog-backend/ratelimit"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+	gin.DefaultWriter = io.Discard
+}
+
+// recordingStore is a ratelimit.Store answering every request with result, or err,
+// and recording the keys it was asked for.
+type recordingStore struct {
+	result ratelimit.Result
+	err    error
+	keys   []string
+}
+
+func (s *recordingStore) Take(ctx context.Context, policy ratelimit.Policy, key string) (ratelimit.Result, error) {
+	s.keys = append(s.keys, key)
+	return s.result, s.err
+}
+
+// useStore makes store the default store until the test ends.
+func useStore(t *testing.T, store ratelimit.Store) {
+	previous := ratelimit.Default
+	ratelimit.Default = store
+	t.Cleanup(func() { ratelimit.Default = previous })
+}
+
+// rateLimitedRouter serves GET / with the named policy of key. Requests carrying an
+// X-User header are authenticated as that user ID, like the auth middleware does.
+func rateLimitedRouter(name, key string) *gin.Engine {
+	cfg := &config.Config{RateLimits: map[string]ratelimit.Policy{
+		"test": {Name: "test", Limit: 5, Period: time.Minute, Key: key},
+	}}
+
+	r := gin.New()
+	r.Use(middleware.ErrorMiddleware())
+	r.GET("/",
+		func(ctx *gin.Context) {
+			if id, err := strconv.ParseUint(ctx.GetHeader("X-User"), 10, 64); err == nil {
+				ctx.Set("user_id", uint(id))
+			}
+		},
+		middleware.RateLimitMiddleware(cfg, name),
+		func(ctx *gin.Context) { ctx.String(http.StatusOK, "ok") },
+	)
+	return r
+}
+
+func TestRateLimitKey(t *testing.T) {
+	sum := sha256.Sum256([]byte("secret"))
+	tokenKey := "token:" + hex.EncodeToString(sum[:16])
+
+	tests := []struct {
+		name    string
+		key     string
+		headers []string
+		want    string
+	}{
+		{"ip", ratelimit.KeyIP, []string{"X-User", "7"}, "ip:192.0.2.1"},
+		{"user", ratelimit.KeyUser, []string{"X-User", "7"}, "user:7"},
+		{"anonymous user", ratelimit.KeyUser, nil, "ip:192.0.2.1"},
+		{"token", ratelimit.KeyToken, []string{"X-User", "7", "Authorization", "Bearer secret"}, tokenKey},
+		{"unverified token", ratelimit.KeyToken, []string{"Authorization", "Bearer secret"}, "ip:192.0.2.1"},
+		{"no bearer token", ratelimit.KeyToken, []string{"X-User", "7", "Authorization", "Basic secret"}, "ip:192.0.2.1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := &recordingStore{result: ratelimit.Result{Allowed: true}}
+			useStore(t, store)
+
+			w := testutil.Do(t, rateLimitedRouter("test", test.key), http.MethodGet, "/", "", nil, test.headers...)
+			testutil.ExpectStatus(t, w, http.StatusOK)
+			if len(store.keys) != 1 || store.keys[0] != test.want {
+				t.Errorf("keys = %v, want %s", store.keys, test.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	tests := []struct {
+		name   string
+		result ratelimit.Result
+		status int
+		want   map[string]string
+	}{
+		{
+			name:   "allowed",
+			result: ratelimit.Result{Allowed: true, Remaining: 3, Reset: 1500 * time.Millisecond},
+			status: http.StatusOK,
+			want: map[string]string{
+				"RateLimit-Limit":     "5",
+				"RateLimit-Remaining": "3",
+				"RateLimit-Reset":     "2",
+				"RateLimit-Policy":    "5;w=60",
+				"Retry-After":         "",
+			},
+		},
+		{
+			name:   "rejected",
+			result: ratelimit.Result{Remaining: 0, Reset: time.Minute, RetryAfter: 200 * time.Millisecond},
+			status: http.StatusTooManyRequests,
+			want: map[string]string{
+				"RateLimit-Limit":     "5",
+				"RateLimit-Remaining": "0",
+				"RateLimit-Reset":     "60",
+				"RateLimit-Policy":    "5;w=60",
+				"Retry-After":         "1",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			useStore(t, &recordingStore{result: test.result})
+
+			w := testutil.Do(t, rateLimitedRouter("test", ratelimit.KeyIP), http.MethodGet, "/", "", nil)
+			testutil.ExpectStatus(t, w, test.status)
+			for name, value := range test.want {
+				if got := w.Header().Get(name); got != value {
+					t.Errorf("%s = %q, want %q", name, got, value)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitPassThrough(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		store  *recordingStore
+		calls  int
+	}{
+		// Without a configured policy, the store is not asked
+		{"unknown policy", "missing", &recordingStore{}, 0},
+		// An unavailable store lets requests through
+		{"store error", "test", &recordingStore{err: errors.New("database is down")}, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			useStore(t, test.store)
+
+			w := testutil.Do(t, rateLimitedRouter(test.policy, ratelimit.KeyIP), http.MethodGet, "/", "", nil)
+			testutil.ExpectStatus(t, w, http.StatusOK)
+			if len(test.store.keys) != test.calls {
+				t.Errorf("store called %d times, want %d", len(test.store.keys), test.calls)
+			}
+			if limit := w.Header().Get("RateLimit-Limit"); limit != "" {
+				t.Errorf("RateLimit-Limit = %q, want none", limit)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/logging"
+)
+
+// RequestIDHeader is the header carrying the request ID.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength is the length above which incoming request IDs are replaced.
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware assigns every request an ID.
+//
+// The ID is taken from the "X-Request-ID" header when a proxy or client set a valid
+// one, and generated otherwise. It is stored in the context as `request_id`, echoed in
+// the response's "X-Request-ID" header, and added to the request's logger (see
+// logging.FromContext), so all entries logged while handling the request carry it.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+
+		ctx.Set("request_id", requestID)
+		ctx.Header(RequestIDHeader, requestID)
+		addLogFields(ctx, "request_id", requestID)
+		ctx.Next()
+	}
+}
+
+// validRequestID reports whether id is a non-empty, reasonably short string of printable
+// ASCII characters, so it can be logged and echoed safely.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns a random 128-bit request ID.
+func newRequestID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// addLogFields adds fields to the logger of the request.
+func addLogFields(ctx *gin.Context, args ...interface{}) {
+	requestContext := ctx.Request.Context()
+	logger := logging.FromContext(requestContext).With(args...)
+	ctx.Request = ctx.Request.WithContext(logging.WithLogger(requestContext, logger))
+}
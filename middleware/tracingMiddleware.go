@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/tracing"
+)
+
+// TracingMiddleware records a server span for every request.
+//
+// The span continues the trace of the caller when the request has a valid W3C
+// "traceparent" header, and starts a new trace otherwise. It is named after the method
+// and route template (e.g. "GET /api/articles/:id") and stored in the request's context,
+// so database queries and outbound calls made with that context become its children.
+//
+// It must run after RequestIDMiddleware. The trace and span IDs are added to the
+// request's logger, and the trace ID to error responses (see apierror.Render), so a
+// reported error can be traced back to the request.
+func TracingMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestContext := ctx.Request.Context()
+		if parent, ok := tracing.Extract(ctx.Request.Header); ok {
+			requestContext = tracing.ContextWithRemoteParent(requestContext, parent)
+		}
+
+		name := ctx.Request.Method
+		if route := ctx.FullPath(); route != "" {
+			name += " " + route
+		}
+		requestContext, span := tracing.Start(requestContext, name, tracing.SpanKindServer)
+		defer span.End()
+		span.SetAttribute("http.request.method", ctx.Request.Method)
+		span.SetAttribute("http.route", ctx.FullPath())
+		span.SetAttribute("url.path", ctx.Request.URL.Path)
+		span.SetAttribute("client.address", ctx.ClientIP())
+		if userAgent := ctx.Request.UserAgent(); userAgent != "" {
+			span.SetAttribute("user_agent.original", userAgent)
+		}
+
+		spanContext := span.SpanContext()
+		ctx.Request = ctx.Request.WithContext(requestContext)
+		addLogFields(ctx, "trace_id", spanContext.TraceID.String(), "span_id", spanContext.SpanID.String())
+
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		span.SetAttribute("http.response.status_code", status)
+		if userID, exists := ctx.Get("user_id"); exists {
+			span.SetAttribute("enduser.id", strconv.FormatUint(uint64(userID.(uint)), 10))
+		}
+		if status >= http.StatusInternalServerError {
+			message := http.StatusText(status)
+			if len(ctx.Errors) > 0 {
+				message = ctx.Errors.Last().Error()
+			}
+			span.SetStatus(tracing.StatusError, message)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jasen-devvv/mini-blog-backend/config"
+	"github.com/jasen-devvv/mini-blog-backend/migrations"
+)
+
+// runMigrate runs the "migrate" command and returns the process exit code:
+//
+//	mini-blog-backend migrate up              apply all pending migrations
+//	mini-blog-backend migrate down [-steps n] revert the last n migrations (1 by default)
+//	mini-blog-backend migrate status          list migrations and when they were applied
+//
+// The initial schema (0001) is irreversible: down refuses to revert it, and reverts
+// nothing when asked to go that far.
+func runMigrate(args []string) int {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	steps := flags.Int("steps", 1, "number of migrations to revert with down")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: migrate [flags] up | down | status")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	sqlDB, err := config.DB.DB()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "migrate:", err)
+		return 1
+	}
+	ctx := context.Background()
+
+	switch flags.Arg(0) {
+	case "up":
+		applied, err := migrations.Up(ctx, sqlDB)
+		for _, migration := range applied {
+			fmt.Printf("applied %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "migrate:", err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+	case "down":
+		if *steps < 1 {
+			flags.Usage()
+			return 2
+		}
+		reverted, err := migrations.Down(ctx, sqlDB, *steps)
+		for _, migration := range reverted {
+			fmt.Printf("reverted %04d_%s\n", migration.Version, migration.Name)
+		}
+		if errors.Is(err, migrations.ErrIrreversible) {
+			fmt.Fprintln(os.Stderr, "migrate:", err)
+			fmt.Fprintln(os.Stderr, "migrate: nothing was reverted; the initial schema can only be removed by dropping the database")
+			return 1
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "migrate:", err)
+			return 1
+		}
+	case "status":
+		states, err := migrations.Status(ctx, sqlDB)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "migrate:", err)
+			return 1
+		}
+		for _, state := range states {
+			applied := "pending"
+			if state.AppliedAt != nil {
+				applied = state.AppliedAt.Local().Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-40s %s\n", state.Version, state.Name, applied)
+		}
+	default:
+		flags.Usage()
+		return 2
+	}
+
+	return 0
+}
@@ -0,0 +1,283 @@
+-- Initial schema: every table the application used before versioned migrations.
+--
+-- Statements use IF NOT EXISTS so databases created by GORM's AutoMigrate can adopt
+-- this migration without being recreated. Such databases already have the users,
+-- articles and comments tables of the first release, so the columns added to them
+-- since are added explicitly (and backfilled) before their indexes are created.
+--
+-- This migration has no down file: reverting it would drop every user and article.
+
+CREATE TABLE IF NOT EXISTS users (
+    id           bigserial PRIMARY KEY,
+    username     varchar(100) NOT NULL CONSTRAINT uni_users_username UNIQUE,
+    email        varchar(255) NOT NULL CONSTRAINT uni_users_email UNIQUE,
+    password     varchar(255) NOT NULL,
+    display_name varchar(100),
+    bio          text,
+    avatar_url   varchar(500),
+    website      varchar(255),
+    social_links jsonb,
+    role         varchar(20) NOT NULL DEFAULT 'user',
+    created_at   timestamptz,
+    updated_at   timestamptz
+);
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS display_name varchar(100),
+    ADD COLUMN IF NOT EXISTS bio          text,
+    ADD COLUMN IF NOT EXISTS avatar_url   varchar(500),
+    ADD COLUMN IF NOT EXISTS website      varchar(255),
+    ADD COLUMN IF NOT EXISTS social_links jsonb,
+    ADD COLUMN IF NOT EXISTS role         varchar(20) NOT NULL DEFAULT 'user';
+
+CREATE TABLE IF NOT EXISTS tags (
+    id   bigserial PRIMARY KEY,
+    name varchar(50) NOT NULL CONSTRAINT uni_tags_name UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS articles (
+    id               bigserial PRIMARY KEY,
+    title            varchar(255) NOT NULL,
+    slug             varchar(255) NOT NULL DEFAULT '',
+    content          text NOT NULL,
+    excerpt          varchar(500),
+    cover_url        varchar(500),
+    meta_description varchar(300),
+    canonical_url    varchar(500),
+    noindex          boolean NOT NULL DEFAULT false,
+    status           varchar(20) NOT NULL DEFAULT 'published',
+    version          bigint NOT NULL DEFAULT 1,
+    user_id          bigint CONSTRAINT fk_articles_user REFERENCES users (id),
+    created_at       timestamptz,
+    updated_at       timestamptz
+);
+-- Articles of the first release were all public, so they keep being published
+ALTER TABLE articles
+    ADD COLUMN IF NOT EXISTS slug             varchar(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS excerpt          varchar(500),
+    ADD COLUMN IF NOT EXISTS cover_url        varchar(500),
+    ADD COLUMN IF NOT EXISTS meta_description varchar(300),
+    ADD COLUMN IF NOT EXISTS canonical_url    varchar(500),
+    ADD COLUMN IF NOT EXISTS noindex          boolean NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS status           varchar(20) NOT NULL DEFAULT 'published',
+    ADD COLUMN IF NOT EXISTS version          bigint NOT NULL DEFAULT 1;
+
+-- Give articles without a slug one derived from their title, like models.Slugify does;
+-- repeated titles get the article ID appended
+WITH slugs AS (
+    SELECT id,
+           COALESCE(NULLIF(rtrim(left(trim(BOTH '-' FROM lower(regexp_replace(title, '[^[:alnum:]]+', '-', 'g'))), 200), '-'), ''), 'article') AS base
+    FROM articles
+    WHERE slug = ''
+), numbered AS (
+    SELECT id, base, row_number() OVER (PARTITION BY base ORDER BY id) AS n
+    FROM slugs
+)
+UPDATE articles
+SET slug = CASE WHEN numbered.n = 1 THEN numbered.base ELSE numbered.base || '-' || articles.id END
+FROM numbered
+WHERE articles.id = numbered.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_articles_slug ON articles (slug) WHERE slug <> '';
+CREATE INDEX IF NOT EXISTS idx_articles_status ON articles (status);
+CREATE INDEX IF NOT EXISTS idx_articles_author_feed ON articles (user_id, status, created_at);
+
+CREATE TABLE IF NOT EXISTS article_tags (
+    article_id bigint NOT NULL CONSTRAINT fk_article_tags_article REFERENCES articles (id) ON DELETE CASCADE,
+    tag_id     bigint NOT NULL CONSTRAINT fk_article_tags_tag REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (article_id, tag_id)
+);
+
+CREATE TABLE IF NOT EXISTS remote_actors (
+    id             bigserial PRIMARY KEY,
+    uri            varchar(500) NOT NULL CONSTRAINT uni_remote_actors_uri UNIQUE,
+    username       varchar(255),
+    name           varchar(255),
+    url            varchar(500),
+    inbox          varchar(500) NOT NULL,
+    shared_inbox   varchar(500),
+    public_key_id  varchar(500),
+    public_key_pem text,
+    created_at     timestamptz,
+    updated_at     timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS comments (
+    id                bigserial PRIMARY KEY,
+    content           text NOT NULL,
+    user_id           bigint CONSTRAINT fk_comments_user REFERENCES users (id),
+    article_id        bigint,
+    parent_id         bigint,
+    created_at        timestamptz,
+    updated_at        timestamptz,
+    remote_actor_id   bigint CONSTRAINT fk_comments_remote_actor REFERENCES remote_actors (id) ON DELETE CASCADE,
+    remote_object_uri varchar(500) CONSTRAINT uni_comments_remote_object_uri UNIQUE,
+    author_name       varchar(255)
+);
+ALTER TABLE comments
+    ADD COLUMN IF NOT EXISTS parent_id         bigint,
+    ADD COLUMN IF NOT EXISTS remote_actor_id   bigint CONSTRAINT fk_comments_remote_actor REFERENCES remote_actors (id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS remote_object_uri varchar(500) CONSTRAINT uni_comments_remote_object_uri UNIQUE,
+    ADD COLUMN IF NOT EXISTS author_name       varchar(255);
+-- Federated comments have no local user
+ALTER TABLE comments ALTER COLUMN user_id DROP NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_comments_article_id ON comments (article_id);
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id ON comments (parent_id);
+
+CREATE TABLE IF NOT EXISTS follows (
+    follower_id bigint NOT NULL,
+    followee_id bigint NOT NULL,
+    created_at  timestamptz,
+    PRIMARY KEY (follower_id, followee_id)
+);
+CREATE INDEX IF NOT EXISTS idx_follows_followee_id ON follows (followee_id);
+
+CREATE TABLE IF NOT EXISTS reactions (
+    id          bigserial PRIMARY KEY,
+    user_id     bigint NOT NULL,
+    target_type varchar(20) NOT NULL,
+    target_id   bigint NOT NULL,
+    kind        varchar(20) NOT NULL,
+    created_at  timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reactions_unique ON reactions (user_id, target_type, target_id, kind);
+
+CREATE TABLE IF NOT EXISTS reaction_counts (
+    target_type varchar(20) NOT NULL,
+    target_id   bigint NOT NULL,
+    kind        varchar(20) NOT NULL,
+    count       bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY (target_type, target_id, kind)
+);
+
+CREATE TABLE IF NOT EXISTS bookmarks (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint NOT NULL,
+    article_id bigint NOT NULL CONSTRAINT fk_bookmarks_article REFERENCES articles (id) ON DELETE CASCADE,
+    created_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bookmarks_user_article ON bookmarks (user_id, article_id);
+CREATE INDEX IF NOT EXISTS idx_bookmarks_article_id ON bookmarks (article_id);
+
+CREATE TABLE IF NOT EXISTS reading_lists (
+    id          bigserial PRIMARY KEY,
+    user_id     bigint NOT NULL CONSTRAINT fk_reading_lists_user REFERENCES users (id),
+    name        varchar(100) NOT NULL,
+    description text,
+    is_public   boolean NOT NULL,
+    created_at  timestamptz,
+    updated_at  timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_reading_lists_user_id ON reading_lists (user_id);
+
+CREATE TABLE IF NOT EXISTS reading_list_items (
+    id              bigserial PRIMARY KEY,
+    reading_list_id bigint NOT NULL CONSTRAINT fk_reading_lists_items REFERENCES reading_lists (id) ON DELETE CASCADE,
+    article_id      bigint NOT NULL CONSTRAINT fk_reading_list_items_article REFERENCES articles (id) ON DELETE CASCADE,
+    position        bigint NOT NULL DEFAULT 0,
+    note            text,
+    created_at      timestamptz,
+    updated_at      timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reading_list_items_list_article ON reading_list_items (reading_list_id, article_id);
+CREATE INDEX IF NOT EXISTS idx_reading_list_items_article_id ON reading_list_items (article_id);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id          bigserial PRIMARY KEY,
+    user_id     bigint NOT NULL,
+    type        varchar(20) NOT NULL,
+    group_key   varchar(100) NOT NULL,
+    actor_id    bigint CONSTRAINT fk_notifications_actor REFERENCES users (id),
+    actor_count bigint NOT NULL DEFAULT 1,
+    article_id  bigint,
+    comment_id  bigint,
+    read_at     timestamptz,
+    created_at  timestamptz,
+    updated_at  timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_group ON notifications (user_id, group_key);
+CREATE INDEX IF NOT EXISTS idx_notifications_updated_at ON notifications (updated_at);
+
+CREATE TABLE IF NOT EXISTS notification_actors (
+    notification_id bigint NOT NULL,
+    actor_id        bigint NOT NULL,
+    PRIMARY KEY (notification_id, actor_id)
+);
+
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id bigint NOT NULL,
+    type    varchar(20) NOT NULL,
+    enabled boolean NOT NULL,
+    PRIMARY KEY (user_id, type)
+);
+
+CREATE TABLE IF NOT EXISTS article_locks (
+    article_id bigint PRIMARY KEY,
+    user_id    bigint NOT NULL CONSTRAINT fk_article_locks_user REFERENCES users (id),
+    token      varchar(64) NOT NULL,
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_article_locks_token ON article_locks (token);
+
+CREATE TABLE IF NOT EXISTS remote_follows (
+    user_id         bigint NOT NULL,
+    remote_actor_id bigint NOT NULL CONSTRAINT fk_remote_follows_remote_actor REFERENCES remote_actors (id) ON DELETE CASCADE,
+    activity_uri    varchar(500),
+    created_at      timestamptz,
+    PRIMARY KEY (user_id, remote_actor_id)
+);
+
+CREATE TABLE IF NOT EXISTS actor_keys (
+    user_id         bigint PRIMARY KEY,
+    public_key_pem  text NOT NULL,
+    private_key_pem text NOT NULL,
+    created_at      timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS webmentions (
+    id           bigserial PRIMARY KEY,
+    article_id   bigint NOT NULL CONSTRAINT fk_webmentions_article REFERENCES articles (id) ON DELETE CASCADE,
+    source       varchar(500) NOT NULL,
+    target       varchar(500) NOT NULL,
+    status       varchar(20) NOT NULL DEFAULT 'pending',
+    hidden       boolean NOT NULL DEFAULT false,
+    type         varchar(20),
+    title        varchar(255),
+    author_name  varchar(255),
+    author_url   varchar(500),
+    author_photo varchar(500),
+    excerpt      text,
+    published    timestamptz,
+    verified_at  timestamptz,
+    created_at   timestamptz,
+    updated_at   timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_webmentions_article_id ON webmentions (article_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webmentions_source_target ON webmentions (source, target);
+
+CREATE TABLE IF NOT EXISTS import_items (
+    id          bigserial PRIMARY KEY,
+    source      varchar(255) NOT NULL,
+    external_id varchar(500) NOT NULL,
+    kind        varchar(20) NOT NULL,
+    target_id   bigint NOT NULL,
+    created_at  timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_import_items_external ON import_items (source, kind, external_id);
+
+CREATE TABLE IF NOT EXISTS export_jobs (
+    id              bigserial PRIMARY KEY,
+    user_id         bigint NOT NULL CONSTRAINT fk_export_jobs_user REFERENCES users (id) ON DELETE CASCADE,
+    scope           varchar(20) NOT NULL,
+    subject_user_id bigint,
+    status          varchar(20) NOT NULL DEFAULT 'pending',
+    error           varchar(500),
+    file_path       varchar(500),
+    size            bigint,
+    token           varchar(64),
+    expires_at      timestamptz,
+    created_at      timestamptz,
+    completed_at    timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_export_jobs_user_id ON export_jobs (user_id);
+CREATE INDEX IF NOT EXISTS idx_export_jobs_status ON export_jobs (status);
@@ -0,0 +1,11 @@
+-- Token buckets of the shared rate limit store (ratelimit.PostgresStore). Losing them in
+-- a crash only resets the limits, so the table skips the write-ahead log.
+
+CREATE UNLOGGED TABLE IF NOT EXISTS rate_limit_buckets (
+    key        varchar(255) PRIMARY KEY,
+    tokens     double precision NOT NULL,
+    allowed    boolean NOT NULL,
+    updated_at timestamptz NOT NULL,
+    expires_at timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_expires_at ON rate_limit_buckets (expires_at);
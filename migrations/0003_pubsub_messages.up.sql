@@ -0,0 +1,12 @@
+-- Messages relayed between instances by pubsub.PostgresBroker. Notifications only carry
+-- the ID of a row, which every instance loads; rows are deleted once all listeners had
+-- time to read them, so the table skips the write-ahead log.
+
+CREATE UNLOGGED TABLE IF NOT EXISTS pubsub_messages (
+    id         bigserial PRIMARY KEY,
+    topic      varchar(255) NOT NULL,
+    event      varchar(100) NOT NULL,
+    data       jsonb NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_pubsub_messages_created_at ON pubsub_messages (created_at);
@@ -0,0 +1,11 @@
+-- Allow a single unread notification per group, so concurrent events join it instead of
+-- each starting a group (notifications.Notify upserts against this index). Duplicates
+-- created before are marked read, keeping the newest one unread.
+
+UPDATE notifications AS n SET read_at = now()
+WHERE n.read_at IS NULL AND EXISTS (
+    SELECT 1 FROM notifications AS o
+    WHERE o.user_id = n.user_id AND o.group_key = n.group_key AND o.read_at IS NULL AND o.id > n.id
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_unread_group ON notifications (user_id, group_key) WHERE read_at IS NULL;
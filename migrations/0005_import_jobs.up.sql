@@ -0,0 +1,15 @@
+-- Imports run in the background (importer.Process); their status and report are kept here.
+
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id           bigserial PRIMARY KEY,
+    user_id      bigint NOT NULL CONSTRAINT fk_import_jobs_user REFERENCES users (id) ON DELETE CASCADE,
+    source       varchar(255) NOT NULL,
+    format       varchar(20) NOT NULL,
+    dry_run      boolean NOT NULL DEFAULT false,
+    status       varchar(20) NOT NULL DEFAULT 'pending',
+    error        varchar(500),
+    report       jsonb,
+    created_at   timestamptz,
+    completed_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_import_jobs_user_id ON import_jobs (user_id);
@@ -0,0 +1,10 @@
+-- Deleted articles leave no row behind, so feeds read their deletion times from here to
+-- answer If-Modified-Since (see models.ArticleDeletion).
+
+CREATE TABLE IF NOT EXISTS article_deletions (
+    article_id bigint PRIMARY KEY,
+    user_id    bigint NOT NULL,
+    deleted_at timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_article_deletions_user_id ON article_deletions (user_id);
+CREATE INDEX IF NOT EXISTS idx_article_deletions_deleted_at ON article_deletions (deleted_at);
@@ -0,0 +1,9 @@
+-- Authors share articles with the users listed here (see models.ArticleCollaborator).
+
+CREATE TABLE IF NOT EXISTS article_collaborators (
+    article_id bigint NOT NULL CONSTRAINT fk_article_collaborators_article REFERENCES articles (id) ON DELETE CASCADE,
+    user_id    bigint NOT NULL CONSTRAINT fk_article_collaborators_user REFERENCES users (id) ON DELETE CASCADE,
+    created_at timestamptz,
+    PRIMARY KEY (article_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_article_collaborators_user_id ON article_collaborators (user_id);
@@ -0,0 +1,4 @@
+package migrations
+
+// LoadFS exposes load to the tests.
+var LoadFS = load
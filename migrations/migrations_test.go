@@ -0,0 +1,227 @@
+package migrations_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jasen-devvv/mini-blog-backend/internal/testutil"
+	"github.com/jasen-devvv/mini-blog-backend/migrations"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(testutil.RunWithDatabase(m))
+}
+
+func TestLoad(t *testing.T) {
+	file := func(content string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(content)} }
+
+	// Versions are ordered numerically and paired with their down file
+	got, err := migrations.LoadFS(fstest.MapFS{
+		"10_tenth.up.sql":     file("ten up"),
+		"2_second.up.sql":     file("two up"),
+		"2_second.down.sql":   file("two down"),
+		"0001_first.up.sql":   file("one up"),
+		"README.md":           file("ignored"),
+		"0003_third.up.sql":   file("three up"),
+		"0003_third.down.sql": file("three down"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []migrations.Migration{
+		{Version: 1, Name: "first", Up: "one up"},
+		{Version: 2, Name: "second", Up: "two up", Down: "two down"},
+		{Version: 3, Name: "third", Up: "three up", Down: "three down"},
+		{Version: 10, Name: "tenth", Up: "ten up"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("migrations = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("migration %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	errorTests := []struct {
+		name  string
+		files []string
+		want  string
+	}{
+		{"no version", []string{"initial.up.sql"}, "invalid migration file name"},
+		{"version zero", []string{"0000_initial.up.sql"}, "invalid migration file name"},
+		{"unknown direction", []string{"0001_initial.sideways.sql"}, "invalid migration file name"},
+		{"no direction", []string{"0001_initial.sql"}, "invalid migration file name"},
+		{"down only", []string{"0001_initial.up.sql", "0002_views.down.sql"}, "migration 2 has no up file"},
+		{"two names", []string{"0001_initial.up.sql", "0001_other.down.sql"}, "migration 1 has two names"},
+	}
+	for _, test := range errorTests {
+		t.Run(test.name, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			for _, name := range test.files {
+				fsys[name] = file("SELECT 1;")
+			}
+			_, err := migrations.LoadFS(fsys)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("error = %v, want %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	all, err := migrations.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Versions follow each other, and only the initial schema cannot be reverted
+	for i, migration := range all {
+		if migration.Version != i+1 {
+			t.Errorf("migration %d_%s, want version %d", migration.Version, migration.Name, i+1)
+		}
+		if reversible := migration.Down != ""; reversible != (migration.Version != 1) {
+			t.Errorf("migration %04d_%s: reversible = %v", migration.Version, migration.Name, reversible)
+		}
+	}
+}
+
+// database returns an empty test database.
+func database(t *testing.T) *sql.DB {
+	t.Helper()
+	sqlDB, err := testutil.EmptyDatabase(t).DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sqlDB
+}
+
+// applied returns the versions Status reports as applied.
+func applied(t *testing.T, db *sql.DB) []int {
+	t.Helper()
+	states, err := migrations.Status(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var versions []int
+	for _, state := range states {
+		if state.AppliedAt != nil {
+			versions = append(versions, state.Version)
+		}
+	}
+	return versions
+}
+
+// tableExists reports whether the table exists in the test schema.
+func tableExists(t *testing.T, db *sql.DB, table string) bool {
+	t.Helper()
+	var exists bool
+	if err := db.QueryRow("SELECT to_regclass($1) IS NOT NULL", table).Scan(&exists); err != nil {
+		t.Fatal(err)
+	}
+	return exists
+}
+
+func TestUpDownStatus(t *testing.T) {
+	ctx := context.Background()
+	db := database(t)
+	all, err := migrations.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest := all[len(all)-1]
+
+	// A new database has every migration pending
+	states, err := migrations.Status(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != len(all) || len(applied(t, db)) != 0 {
+		t.Fatalf("status = %+v, want %d pending migrations", states, len(all))
+	}
+
+	ran, err := migrations.Up(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ran) != len(all) || len(applied(t, db)) != len(all) {
+		t.Fatalf("applied %d migrations, want %d", len(ran), len(all))
+	}
+	if !tableExists(t, db, "articles") || !tableExists(t, db, "article_collaborators") {
+		t.Error("the schema was not created")
+	}
+	if ran, err := migrations.Up(ctx, db); err != nil || len(ran) != 0 {
+		t.Errorf("second Up applied %d migrations (%v), want none", len(ran), err)
+	}
+
+	// Down reverts the newest migrations first, and Up applies them again
+	reverted, err := migrations.Down(ctx, db, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reverted) != 2 || reverted[0].Version != latest.Version || reverted[1].Version != latest.Version-1 {
+		t.Fatalf("reverted %+v, want the two newest migrations", reverted)
+	}
+	if versions := applied(t, db); len(versions) != len(all)-2 || versions[len(versions)-1] != latest.Version-2 {
+		t.Errorf("applied versions = %v after reverting two", versions)
+	}
+	if tableExists(t, db, "article_collaborators") {
+		t.Error("article_collaborators still exists after reverting its migration")
+	}
+	if ran, err := migrations.Up(ctx, db); err != nil || len(ran) != 2 {
+		t.Errorf("Up applied %d migrations (%v), want the 2 reverted ones", len(ran), err)
+	}
+
+	// The initial schema is irreversible: asking to revert it reverts nothing
+	reverted, err = migrations.Down(ctx, db, len(all))
+	if !errors.Is(err, migrations.ErrIrreversible) || !strings.Contains(err.Error(), "0001_initial_schema") {
+		t.Errorf("reverting everything: error = %v, want 0001 irreversible", err)
+	}
+	if len(reverted) != 0 || len(applied(t, db)) != len(all) {
+		t.Errorf("reverted %+v, want nothing", reverted)
+	}
+	reverted, err = migrations.Down(ctx, db, len(all)-1)
+	if err != nil || len(reverted) != len(all)-1 {
+		t.Fatalf("reverting all but the initial schema: %d reverted (%v)", len(reverted), err)
+	}
+	if versions := applied(t, db); len(versions) != 1 || versions[0] != 1 {
+		t.Errorf("applied versions = %v, want the initial schema only", versions)
+	}
+}
+
+func TestUpConcurrent(t *testing.T) {
+	ctx := context.Background()
+	db := database(t)
+
+	// Instances starting together apply each migration exactly once
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		total int
+	)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ran, err := migrations.Up(ctx, db)
+			if err != nil {
+				t.Error(err)
+			}
+			mu.Lock()
+			total += len(ran)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	all, _ := migrations.Load()
+	if total != len(all) {
+		t.Errorf("applied %d migrations in total, want %d", total, len(all))
+	}
+}
@@ -1,23 +1,116 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// Article status values. Public listings such as author pages only show published
+// articles; drafts are only visible to their author and collaborators.
+const (
+	ArticleStatusDraft     = "draft"
+	ArticleStatusPublished = "published"
+)
 
 // Article represents a blog article in the system.
 //
 // Fields:
 //   - ID: Unique identifier for the article.
 //   - Title: Title of the article (max 255 characters, required).
+//   - Slug: URL-friendly name of the article, generated from the title (unique when set).
 //   - Content: Main content of the article (required).
+//   - Excerpt: Optional short summary shown in listings (max 500 characters).
+//   - CoverURL: Optional URL of the article's cover image.
+//   - MetaDescription: Optional description for search engines and link previews (max 300 characters).
+//   - CanonicalURL: Optional canonical URL, for articles first published elsewhere.
+//   - NoIndex: Whether search engines should not index the article.
+//   - Status: Publication status, either "draft" or "published" (defaults to "published").
+//   - Version: Incremented on every update, used for optimistic concurrency control (ETags).
 //   - UserID: ID of the user who created the article.
 //   - User: Associated user who wrote the article.
+//   - Tags: Tags attached to the article.
 //   - CreatedAt: Timestamp when the article was created.
 //   - UpdatedAt: Timestamp when the article was last updated.
+//   - ReactionCounts: Number of reactions per kind (not stored, filled in responses).
+//   - MyReactions: Reaction kinds added by the current user (not stored, filled in responses).
+//
+// The composite (user_id, status, created_at) index serves author pages and
+// the personalized feed, which both list an author's newest published articles.
 type Article struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	Title     string    `gorm:"size:255;not null" json:"title"`
-	Content   string    `gorm:"type:text;not null" json:"content"`
-	UserID    uint      `json:"user_id"`
-	User      User      `gorm:"foreignKey:UserID" json:"user"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	Title           string    `gorm:"size:255;not null" json:"title"`
+	Slug            string    `gorm:"size:255;not null;default:'';uniqueIndex:idx_articles_slug,where:slug <> ''" json:"slug"`
+	Content         string    `gorm:"type:text;not null" json:"content"`
+	Excerpt         string    `gorm:"size:500" json:"excerpt"`
+	CoverURL        string    `gorm:"size:500" json:"cover_url"`
+	MetaDescription string    `gorm:"size:300" json:"meta_description"`
+	CanonicalURL    string    `gorm:"size:500" json:"canonical_url"`
+	NoIndex         bool      `gorm:"column:noindex;not null;default:false" json:"noindex"`
+	Status          string    `gorm:"size:20;not null;default:'published';index;index:idx_articles_author_feed,priority:2" json:"status"`
+	Version         uint      `gorm:"not null;default:1" json:"version"`
+	UserID          uint      `gorm:"index:idx_articles_author_feed,priority:1" json:"user_id"`
+	User            User      `gorm:"foreignKey:UserID" json:"user"`
+	Tags            []Tag     `gorm:"many2many:article_tags;constraint:OnDelete:CASCADE" json:"tags"`
+	CreatedAt       time.Time `gorm:"index:idx_articles_author_feed,priority:3" json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+
+	ReactionCounts map[string]int64 `gorm:"-" json:"reaction_counts"`
+	MyReactions    []string         `gorm:"-" json:"my_reactions"`
+}
+
+// maxSlugLength is the maximum length of a generated slug, leaving room for a
+// numeric suffix within the column size.
+const maxSlugLength = 200
+
+// Slugify converts a title to a URL-friendly slug: lowercase letters and digits
+// separated by single dashes.
+func Slugify(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+
+	slug := b.String()
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(strings.ToValidUTF8(slug[:maxSlugLength], ""), "-")
+	}
+	return slug
+}
+
+// UniqueArticleSlug returns a slug for the given text that no article uses yet,
+// appending "-2", "-3", ... when needed. It returns "article" for text without
+// letters or digits.
+func UniqueArticleSlug(db *gorm.DB, text string) (string, error) {
+	base := Slugify(text)
+	if base == "" {
+		base = "article"
+	}
+
+	var taken []string
+	if err := db.Model(&Article{}).Where("slug = ? OR slug LIKE ?", base, base+"-%").Pluck("slug", &taken).Error; err != nil {
+		return "", err
+	}
+	used := map[string]bool{}
+	for _, slug := range taken {
+		used[slug] = true
+	}
+
+	slug := base
+	for i := 2; used[slug]; i++ {
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+	return slug, nil
 }
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// ArticleCollaborator represents a user the author lets edit an article, so a team can
+// share a draft.
+//
+// Collaborators may view, change and take the edit lock of the article, but only its
+// author may delete it or change who collaborates on it.
+//
+// Fields:
+//   - ArticleID: ID of the shared article.
+//   - UserID: ID of the collaborating user.
+//   - User: Associated collaborating user.
+//   - CreatedAt: Timestamp when the user was added.
+type ArticleCollaborator struct {
+	ArticleID uint      `gorm:"primaryKey;autoIncrement:false" json:"article_id"`
+	UserID    uint      `gorm:"primaryKey;autoIncrement:false;index" json:"user_id"`
+	User      User      `gorm:"foreignKey:UserID" json:"user"`
+	CreatedAt time.Time `json:"created_at"`
+}
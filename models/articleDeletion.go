@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// ArticleDeletion records that a published article was deleted, so feeds can tell that
+// they changed even though the article left no row behind (see controllers.serveFeed).
+//
+// Fields:
+//   - ArticleID: ID the deleted article had.
+//   - UserID: ID of the article's author.
+//   - DeletedAt: Timestamp when the article was deleted.
+type ArticleDeletion struct {
+	ArticleID uint      `gorm:"primaryKey;autoIncrement:false" json:"article_id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	DeletedAt time.Time `gorm:"not null;index" json:"deleted_at"`
+}
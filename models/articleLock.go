@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// ArticleLock represents an advisory edit lock on an article.
+//
+// A lock belongs to one editing session (identified by Token) and expires unless
+// it is renewed by heartbeats. Expired locks are treated as released.
+//
+// Fields:
+//   - ArticleID: ID of the locked article.
+//   - UserID: ID of the user holding the lock.
+//   - User: Associated user holding the lock.
+//   - Token: Secret identifying the editing session that holds the lock.
+//   - ExpiresAt: Timestamp after which the lock is no longer valid.
+//   - CreatedAt: Timestamp when the lock was acquired.
+type ArticleLock struct {
+	ArticleID uint      `gorm:"primaryKey;autoIncrement:false" json:"article_id"`
+	UserID    uint      `gorm:"not null" json:"user_id"`
+	User      User      `gorm:"foreignKey:UserID" json:"user"`
+	Token     string    `gorm:"size:64;not null;index" json:"-"`
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Bookmark represents an article a user saved to read later.
+//
+// Fields:
+//   - ID: Unique identifier for the bookmark.
+//   - UserID: ID of the user who saved the article.
+//   - ArticleID: ID of the saved article.
+//   - Article: Associated saved article.
+//   - CreatedAt: Timestamp when the article was bookmarked.
+type Bookmark struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_bookmarks_user_article,priority:1" json:"user_id"`
+	ArticleID uint      `gorm:"not null;uniqueIndex:idx_bookmarks_user_article,priority:2;index" json:"article_id"`
+	Article   Article   `gorm:"foreignKey:ArticleID;constraint:OnDelete:CASCADE" json:"article"`
+	CreatedAt time.Time `json:"created_at"`
+}
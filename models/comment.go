@@ -7,17 +7,33 @@ import "time"
 // Fields:
 //   - ID: Unique identifier for the comment.
 //   - Content: The actual comment text (required).
-//   - UserID: ID of the user who posted the comment.
+//   - UserID: ID of the user who posted the comment (null for federated replies).
 //   - User: Associated user who made the comment.
+//   - RemoteActorID: ID of the remote ActivityPub actor who posted a federated reply.
+//   - RemoteActor: Associated remote actor.
+//   - RemoteObjectURI: ActivityPub ID of a federated reply, used to deduplicate deliveries.
+//   - AuthorName: Name of the guest author of an imported comment.
 //   - ArticleID: ID of the article the comment belongs to.
+//   - ParentID: ID of the comment this comment replies to, if any.
 //   - CreatedAt: Timestamp when the comment was created.
 //   - UpdatedAt: Timestamp when the comment was last updated.
+//   - ReactionCounts: Number of reactions per kind (not stored, filled in responses).
+//   - MyReactions: Reaction kinds added by the current user (not stored, filled in responses).
 type Comment struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
 	Content   string    `gorm:"type:text;not null" json:"content"`
-	UserID    uint      `json:"user_id"`
-	User      User      `gorm:"foreignKey:UserID" json:"user"`
-	ArticleID uint      `json:"article_id"`
+	UserID    *uint     `json:"user_id"`
+	User      *User     `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	ArticleID uint      `gorm:"index" json:"article_id"`
+	ParentID  *uint     `gorm:"index" json:"parent_id"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	RemoteActorID   *uint        `json:"remote_actor_id,omitempty"`
+	RemoteActor     *RemoteActor `gorm:"foreignKey:RemoteActorID;constraint:OnDelete:CASCADE" json:"remote_actor,omitempty"`
+	RemoteObjectURI *string      `gorm:"size:500;unique" json:"-"`
+	AuthorName      string       `gorm:"size:255" json:"author_name,omitempty"`
+
+	ReactionCounts map[string]int64 `gorm:"-" json:"reaction_counts"`
+	MyReactions    []string         `gorm:"-" json:"my_reactions"`
 }
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Statuses of an export job.
+const (
+	ExportStatusPending   = "pending"
+	ExportStatusRunning   = "running"
+	ExportStatusCompleted = "completed"
+	ExportStatusFailed    = "failed"
+	ExportStatusExpired   = "expired"
+)
+
+// ExportJob represents an asynchronous export of the blog or of a user's data.
+//
+// Fields:
+//   - ID: Unique identifier for the job.
+//   - UserID: ID of the user who requested the export.
+//   - Scope: What is exported, "blog" (every article) or "user" (a single user's data).
+//   - SubjectUserID: ID of the exported user (user scope only).
+//   - Status: Job status, "pending", "running", "completed", "failed" or "expired".
+//   - Error: Why the export failed.
+//   - FilePath: Location of the ZIP archive on disk.
+//   - Size: Size of the ZIP archive in bytes.
+//   - Token: Secret required to download the archive.
+//   - ExpiresAt: When the download link expires and the archive is deleted.
+//   - CreatedAt: Timestamp when the export was requested.
+//   - CompletedAt: Timestamp when the export finished.
+type ExportJob struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	UserID        uint       `gorm:"not null;index" json:"user_id"`
+	Scope         string     `gorm:"size:20;not null" json:"scope"`
+	SubjectUserID *uint      `json:"subject_user_id,omitempty"`
+	Status        string     `gorm:"size:20;not null;default:'pending';index" json:"status"`
+	Error         string     `gorm:"size:500" json:"error,omitempty"`
+	FilePath      string     `gorm:"size:500" json:"-"`
+	Size          int64      `json:"size"`
+	Token         string     `gorm:"size:64" json:"-"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+	User          User       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Follow represents a user following another user (an author).
+//
+// Fields:
+//   - FollowerID: ID of the user who follows.
+//   - FolloweeID: ID of the user being followed.
+//   - CreatedAt: Timestamp when the follow was created.
+//
+// The composite primary key prevents duplicate follows, and the index on
+// FolloweeID keeps follower counts and lookups fast.
+type Follow struct {
+	FollowerID uint      `gorm:"primaryKey;autoIncrement:false" json:"follower_id"`
+	FolloweeID uint      `gorm:"primaryKey;autoIncrement:false;index" json:"followee_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Kinds of imported items.
+const (
+	ImportKindArticle = "article"
+	ImportKindComment = "comment"
+	ImportKindAuthor  = "author"
+)
+
+// ImportItem records a post, comment or author created by an import, so running the same
+// import again skips what was already imported and resumes after a failure. Author items
+// map the source's authors to users.
+//
+// Fields:
+//   - ID: Unique identifier for the record.
+//   - Source: Identifies the import source (e.g. the WordPress site URL).
+//   - ExternalID: ID of the item in the source (e.g. a WordPress GUID, a file path or an author's login).
+//   - Kind: Kind of item, "article", "comment" or "author".
+//   - TargetID: ID of the article, comment or user created for the item.
+//   - CreatedAt: Timestamp when the item was imported.
+type ImportItem struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Source     string    `gorm:"size:255;not null;uniqueIndex:idx_import_items_external,priority:1" json:"source"`
+	ExternalID string    `gorm:"size:500;not null;uniqueIndex:idx_import_items_external,priority:3" json:"external_id"`
+	Kind       string    `gorm:"size:20;not null;uniqueIndex:idx_import_items_external,priority:2" json:"kind"`
+	TargetID   uint      `gorm:"not null" json:"target_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
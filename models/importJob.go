@@ -0,0 +1,41 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Statuses of an import job.
+const (
+	ImportStatusPending   = "pending"
+	ImportStatusRunning   = "running"
+	ImportStatusCompleted = "completed"
+	ImportStatusFailed    = "failed"
+)
+
+// ImportJob represents an import of content from another blogging platform, run in the background.
+//
+// Fields:
+//   - ID: Unique identifier for the job.
+//   - UserID: ID of the administrator who started the import.
+//   - Source: Identifies the import source (see ImportItem).
+//   - Format: Format of the uploaded file, "wxr" or "markdown".
+//   - DryRun: Whether the import only reports what it would do.
+//   - Status: Job status, "pending", "running", "completed" or "failed".
+//   - Error: Why the import failed.
+//   - Report: Report of the finished import (see importer.Report).
+//   - CreatedAt: Timestamp when the import was started.
+//   - CompletedAt: Timestamp when the import finished.
+type ImportJob struct {
+	ID          uint            `gorm:"primaryKey" json:"id"`
+	UserID      uint            `gorm:"not null;index" json:"user_id"`
+	Source      string          `gorm:"size:255;not null" json:"source"`
+	Format      string          `gorm:"size:20;not null" json:"format"`
+	DryRun      bool            `gorm:"not null;default:false" json:"dry_run"`
+	Status      string          `gorm:"size:20;not null;default:'pending'" json:"status"`
+	Error       string          `gorm:"size:500" json:"error,omitempty"`
+	Report      json.RawMessage `gorm:"type:jsonb" json:"report,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+	User        User            `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
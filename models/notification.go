@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+// Notification types.
+const (
+	NotificationTypeComment  = "comment"  // someone commented on your article
+	NotificationTypeReply    = "reply"    // someone replied to your comment
+	NotificationTypeFollow   = "follow"   // someone followed you
+	NotificationTypeReaction = "reaction" // someone reacted to your article or comment
+)
+
+// NotificationTypes lists every notification type, in display order.
+var NotificationTypes = []string{
+	NotificationTypeComment,
+	NotificationTypeReply,
+	NotificationTypeFollow,
+	NotificationTypeReaction,
+}
+
+// Notification represents an entry in a user's notification inbox.
+//
+// Similar events are grouped into a single unread notification (for example
+// "5 people liked your post"); GroupKey identifies the group and ActorCount
+// holds the number of distinct users involved.
+//
+// Fields:
+//   - ID: Unique identifier for the notification.
+//   - UserID: ID of the user who receives the notification.
+//   - Type: Notification type, one of NotificationTypes.
+//   - GroupKey: Key shared by events that are grouped together.
+//   - ActorID: ID of the most recent user who triggered the notification.
+//   - Actor: Associated most recent actor.
+//   - ActorCount: Number of distinct users grouped in the notification.
+//   - ArticleID: ID of the related article, if any.
+//   - CommentID: ID of the related comment, if any.
+//   - ReadAt: Timestamp when the notification was read (null while unread).
+//   - CreatedAt: Timestamp when the notification was created.
+//   - UpdatedAt: Timestamp of the most recent grouped event.
+//   - Message: Human readable summary (not stored, filled in responses).
+type Notification struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index:idx_notifications_user_group,priority:1;uniqueIndex:idx_notifications_unread_group,priority:1,where:read_at IS NULL" json:"user_id"`
+	Type       string     `gorm:"size:20;not null" json:"type"`
+	GroupKey   string     `gorm:"size:100;not null;index:idx_notifications_user_group,priority:2;uniqueIndex:idx_notifications_unread_group,priority:2,where:read_at IS NULL" json:"group_key"`
+	ActorID    uint       `json:"actor_id"`
+	Actor      User       `gorm:"foreignKey:ActorID" json:"actor"`
+	ActorCount int        `gorm:"not null;default:1" json:"actor_count"`
+	ArticleID  *uint      `json:"article_id"`
+	CommentID  *uint      `json:"comment_id"`
+	ReadAt     *time.Time `json:"read_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"index" json:"updated_at"`
+
+	Message string `gorm:"-" json:"message"`
+}
+
+// NotificationActor records which users are grouped in a notification, so that
+// repeated events from the same user are only counted once.
+//
+// Fields:
+//   - NotificationID: ID of the grouped notification.
+//   - ActorID: ID of a user who triggered the notification.
+type NotificationActor struct {
+	NotificationID uint `gorm:"primaryKey;autoIncrement:false" json:"notification_id"`
+	ActorID        uint `gorm:"primaryKey;autoIncrement:false" json:"actor_id"`
+}
+
+// NotificationPreference stores whether a user wants notifications of a given type.
+// Types without a stored preference are enabled.
+//
+// Fields:
+//   - UserID: ID of the user.
+//   - Type: Notification type.
+//   - Enabled: Whether notifications of this type are delivered.
+type NotificationPreference struct {
+	UserID  uint   `gorm:"primaryKey;autoIncrement:false" json:"user_id"`
+	Type    string `gorm:"primaryKey;size:20" json:"type"`
+	Enabled bool   `gorm:"not null" json:"enabled"`
+}
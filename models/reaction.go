@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Reaction target types.
+const (
+	ReactionTargetArticle = "article"
+	ReactionTargetComment = "comment"
+)
+
+// ReactionEmojis is the fixed set of supported reactions, keyed by kind.
+var ReactionEmojis = map[string]string{
+	"like":      "👍",
+	"love":      "❤️",
+	"laugh":     "😂",
+	"wow":       "😮",
+	"sad":       "😢",
+	"celebrate": "🎉",
+}
+
+// Reaction represents a single user's emoji reaction to an article or a comment.
+//
+// Fields:
+//   - ID: Unique identifier for the reaction.
+//   - UserID: ID of the user who reacted.
+//   - TargetType: Either "article" or "comment".
+//   - TargetID: ID of the article or comment reacted to.
+//   - Kind: Reaction kind, one of the keys of ReactionEmojis.
+//   - CreatedAt: Timestamp when the reaction was added.
+//
+// The unique index allows one reaction of each kind per user and target.
+type Reaction struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     uint      `gorm:"not null;uniqueIndex:idx_reactions_unique,priority:1" json:"user_id"`
+	TargetType string    `gorm:"size:20;not null;uniqueIndex:idx_reactions_unique,priority:2" json:"target_type"`
+	TargetID   uint      `gorm:"not null;uniqueIndex:idx_reactions_unique,priority:3" json:"target_id"`
+	Kind       string    `gorm:"size:20;not null;uniqueIndex:idx_reactions_unique,priority:4" json:"kind"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ReactionCount holds the aggregate number of reactions of one kind on a target.
+// It is updated atomically together with Reaction rows.
+//
+// Fields:
+//   - TargetType: Either "article" or "comment".
+//   - TargetID: ID of the article or comment.
+//   - Kind: Reaction kind.
+//   - Count: Number of users who reacted with this kind.
+type ReactionCount struct {
+	TargetType string `gorm:"primaryKey;size:20" json:"target_type"`
+	TargetID   uint   `gorm:"primaryKey;autoIncrement:false" json:"target_id"`
+	Kind       string `gorm:"primaryKey;size:20" json:"kind"`
+	Count      int64  `gorm:"not null;default:0" json:"count"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// ReadingList represents a named, ordered collection of articles curated by a user.
+//
+// Fields:
+//   - ID: Unique identifier for the list.
+//   - UserID: ID of the user who owns the list.
+//   - User: Associated owner of the list.
+//   - Name: Name of the list (max 100 characters, required).
+//   - Description: Optional description of the list.
+//   - IsPublic: Whether anyone with the list URL can view it.
+//   - Items: Articles in the list, ordered by position.
+//   - CreatedAt: Timestamp when the list was created.
+//   - UpdatedAt: Timestamp when the list was last updated.
+type ReadingList struct {
+	ID          uint              `gorm:"primaryKey" json:"id"`
+	UserID      uint              `gorm:"not null;index" json:"user_id"`
+	User        User              `gorm:"foreignKey:UserID" json:"user"`
+	Name        string            `gorm:"size:100;not null" json:"name"`
+	Description string            `gorm:"type:text" json:"description"`
+	IsPublic    bool              `gorm:"not null" json:"is_public"`
+	Items       []ReadingListItem `gorm:"foreignKey:ReadingListID;constraint:OnDelete:CASCADE" json:"items,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// ReadingListItem represents an article placed in a reading list.
+//
+// Fields:
+//   - ID: Unique identifier for the item.
+//   - ReadingListID: ID of the list the item belongs to.
+//   - ArticleID: ID of the listed article.
+//   - Article: Associated listed article.
+//   - Position: Sort position of the item within the list (ascending).
+//   - Note: Optional note about the article.
+//   - CreatedAt: Timestamp when the article was added to the list.
+//   - UpdatedAt: Timestamp when the item was last updated.
+type ReadingListItem struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	ReadingListID uint      `gorm:"not null;uniqueIndex:idx_reading_list_items_list_article,priority:1" json:"reading_list_id"`
+	ArticleID     uint      `gorm:"not null;uniqueIndex:idx_reading_list_items_list_article,priority:2;index" json:"article_id"`
+	Article       Article   `gorm:"foreignKey:ArticleID;constraint:OnDelete:CASCADE" json:"article"`
+	Position      int       `gorm:"not null;default:0" json:"position"`
+	Note          string    `gorm:"type:text" json:"note"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// RemoteActor represents a user on another ActivityPub server (for example a Mastodon account)
+// that follows our authors or replies to our articles.
+//
+// Fields:
+//   - ID: Unique identifier for the remote actor.
+//   - URI: ActivityPub ID of the actor (unique).
+//   - Username: The actor's preferred username on its server.
+//   - Name: Display name of the actor.
+//   - URL: Profile page of the actor.
+//   - Inbox: Inbox URL activities are delivered to.
+//   - SharedInbox: Server-wide inbox, preferred for delivery when available.
+//   - PublicKeyID: ID of the key the actor signs its requests with.
+//   - PublicKeyPEM: PEM encoded public key used to verify the actor's signatures.
+//   - CreatedAt: Timestamp when the actor was first seen.
+//   - UpdatedAt: Timestamp when the actor was last fetched.
+type RemoteActor struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	URI          string    `gorm:"size:500;not null;unique" json:"uri"`
+	Username     string    `gorm:"size:255" json:"username"`
+	Name         string    `gorm:"size:255" json:"name"`
+	URL          string    `gorm:"size:500" json:"url"`
+	Inbox        string    `gorm:"size:500;not null" json:"-"`
+	SharedInbox  string    `gorm:"size:500" json:"-"`
+	PublicKeyID  string    `gorm:"size:500" json:"-"`
+	PublicKeyPEM string    `gorm:"type:text" json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// RemoteFollow represents a remote actor following one of our authors.
+//
+// Fields:
+//   - UserID: ID of the local author being followed.
+//   - RemoteActorID: ID of the remote follower.
+//   - RemoteActor: Associated remote follower.
+//   - ActivityURI: ID of the Follow activity, needed to match a later Undo.
+//   - CreatedAt: Timestamp when the follow was accepted.
+type RemoteFollow struct {
+	UserID        uint        `gorm:"primaryKey;autoIncrement:false" json:"user_id"`
+	RemoteActorID uint        `gorm:"primaryKey;autoIncrement:false" json:"remote_actor_id"`
+	RemoteActor   RemoteActor `gorm:"foreignKey:RemoteActorID;constraint:OnDelete:CASCADE" json:"remote_actor"`
+	ActivityURI   string      `gorm:"size:500" json:"activity_uri"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+// ActorKey holds the key pair an author signs outgoing ActivityPub requests with.
+// It is generated the first time the author is federated.
+//
+// Fields:
+//   - UserID: ID of the author owning the key pair.
+//   - PublicKeyPEM: PEM encoded public key, published on the actor document.
+//   - PrivateKeyPEM: PEM encoded private key (never exposed).
+//   - CreatedAt: Timestamp when the key pair was generated.
+type ActorKey struct {
+	UserID        uint      `gorm:"primaryKey;autoIncrement:false" json:"user_id"`
+	PublicKeyPEM  string    `gorm:"type:text;not null" json:"public_key_pem"`
+	PrivateKeyPEM string    `gorm:"type:text;not null" json:"-"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "strings"
+
+// Tag represents a topic label attached to articles.
+//
+// Fields:
+//   - ID: Unique identifier for the tag.
+//   - Name: Normalized tag name: lowercase, with spaces replaced by dashes (max 50 characters, unique).
+type Tag struct {
+	ID   uint   `gorm:"primaryKey" json:"id"`
+	Name string `gorm:"size:50;not null;unique" json:"name"`
+}
+
+// NormalizeTagName converts a tag name to its stored form: lowercase and trimmed,
+// with inner whitespace replaced by single dashes.
+func NormalizeTagName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), "-")
+}
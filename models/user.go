@@ -1,21 +1,84 @@
 package models
 
-import "time"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// User roles.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin" // may use the admin endpoints, such as imports
+)
 
 // User represents a registered user in the system.
 //
 // Fields:
 //   - ID: Unique identifier for the user.
 //   - Username: User's unique username (max 100 characters, required).
-//   - Email: User's unique email address (max 255 characters, required).
+//   - Email: User's unique email address (max 255 characters, required, never shown publicly).
 //   - Password: Hashed password of the user (hidden from JSON responses).
+//   - DisplayName: Name shown on the author page (max 100 characters).
+//   - Bio: Short biography of the author.
+//   - AvatarURL: URL of the author's avatar image.
+//   - Website: URL of the author's personal website.
+//   - SocialLinks: Links to the author's social profiles, keyed by network.
+//   - Role: Either "user" or "admin" (defaults to "user"; admins are promoted in the database).
 //   - CreatedAt: Timestamp when the user account was created.
 //   - UpdatedAt: Timestamp when the user account was last updated.
 type User struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	Username  string    `gorm:"size:100;not null;unique" json:"username"`
-	Email     string    `gorm:"size:255;not null;unique" json:"email"`
-	Password  string    `gorm:"size:255;not null" json:"-"` // Hidden from JSON responses
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          uint        `gorm:"primaryKey" json:"id"`
+	Username    string      `gorm:"size:100;not null;unique" json:"username"`
+	Email       string      `gorm:"size:255;not null;unique" json:"email,omitempty"`
+	Password    string      `gorm:"size:255;not null" json:"-"` // Hidden from JSON responses
+	DisplayName string      `gorm:"size:100" json:"display_name"`
+	Bio         string      `gorm:"type:text" json:"bio"`
+	AvatarURL   string      `gorm:"size:500" json:"avatar_url"`
+	Website     string      `gorm:"size:255" json:"website"`
+	SocialLinks SocialLinks `gorm:"type:jsonb" json:"social_links"`
+	Role        string      `gorm:"size:20;not null;default:'user'" json:"role"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// HidePrivate clears the password and email so the user can be safely
+// included in public responses. It is a no-op on a nil user.
+func (u *User) HidePrivate() {
+	if u == nil {
+		return
+	}
+	u.Password = ""
+	u.Email = ""
+}
+
+// SocialLinks maps a social network name (e.g. "github") to a profile URL.
+// It is stored as a JSONB column.
+type SocialLinks map[string]string
+
+// Value implements driver.Valuer so the links can be written as JSON.
+func (s SocialLinks) Value() (driver.Value, error) {
+	if s == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(s)
+	return string(b), err
+}
+
+// Scan implements sql.Scanner so the links can be read from a JSON column.
+func (s *SocialLinks) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*s = SocialLinks{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.New("unsupported type for SocialLinks")
+	}
+	return json.Unmarshal(data, s)
 }
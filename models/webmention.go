@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Webmention verification statuses.
+const (
+	WebmentionStatusPending  = "pending"  // received, source not verified yet
+	WebmentionStatusVerified = "verified" // the source links to the article
+	WebmentionStatusInvalid  = "invalid"  // the source could not be fetched or does not link to the article
+)
+
+// Webmention represents a mention of one of our articles received from another site.
+//
+// Text metadata is plain text extracted from the source page and URLs are absolute
+// http(s) URLs, so everything is safe to display.
+//
+// Fields:
+//   - ID: Unique identifier for the webmention.
+//   - ArticleID: ID of the mentioned article.
+//   - Source: URL of the page mentioning the article.
+//   - Target: URL of the article that was mentioned.
+//   - Status: Verification status, one of the WebmentionStatus constants.
+//   - Hidden: Whether the article author hid the mention (moderation).
+//   - Type: Kind of mention: mention, reply, like, repost or bookmark.
+//   - Title: Title of the source page.
+//   - AuthorName: Name of the source page's author.
+//   - AuthorURL: URL of the source page's author.
+//   - AuthorPhoto: URL of the author's photo.
+//   - Excerpt: Short plain text excerpt of the source page.
+//   - Published: Publication date of the source page, if known.
+//   - VerifiedAt: Timestamp of the last successful verification.
+//   - CreatedAt: Timestamp when the webmention was first received.
+//   - UpdatedAt: Timestamp when the webmention was last received or verified.
+type Webmention struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	ArticleID   uint       `gorm:"not null;index" json:"article_id"`
+	Article     *Article   `gorm:"foreignKey:ArticleID;constraint:OnDelete:CASCADE" json:"-"`
+	Source      string     `gorm:"size:500;not null;uniqueIndex:idx_webmentions_source_target,priority:1" json:"source"`
+	Target      string     `gorm:"size:500;not null;uniqueIndex:idx_webmentions_source_target,priority:2" json:"target"`
+	Status      string     `gorm:"size:20;not null;default:'pending'" json:"status"`
+	Hidden      bool       `gorm:"not null;default:false" json:"hidden"`
+	Type        string     `gorm:"size:20" json:"type"`
+	Title       string     `gorm:"size:255" json:"title"`
+	AuthorName  string     `gorm:"size:255" json:"author_name"`
+	AuthorURL   string     `gorm:"size:500" json:"author_url"`
+	AuthorPhoto string     `gorm:"size:500" json:"author_photo"`
+	Excerpt     string     `gorm:"type:text" json:"excerpt"`
+	Published   *time.Time `json:"published"`
+	VerifiedAt  *time.Time `json:"verified_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
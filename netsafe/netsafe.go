@@ -0,0 +1,48 @@
+// Package netsafe guards the requests the server makes to URLs it was given by remote
+// parties, such as Webmention sources and ActivityPub actors, so they cannot make it
+// fetch internal URLs.
+package netsafe
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/jasen-devvv/mini-blog-backend/tracing"
+)
+
+// IsHTTPURL reports whether a URL is an absolute http or https URL.
+func IsHTTPURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// RefusePrivateAddresses is a dialer control function rejecting connections to
+// loopback, private, link-local and unspecified addresses.
+func RefusePrivateAddresses(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("refusing to connect to %s", address)
+	}
+	return nil
+}
+
+// NewHTTPClient returns a traced HTTP client with the given timeout, which refuses to
+// connect to private addresses (see RefusePrivateAddresses). The check applies to the
+// address actually dialed, so host names resolving to private addresses are refused too.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		Transport: tracing.Transport(&http.Transport{
+			Proxy:       http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{Timeout: 5 * time.Second, Control: RefusePrivateAddresses}).DialContext,
+		}),
+	}
+}
@@ -0,0 +1,69 @@
+package netsafe_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jasen-devvv/mini-blog-backend/netsafe"
+)
+
+func TestIsHTTPURL(t *testing.T) {
+	tests := map[string]bool{
+		"https://example.com/post": true,
+		"http://example.com":       true,
+		"HTTPS://example.com":      true,
+		"ftp://example.com":        false,
+		"javascript:alert(1)":      false,
+		"//example.com/post":       false,
+		"/post":                    false,
+		"https://":                 false,
+		"":                         false,
+	}
+	for rawURL, want := range tests {
+		if got := netsafe.IsHTTPURL(rawURL); got != want {
+			t.Errorf("IsHTTPURL(%q) = %v, want %v", rawURL, got, want)
+		}
+	}
+}
+
+func TestRefusePrivateAddresses(t *testing.T) {
+	tests := map[string]bool{
+		"93.184.216.34:443":      true,
+		"[2606:2800:220::1]:443": true,
+		"127.0.0.1:80":           false,
+		"[::1]:80":               false,
+		"10.0.0.1:80":            false,
+		"172.16.0.1:80":          false,
+		"192.168.1.1:80":         false,
+		"[fd00::1]:80":           false,
+		"169.254.169.254:80":     false,
+		"[fe80::1]:80":           false,
+		"0.0.0.0:80":             false,
+		"example.com:80":         false,
+		"no port":                false,
+	}
+	for address, allowed := range tests {
+		err := netsafe.RefusePrivateAddresses("tcp", address, nil)
+		if allowed && err != nil {
+			t.Errorf("%s refused: %v", address, err)
+		}
+		if !allowed && err == nil {
+			t.Errorf("%s allowed, want refused", address)
+		}
+	}
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the client reached a loopback server")
+	}))
+	defer server.Close()
+
+	_, err := netsafe.NewHTTPClient(time.Second).Get(server.URL)
+	if err == nil || !strings.Contains(err.Error(), "refusing to connect") {
+		t.Errorf("error = %v, want the connection refused", err)
+	}
+}
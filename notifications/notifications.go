@@ -0,0 +1,185 @@
+// Package notifications fans out in-app notifications to users' inboxes.
+//
+// Controllers call Notify when something happens that another user should hear
+// about. Notify checks the recipient's preferences and groups similar unread
+// notifications together, so that many reactions to the same article become a
+// single "5 people reacted to your article" entry. Each new or updated
+// notification is also published on the recipient's pub/sub topic for streaming.
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jasen-devvv/mini-blog-backend/config"
+	"github.com/jasen-devvv/mini-blog-backend/logging"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"github.com/jasen-devvv/mini-blog-backend/pubsub"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Event describes something that happened which may notify a user.
+//
+// Fields:
+//   - Type: Notification type, one of models.NotificationTypes.
+//   - RecipientID: ID of the user to notify.
+//   - ActorID: ID of the user who caused the event.
+//   - ArticleID: ID of the related article, if any.
+//   - CommentID: ID of the related comment, if any. For replies this is the comment replied to.
+//   - TargetType: For reactions, whether an "article" or a "comment" was reacted to.
+type Event struct {
+	Type        string
+	RecipientID uint
+	ActorID     uint
+	ArticleID   *uint
+	CommentID   *uint
+	TargetType  string
+}
+
+// Notify records an event in the recipient's inbox.
+//
+// Nothing is stored when users act on their own content or when the recipient
+// disabled the notification type. Otherwise the event joins the recipient's
+// unread notification with the same group key, or starts a new one.
+// Errors are logged rather than returned, so a failed notification never fails
+// the request that triggered it. Queries run within ctx, the context of that request.
+func Notify(ctx context.Context, event Event) {
+	if err := notify(ctx, event); err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to create notification", "type", event.Type, "recipient_id", event.RecipientID, "error", err)
+	}
+}
+
+func notify(ctx context.Context, event Event) error {
+	// Users are not notified about their own actions
+	if event.RecipientID == 0 || event.RecipientID == event.ActorID {
+		return nil
+	}
+
+	enabled, err := IsEnabled(ctx, event.RecipientID, event.Type)
+	if err != nil || !enabled {
+		return err
+	}
+
+	groupKey := groupKey(event)
+
+	db := config.DB.WithContext(ctx)
+
+	notification := models.Notification{
+		UserID:    event.RecipientID,
+		Type:      event.Type,
+		GroupKey:  groupKey,
+		ActorID:   event.ActorID,
+		ArticleID: event.ArticleID,
+		CommentID: event.CommentID,
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// Join the unread notification of the group, or start one. The unique index on unread
+		// groups makes concurrent events update the same row, which stays locked until commit.
+		err := tx.Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "user_id"}, {Name: "group_key"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "read_at IS NULL"}}},
+			DoUpdates:   clause.Assignments(map[string]interface{}{"actor_id": event.ActorID, "updated_at": time.Now()}),
+		}).Create(&notification).Error
+		if err != nil {
+			return err
+		}
+
+		// Only count each actor once per group
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.NotificationActor{NotificationID: notification.ID, ActorID: event.ActorID})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Model(&notification).Update("actor_count", tx.Model(&models.NotificationActor{}).
+			Select("COUNT(*)").Where("notification_id = ?", notification.ID)).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	// Stream the up-to-date notification to the recipient's connected clients
+	if err := db.Preload("Actor").First(&notification, notification.ID).Error; err != nil {
+		return err
+	}
+	notification.Actor.HidePrivate()
+	notification.Message = Message(notification)
+
+	return pubsub.Publish(Topic(notification.UserID), "notification", notification)
+}
+
+// Topic returns the pub/sub topic streaming a user's notifications.
+func Topic(userID uint) string {
+	return fmt.Sprintf("notifications:user:%d", userID)
+}
+
+// IsEnabled reports whether the user wants notifications of the given type.
+// Types without a stored preference are enabled.
+func IsEnabled(ctx context.Context, userID uint, notificationType string) (bool, error) {
+	var preference models.NotificationPreference
+	err := config.DB.WithContext(ctx).Where("user_id = ? AND type = ?", userID, notificationType).First(&preference).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return preference.Enabled, nil
+}
+
+// Message builds a human readable summary of a notification, such as
+// "alice and 4 others reacted to your article". The Actor must be loaded.
+func Message(notification models.Notification) string {
+	actor := notification.Actor.DisplayName
+	if actor == "" {
+		actor = notification.Actor.Username
+	}
+
+	switch notification.ActorCount {
+	case 1:
+	case 2:
+		actor += " and 1 other"
+	default:
+		actor += fmt.Sprintf(" and %d others", notification.ActorCount-1)
+	}
+
+	switch notification.Type {
+	case models.NotificationTypeComment:
+		return actor + " commented on your article"
+	case models.NotificationTypeReply:
+		return actor + " replied to your comment"
+	case models.NotificationTypeFollow:
+		return actor + " followed you"
+	case models.NotificationTypeReaction:
+		if notification.CommentID != nil {
+			return actor + " reacted to your comment"
+		}
+		return actor + " reacted to your article"
+	}
+	return actor
+}
+
+// groupKey returns the key that groups similar events together.
+func groupKey(event Event) string {
+	switch event.Type {
+	case models.NotificationTypeComment:
+		return fmt.Sprintf("comment:article:%d", derefID(event.ArticleID))
+	case models.NotificationTypeReply:
+		return fmt.Sprintf("reply:comment:%d", derefID(event.CommentID))
+	case models.NotificationTypeReaction:
+		if event.TargetType == models.ReactionTargetComment {
+			return fmt.Sprintf("reaction:comment:%d", derefID(event.CommentID))
+		}
+		return fmt.Sprintf("reaction:article:%d", derefID(event.ArticleID))
+	}
+	return event.Type
+}
+
+func derefID(id *uint) uint {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
@@ -0,0 +1,165 @@
+// Package presence tracks who is viewing or editing an article in real time
+// and manages advisory edit locks.
+//
+// Clients connect over a WebSocket per article (see controllers.Handler.ArticleLive).
+// The Hub keeps the connected clients of each article on this instance and
+// broadcasts the list of viewers and the current lock whenever it changes.
+// Edit locks are stored in the database, so they are honoured by every
+// instance and by UpdateArticle.
+package presence
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/jasen-devvv/mini-blog-backend/logging"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+)
+
+// Viewer describes a user connected to an article.
+//
+// Fields:
+//   - UserID: ID of the connected user.
+//   - Username: Username of the connected user.
+//   - DisplayName: Display name of the connected user.
+//   - Editing: Whether the user currently holds the edit lock.
+type Viewer struct {
+	UserID      uint   `json:"user_id"`
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+	Editing     bool   `json:"editing"`
+}
+
+// State is the message broadcast to every client of an article when presence changes.
+//
+// Fields:
+//   - Type: Always "presence".
+//   - Viewers: Users connected to the article, one entry per user.
+//   - Lock: The current edit lock, or null when the article is not locked.
+type State struct {
+	Type    string              `json:"type"`
+	Viewers []Viewer            `json:"viewers"`
+	Lock    *models.ArticleLock `json:"lock"`
+}
+
+// Client is a single WebSocket connection to an article.
+type Client struct {
+	Token   string
+	User    models.User
+	Editing bool
+	Send    chan interface{}
+}
+
+// LockStore reads and releases the edit locks the hub reports.
+type LockStore interface {
+	// CurrentLock returns the valid lock on an article, or nil when it is not locked.
+	CurrentLock(ctx context.Context, articleID uint) (*models.ArticleLock, error)
+	// ReleaseLock releases the lock held by the session identified by token, if any.
+	ReleaseLock(ctx context.Context, token string) error
+}
+
+// DatabaseLocks is the LockStore of the locks in the database (see CurrentLock and ReleaseLock).
+var DatabaseLocks LockStore = databaseLocks{}
+
+type databaseLocks struct{}
+
+func (databaseLocks) CurrentLock(ctx context.Context, articleID uint) (*models.ArticleLock, error) {
+	return CurrentLock(ctx, articleID)
+}
+
+func (databaseLocks) ReleaseLock(ctx context.Context, token string) error {
+	return ReleaseLock(ctx, token)
+}
+
+// Hub keeps track of the clients connected to each article.
+type Hub struct {
+	mu    sync.Mutex
+	rooms map[uint]map[*Client]struct{}
+	locks LockStore
+}
+
+// DefaultHub is the hub shared by the live article endpoint.
+var DefaultHub = NewHub(DatabaseLocks)
+
+// NewHub creates an empty hub reporting the locks of locks.
+func NewHub(locks LockStore) *Hub {
+	return &Hub{rooms: make(map[uint]map[*Client]struct{}), locks: locks}
+}
+
+// Join adds a client to an article and broadcasts the new state.
+func (h *Hub) Join(ctx context.Context, articleID uint, client *Client) {
+	h.mu.Lock()
+	if h.rooms[articleID] == nil {
+		h.rooms[articleID] = make(map[*Client]struct{})
+	}
+	h.rooms[articleID][client] = struct{}{}
+	h.mu.Unlock()
+
+	h.Broadcast(ctx, articleID)
+}
+
+// Leave removes a client from an article, releases its edit lock and broadcasts the new state.
+func (h *Hub) Leave(ctx context.Context, articleID uint, client *Client) {
+	h.mu.Lock()
+	delete(h.rooms[articleID], client)
+	if len(h.rooms[articleID]) == 0 {
+		delete(h.rooms, articleID)
+	}
+	h.mu.Unlock()
+
+	if err := h.locks.ReleaseLock(ctx, client.Token); err != nil {
+		logging.FromContext(ctx).Error("failed to release edit lock", "article_id", articleID, "error", err)
+	}
+	h.Broadcast(ctx, articleID)
+}
+
+// SetEditing marks whether a client holds the edit lock and broadcasts the new state.
+func (h *Hub) SetEditing(ctx context.Context, articleID uint, client *Client, editing bool) {
+	h.mu.Lock()
+	client.Editing = editing
+	h.mu.Unlock()
+
+	h.Broadcast(ctx, articleID)
+}
+
+// Broadcast sends the current viewers and lock of an article to all of its clients.
+// Clients that are too slow to keep up skip the update; the next one supersedes it.
+// When the lock cannot be read, the state is sent without it.
+func (h *Hub) Broadcast(ctx context.Context, articleID uint) {
+	lock, err := h.locks.CurrentLock(ctx, articleID)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to load edit lock", "article_id", articleID, "error", err)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// One entry per user, even with several open connections
+	viewers := map[uint]*Viewer{}
+	for client := range h.rooms[articleID] {
+		viewer, ok := viewers[client.User.ID]
+		if !ok {
+			viewer = &Viewer{
+				UserID:      client.User.ID,
+				Username:    client.User.Username,
+				DisplayName: client.User.DisplayName,
+			}
+			viewers[client.User.ID] = viewer
+		}
+		viewer.Editing = viewer.Editing || client.Editing
+	}
+
+	state := State{Type: "presence", Viewers: make([]Viewer, 0, len(viewers)), Lock: lock}
+	for _, viewer := range viewers {
+		state.Viewers = append(state.Viewers, *viewer)
+	}
+	sort.Slice(state.Viewers, func(i, j int) bool { return state.Viewers[i].UserID < state.Viewers[j].UserID })
+
+	for client := range h.rooms[articleID] {
+		select {
+		case client.Send <- state:
+		default:
+		}
+	}
+}
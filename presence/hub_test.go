@@ -0,0 +1,167 @@
+package presence_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jasen-devvv/mini-blog-backend/logging"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"github.com/jasen-devvv/mini-blog-backend/presence"
+)
+
+// fakeLocks is a LockStore holding at most one lock per article.
+type fakeLocks struct {
+	mu       sync.Mutex
+	locks    map[uint]*models.ArticleLock
+	released []string
+	err      error
+}
+
+func (l *fakeLocks) CurrentLock(ctx context.Context, articleID uint) (*models.ArticleLock, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.locks[articleID], l.err
+}
+
+func (l *fakeLocks) ReleaseLock(ctx context.Context, token string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.released = append(l.released, token)
+	for articleID, lock := range l.locks {
+		if lock.Token == token {
+			delete(l.locks, articleID)
+		}
+	}
+	return l.err
+}
+
+func newClient(id uint, username string) *presence.Client {
+	return &presence.Client{
+		Token: username + "-token",
+		User:  models.User{ID: id, Username: username},
+		Send:  make(chan interface{}, 16),
+	}
+}
+
+// last returns the newest state sent to client, failing when there is none.
+func last(t *testing.T, client *presence.Client) presence.State {
+	t.Helper()
+	var state *presence.State
+	for {
+		select {
+		case msg := <-client.Send:
+			s := msg.(presence.State)
+			state = &s
+			continue
+		default:
+		}
+		break
+	}
+	if state == nil {
+		t.Fatalf("%s received no state", client.User.Username)
+	}
+	return *state
+}
+
+func TestHubPresence(t *testing.T) {
+	ctx := context.Background()
+	locks := &fakeLocks{locks: map[uint]*models.ArticleLock{}}
+	hub := presence.NewHub(locks)
+
+	alice, aliceTab, bob := newClient(1, "alice"), newClient(1, "alice"), newClient(2, "bob")
+	hub.Join(ctx, 10, alice)
+	hub.Join(ctx, 10, aliceTab)
+	hub.Join(ctx, 10, bob)
+
+	// Users appear once, however many connections they have
+	state := last(t, alice)
+	if state.Type != "presence" || len(state.Viewers) != 2 || state.Viewers[0].Username != "alice" || state.Viewers[1].Username != "bob" {
+		t.Fatalf("state = %+v, want alice and bob", state)
+	}
+	if state.Lock != nil {
+		t.Errorf("lock = %+v, want none", state.Lock)
+	}
+
+	// Editing shows on the user and the lock is reported
+	locks.locks[10] = &models.ArticleLock{ArticleID: 10, UserID: 1, Token: aliceTab.Token}
+	hub.SetEditing(ctx, 10, aliceTab, true)
+	state = last(t, bob)
+	if !state.Viewers[0].Editing || state.Viewers[1].Editing || state.Lock == nil || state.Lock.UserID != 1 {
+		t.Fatalf("state = %+v, want alice editing", state)
+	}
+
+	// Other articles are not told
+	carol := newClient(3, "carol")
+	hub.Join(ctx, 11, carol)
+	last(t, carol)
+	hub.Broadcast(ctx, 10)
+	if len(carol.Send) != 0 {
+		t.Errorf("carol received an update of another article")
+	}
+
+	// Leaving releases the session's lock
+	hub.Leave(ctx, 10, aliceTab)
+	state = last(t, bob)
+	if len(locks.released) != 1 || locks.released[0] != aliceTab.Token {
+		t.Errorf("released = %v, want the tab's token", locks.released)
+	}
+	if state.Lock != nil || state.Viewers[0].Editing {
+		t.Errorf("state = %+v, want the lock released", state)
+	}
+	hub.Leave(ctx, 10, alice)
+	if state = last(t, bob); len(state.Viewers) != 1 || state.Viewers[0].Username != "bob" {
+		t.Errorf("viewers = %+v, want bob alone", state.Viewers)
+	}
+}
+
+func TestHubSlowClient(t *testing.T) {
+	ctx := context.Background()
+	hub := presence.NewHub(&fakeLocks{locks: map[uint]*models.ArticleLock{}})
+
+	slow := &presence.Client{Token: "slow", User: models.User{ID: 1}, Send: make(chan interface{}, 1)}
+	fast := newClient(2, "fast")
+	hub.Join(ctx, 10, slow)
+	hub.Join(ctx, 10, fast)
+
+	// Updates to a full buffer are dropped instead of blocking everyone else
+	for i := 0; i < 3; i++ {
+		hub.Broadcast(ctx, 10)
+	}
+	if len(slow.Send) != 1 {
+		t.Errorf("slow client has %d queued updates, want 1", len(slow.Send))
+	}
+	if state := last(t, fast); len(state.Viewers) != 2 {
+		t.Errorf("viewers = %+v", state.Viewers)
+	}
+}
+
+func TestHubLockError(t *testing.T) {
+	var logs bytes.Buffer
+	ctx := logging.WithLogger(context.Background(), slog.New(slog.NewTextHandler(&logs, nil)))
+	locks := &fakeLocks{locks: map[uint]*models.ArticleLock{}, err: errors.New("database is down")}
+	hub := presence.NewHub(locks)
+
+	// The state is still sent, without the lock
+	client, other := newClient(1, "alice"), newClient(2, "bob")
+	hub.Join(ctx, 10, client)
+	hub.Join(ctx, 10, other)
+	if state := last(t, client); len(state.Viewers) != 2 || state.Lock != nil {
+		t.Errorf("state = %+v, want both viewers and no lock", state)
+	}
+
+	hub.Leave(ctx, 10, client)
+	if len(locks.released) != 1 {
+		t.Errorf("released = %v, want the session released despite the error", locks.released)
+	}
+
+	for _, message := range []string{"failed to load edit lock", "failed to release edit lock"} {
+		if !strings.Contains(logs.String(), message) {
+			t.Errorf("logs do not contain %q:\n%s", message, logs.String())
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package presence_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jasen-devvv/mini-blog-backend/config"
+	"github.com/jasen-devvv/mini-blog-backend/internal/testutil"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"github.com/jasen-devvv/mini-blog-backend/presence"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(testutil.RunWithDatabase(m))
+}
+
+// setupLocks points config.DB at a fresh schema with an article written by alice and
+// returns the article and the users alice and bob.
+func setupLocks(t *testing.T) (models.Article, models.User, models.User) {
+	t.Helper()
+	db := testutil.Database(t)
+	previous := config.DB
+	config.DB = db
+	t.Cleanup(func() { config.DB = previous })
+
+	alice := models.User{Username: "alice", Email: "alice@example.com", Password: "x"}
+	bob := models.User{Username: "bob", Email: "bob@example.com", Password: "x"}
+	for _, user := range []*models.User{&alice, &bob} {
+		if err := db.Create(user).Error; err != nil {
+			t.Fatalf("creating %s: %v", user.Username, err)
+		}
+	}
+	article := models.Article{Title: "Shared", Content: "Body", Status: models.ArticleStatusDraft, UserID: alice.ID}
+	if err := db.Create(&article).Error; err != nil {
+		t.Fatalf("creating article: %v", err)
+	}
+	return article, alice, bob
+}
+
+// expire moves the expiry of the article's lock into the past.
+func expire(t *testing.T, articleID uint) {
+	t.Helper()
+	err := config.DB.Model(&models.ArticleLock{}).Where("article_id = ?", articleID).
+		Update("expires_at", time.Now().Add(-time.Second)).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLocks(t *testing.T) {
+	article, alice, bob := setupLocks(t)
+	ctx := context.Background()
+	aliceToken, bobToken := presence.NewToken(), presence.NewToken()
+
+	acquire := func(userID uint, token string) bool {
+		t.Helper()
+		acquired, err := presence.AcquireLock(ctx, article.ID, userID, token)
+		if err != nil {
+			t.Fatalf("acquiring: %v", err)
+		}
+		return acquired
+	}
+	current := func() *models.ArticleLock {
+		t.Helper()
+		lock, err := presence.CurrentLock(ctx, article.ID)
+		if err != nil {
+			t.Fatalf("reading the lock: %v", err)
+		}
+		return lock
+	}
+
+	if lock := current(); lock != nil {
+		t.Fatalf("unlocked article has lock %+v", lock)
+	}
+
+	// One session holds the lock at a time; the holder may take it again
+	if !acquire(alice.ID, aliceToken) {
+		t.Fatal("alice could not lock the unlocked article")
+	}
+	if acquire(bob.ID, bobToken) {
+		t.Fatal("bob took a lock alice holds")
+	}
+	if !acquire(alice.ID, aliceToken) {
+		t.Fatal("alice could not renew her lock")
+	}
+	lock := current()
+	if lock == nil || lock.UserID != alice.ID || lock.User.Username != "alice" || lock.User.Email != "" {
+		t.Fatalf("lock = %+v, want alice's without private fields", lock)
+	}
+
+	// Heartbeats extend the lock
+	expiresAt := lock.ExpiresAt
+	time.Sleep(10 * time.Millisecond)
+	if held, err := presence.RefreshLock(ctx, aliceToken); err != nil || !held {
+		t.Fatalf("refreshing = %v, %v, want the lock held", held, err)
+	}
+	if lock := current(); !lock.ExpiresAt.After(expiresAt) {
+		t.Errorf("refreshed lock expires at %v, not after %v", lock.ExpiresAt, expiresAt)
+	}
+	if held, _ := presence.RefreshLock(ctx, bobToken); held {
+		t.Error("bob refreshed a lock he does not hold")
+	}
+
+	// Expired locks count as released and can be taken over
+	expire(t, article.ID)
+	if lock := current(); lock != nil {
+		t.Fatalf("expired lock reported: %+v", lock)
+	}
+	if !acquire(bob.ID, bobToken) {
+		t.Fatal("bob could not take over the expired lock")
+	}
+	if lock := current(); lock == nil || lock.UserID != bob.ID {
+		t.Fatalf("lock = %+v, want bob's", lock)
+	}
+	if held, _ := presence.RefreshLock(ctx, aliceToken); held {
+		t.Error("alice's heartbeat renewed the lock bob took over")
+	}
+
+	// Releasing only affects the holding session
+	if err := presence.ReleaseLock(ctx, aliceToken); err != nil {
+		t.Fatal(err)
+	}
+	if lock := current(); lock == nil {
+		t.Fatal("alice released bob's lock")
+	}
+	if err := presence.ReleaseLock(ctx, bobToken); err != nil {
+		t.Fatal(err)
+	}
+	if lock := current(); lock != nil {
+		t.Fatalf("released lock still reported: %+v", lock)
+	}
+}
@@ -0,0 +1,93 @@
+package pubsub
+
+import "sync"
+
+// subscriberBuffer is the number of messages a subscriber can lag behind
+// before new messages are dropped for it.
+const subscriberBuffer = 32
+
+// MemoryBroker is an in-process Broker.
+// It keeps the last historySize messages of each topic for resume.
+type MemoryBroker struct {
+	mu          sync.RWMutex
+	historySize int
+	subscribers map[string]map[chan Message]struct{}
+	history     map[string][]Message
+}
+
+// NewMemoryBroker creates an in-process broker keeping historySize messages per topic.
+func NewMemoryBroker(historySize int) *MemoryBroker {
+	return &MemoryBroker{
+		historySize: historySize,
+		subscribers: make(map[string]map[chan Message]struct{}),
+		history:     make(map[string][]Message),
+	}
+}
+
+// Publish records the message in the topic history and delivers it to every subscriber.
+// Subscribers that are too slow to keep up miss the message instead of blocking the publisher.
+// A message without an ID gets one from the current time.
+func (b *MemoryBroker) Publish(msg Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if msg.ID == "" {
+		msg.ID = nextID()
+	}
+
+	history := append(b.history[msg.Topic], msg)
+	if len(history) > b.historySize {
+		history = history[len(history)-b.historySize:]
+	}
+	b.history[msg.Topic] = history
+
+	for ch := range b.subscribers[msg.Topic] {
+		select {
+		case ch <- msg:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Subscribe registers a new subscriber for the topic.
+func (b *MemoryBroker) Subscribe(topic string) (<-chan Message, func()) {
+	ch := make(chan Message, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.subscribers[topic] == nil {
+		b.subscribers[topic] = make(map[chan Message]struct{})
+	}
+	b.subscribers[topic][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers[topic], ch)
+			if len(b.subscribers[topic]) == 0 {
+				delete(b.subscribers, topic)
+			}
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+
+	return ch, cancel
+}
+
+// Since returns the buffered messages of the topic newer than lastID.
+func (b *MemoryBroker) Since(topic string, lastID string) []Message {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var messages []Message
+	for _, msg := range b.history[topic] {
+		if After(msg.ID, lastID) {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
@@ -0,0 +1,150 @@
+package pubsub
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// postgresChannel is the LISTEN/NOTIFY channel shared by all instances.
+	postgresChannel = "blog_events"
+
+	// publishLockID identifies the advisory lock that orders publications.
+	publishLockID = 7_261_432_020
+
+	// messageRetention is how long relayed messages stay in the pubsub_messages table.
+	messageRetention = 10 * time.Minute
+)
+
+// PostgresBroker shares messages between application instances through
+// Postgres LISTEN/NOTIFY.
+//
+// Published messages are stored in the pubsub_messages table, whose sequence gives
+// them IDs shared by every instance, and only their ID is sent with pg_notify, so
+// payloads are not limited by the notification size. Every instance (including the
+// publisher) listens on the channel, loads the notified messages and hands them to a
+// local MemoryBroker, which serves its subscribers and keeps the resume history.
+//
+// Publications are serialized with an advisory lock, so messages are committed, and
+// therefore notified, in ID order: a stream never skips a message because one with a
+// greater ID arrived first.
+type PostgresBroker struct {
+	pool  *pgxpool.Pool
+	local *MemoryBroker
+}
+
+// NewPostgresBroker connects to the database and starts listening for messages
+// until ctx is cancelled. The listener reconnects automatically when the
+// connection drops. Messages older than messageRetention are deleted periodically.
+func NewPostgresBroker(ctx context.Context, dsn string, historySize int) (*PostgresBroker, error) {
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	broker := &PostgresBroker{pool: pool, local: NewMemoryBroker(historySize)}
+	go broker.listen(ctx)
+	go broker.cleanup(ctx)
+
+	return broker, nil
+}
+
+// Publish stores the message and notifies all instances of its ID. The ID of msg is
+// replaced by the one the database assigns.
+func (b *PostgresBroker) Publish(msg Message) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return pgx.BeginFunc(ctx, b.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", publishLockID); err != nil {
+			return err
+		}
+
+		var id int64
+		if err := tx.QueryRow(ctx, "INSERT INTO pubsub_messages (topic, event, data) VALUES ($1, $2, $3) RETURNING id",
+			msg.Topic, msg.Event, string(msg.Data)).Scan(&id); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(ctx, "SELECT pg_notify($1, $2)", postgresChannel, strconv.FormatInt(id, 10))
+		return err
+	})
+}
+
+// Subscribe registers a subscriber on the local broker.
+func (b *PostgresBroker) Subscribe(topic string) (<-chan Message, func()) {
+	return b.local.Subscribe(topic)
+}
+
+// Since returns the messages received by this instance after lastID.
+func (b *PostgresBroker) Since(topic string, lastID string) []Message {
+	return b.local.Since(topic, lastID)
+}
+
+// listen relays notifications to the local broker until ctx is cancelled,
+// retrying with a fresh connection whenever listening fails.
+func (b *PostgresBroker) listen(ctx context.Context) {
+	defer b.pool.Close()
+
+	for ctx.Err() == nil {
+		if err := b.listenOnce(ctx); err != nil && ctx.Err() == nil {
+			slog.Warn("pubsub listener failed, reconnecting", "error", err)
+			time.Sleep(time.Second)
+		}
+	}
+}
+
+// listenOnce holds one pooled connection and relays its notifications until an error occurs.
+func (b *PostgresBroker) listenOnce(ctx context.Context) error {
+	conn, err := b.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+postgresChannel); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		msg := Message{ID: notification.Payload}
+		var data string
+		err = b.pool.QueryRow(ctx, "SELECT topic, event, data::text FROM pubsub_messages WHERE id = $1", notification.Payload).
+			Scan(&msg.Topic, &msg.Event, &data)
+		if err != nil {
+			slog.Error("pubsub failed to load a message", "id", notification.Payload, "error", err)
+			continue
+		}
+		msg.Data = []byte(data)
+		b.local.Publish(msg)
+	}
+}
+
+// cleanup deletes messages older than messageRetention every minute until ctx is cancelled.
+func (b *PostgresBroker) cleanup(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		_, err := b.pool.Exec(ctx, "DELETE FROM pubsub_messages WHERE created_at < now() - make_interval(secs => $1)", messageRetention.Seconds())
+		if err != nil && ctx.Err() == nil {
+			slog.Error("failed to clean up pubsub messages", "error", err)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package ratelimit
+
+import "time"
+
+// ResultOf exposes result to the tests.
+var ResultOf = result
+
+// NewMemoryStoreAt returns an empty in-memory store reading the time from clock.
+func NewMemoryStoreAt(clock func() time.Time) *MemoryStore {
+	s := NewMemoryStore()
+	s.now = clock
+	s.lastSweep = clock()
+	return s
+}
+
+// Refill returns the tokens at now of a bucket of policy left with tokens at updated.
+func Refill(policy Policy, tokens float64, updated, now time.Time) float64 {
+	b := bucket{policy: policy, tokens: tokens, updated: updated}
+	return b.refill(now)
+}
+
+// Len returns the number of buckets the store keeps.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.buckets)
+}
@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often a MemoryStore forgets buckets that refilled completely.
+const sweepInterval = time.Minute
+
+// MemoryStore keeps buckets in memory, limiting the requests each instance receives.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// bucket holds the tokens left at the time of the last request.
+type bucket struct {
+	policy  Policy
+	tokens  float64
+	updated time.Time
+}
+
+// refill returns the tokens of the bucket at now.
+func (b *bucket) refill(now time.Time) float64 {
+	return math.Min(float64(b.policy.Limit), b.tokens+now.Sub(b.updated).Seconds()*b.policy.rate())
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*bucket{}, lastSweep: time.Now(), now: time.Now}
+}
+
+// Take takes a token from the bucket of key under policy.
+func (s *MemoryStore) Take(ctx context.Context, policy Policy, key string) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	id := policy.Name + ":" + key
+	b, ok := s.buckets[id]
+	if !ok {
+		b = &bucket{policy: policy, tokens: float64(policy.Limit), updated: now}
+		s.buckets[id] = b
+	}
+
+	b.policy = policy
+	b.tokens = b.refill(now)
+	b.updated = now
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return result(policy, b.tokens, allowed), nil
+}
+
+// sweep forgets the buckets that refilled completely, as a new bucket is the same as a
+// full one. The caller holds the lock.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for id, b := range s.buckets {
+		if b.refill(now) >= float64(b.policy.Limit) {
+			delete(s.buckets, id)
+		}
+	}
+}
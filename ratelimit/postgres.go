@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// takeQuery refills a bucket for the time elapsed since its last request and takes a
+// token when one is left, in a single statement so concurrent requests from several
+// instances cannot both take the last token. New buckets start full, less the token
+// taken. A bucket is certainly full again one period after its last request, so it
+// expires then.
+const takeQuery = `
+INSERT INTO rate_limit_buckets AS b (key, tokens, allowed, updated_at, expires_at)
+VALUES (@key, CAST(@limit AS double precision) - 1, true, now(), now() + make_interval(secs => @period))
+ON CONFLICT (key) DO UPDATE SET
+    tokens = CASE WHEN ` + refilled + ` >= 1 THEN ` + refilled + ` - 1 ELSE ` + refilled + ` END,
+    allowed = ` + refilled + ` >= 1,
+    updated_at = now(),
+    expires_at = now() + make_interval(secs => @period)
+RETURNING tokens, allowed`
+
+// refilled is the number of tokens in an existing bucket before the request.
+const refilled = `LEAST(CAST(@limit AS double precision), b.tokens + ` +
+	`GREATEST(CAST(EXTRACT(EPOCH FROM now() - b.updated_at) AS double precision), 0) * CAST(@rate AS double precision))`
+
+// PostgresStore keeps buckets in the rate_limit_buckets table, sharing the limits between
+// application instances.
+type PostgresStore struct {
+	db *gorm.DB
+}
+
+// NewPostgresStore returns a store keeping buckets in the database.
+func NewPostgresStore(db *gorm.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// Take takes a token from the bucket of key under policy.
+func (s *PostgresStore) Take(ctx context.Context, policy Policy, key string) (Result, error) {
+	var bucket struct {
+		Tokens  float64
+		Allowed bool
+	}
+	err := s.db.WithContext(ctx).Raw(takeQuery, map[string]interface{}{
+		"key":    policy.Name + ":" + key,
+		"limit":  float64(policy.Limit),
+		"period": policy.Period.Seconds(),
+		"rate":   policy.rate(),
+	}).Scan(&bucket).Error
+	if err != nil {
+		return Result{}, err
+	}
+	return result(policy, bucket.Tokens, bucket.Allowed), nil
+}
+
+// Cleanup deletes the buckets that refilled completely, as a new bucket is the same as a
+// full one.
+func (s *PostgresStore) Cleanup(ctx context.Context) error {
+	return s.db.WithContext(ctx).Exec("DELETE FROM rate_limit_buckets WHERE expires_at < now()").Error
+}
+
+// RunCleanup calls Cleanup every interval until ctx is done.
+func (s *PostgresStore) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.Cleanup(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("failed to clean up rate limit buckets", "error", err)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package ratelimit_test
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jasen-devvv/mini-blog-backend/internal/testutil"
+	"github.com/jasen-devvv/mini-blog-backend/ratelimit"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(testutil.RunWithDatabase(m))
+}
+
+func TestPostgresStore(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.Database(t)
+	store := ratelimit.NewPostgresStore(db)
+
+	// Three requests an hour: a token every 20 minutes
+	policy := ratelimit.Policy{Name: "test", Limit: 3, Period: time.Hour, Key: ratelimit.KeyIP}
+	take := func(key string) ratelimit.Result {
+		t.Helper()
+		result, err := store.Take(ctx, policy, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+	rewind := func(column string, by time.Duration) {
+		t.Helper()
+		query := "UPDATE rate_limit_buckets SET " + column + " = " + column + " - make_interval(secs => ?) WHERE key = ?"
+		if err := db.Exec(query, by.Seconds(), "test:a").Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A new bucket allows a burst up to the limit
+	for i := 2; i >= 0; i-- {
+		if result := take("a"); !result.Allowed || result.Remaining != i {
+			t.Fatalf("burst request %d = %+v, want allowed with %d remaining", 3-i, result, i)
+		}
+	}
+	result := take("a")
+	if result.Allowed || result.RetryAfter <= 19*time.Minute || result.RetryAfter > 20*time.Minute {
+		t.Fatalf("request over the limit = %+v, want rejected for 20 minutes", result)
+	}
+
+	// The bucket refills for the time elapsed since the last request, up to the limit
+	rewind("updated_at", 40*time.Minute)
+	if result := take("a"); !result.Allowed || result.Remaining != 1 {
+		t.Errorf("request 40 minutes later = %+v, want allowed with 1 remaining", result)
+	}
+	rewind("updated_at", 10*time.Hour)
+	if result := take("a"); !result.Allowed || result.Remaining != 2 {
+		t.Errorf("request 10 hours later = %+v, want allowed with 2 remaining", result)
+	}
+
+	// Keys have buckets of their own
+	if result := take("b"); !result.Allowed || result.Remaining != 2 {
+		t.Errorf("other key = %+v, want a full bucket", result)
+	}
+
+	// Cleanup deletes the buckets that expired
+	rewind("expires_at", 2*time.Hour)
+	if err := store.Cleanup(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	if err := db.Raw("SELECT key FROM rate_limit_buckets ORDER BY key").Scan(&keys).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0] != "test:b" {
+		t.Errorf("buckets after cleanup = %v, want test:b", keys)
+	}
+}
+
+func TestPostgresStoreConcurrent(t *testing.T) {
+	ctx := context.Background()
+	store := ratelimit.NewPostgresStore(testutil.Database(t))
+	policy := ratelimit.Policy{Name: "test", Limit: 5, Period: time.Hour, Key: ratelimit.KeyIP}
+
+	// Concurrent requests never take more tokens than the bucket holds
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		allowed int
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := store.Take(ctx, policy, "a")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if result.Allowed {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if allowed != 5 {
+		t.Errorf("allowed %d of 20 concurrent requests, want 5", allowed)
+	}
+}
@@ -0,0 +1,137 @@
+// Package ratelimit limits how often clients call an endpoint with token buckets.
+//
+// Every client gets a bucket per policy, holding up to Policy.Limit tokens and refilled
+// at Policy.Limit tokens per Policy.Period. Each request takes a token; requests finding
+// the bucket empty are rejected until it refills. Clients can burst up to the limit, then
+// continue at the refill rate.
+//
+// Buckets are kept in a Store. The default MemoryStore limits each instance on its own;
+// PostgresStore shares the buckets between instances.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Key kinds, identifying whose requests share a bucket.
+const (
+	// KeyIP limits each client IP address.
+	KeyIP = "ip"
+	// KeyUser limits each authenticated user, and anonymous requests by IP address.
+	KeyUser = "user"
+	// KeyToken limits each authenticated bearer token, and unauthenticated requests by
+	// IP address, so clients cannot get a fresh bucket by sending made-up tokens.
+	KeyToken = "token"
+)
+
+// Policy is the limit applied to a group of routes.
+//
+// Fields:
+//   - Name: Name of the policy, referenced by the routes it applies to.
+//   - Limit: Size of the bucket: how many requests a client can make in a burst.
+//   - Period: Time it takes an empty bucket to refill completely.
+//   - Key: Whose requests share a bucket: KeyIP, KeyUser or KeyToken.
+type Policy struct {
+	Name   string
+	Limit  int
+	Period time.Duration
+	Key    string
+}
+
+// rate returns how many tokens the bucket regains per second.
+func (p Policy) rate() float64 {
+	return float64(p.Limit) / p.Period.Seconds()
+}
+
+// ParsePolicies reads a comma-separated list of policies written as
+// "name=limit/period:key", for example "login=10/1m:ip,comment=20/1h:user". The key is
+// optional and defaults to KeyIP. An empty list disables rate limiting.
+func ParsePolicies(value string) (map[string]Policy, error) {
+	policies := map[string]Policy{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, spec, ok := strings.Cut(entry, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("rate limit %q: expected name=limit/period", entry)
+		}
+		spec, key, hasKey := strings.Cut(spec, ":")
+		if !hasKey {
+			key = KeyIP
+		}
+		limitText, periodText, ok := strings.Cut(spec, "/")
+		if !ok {
+			return nil, fmt.Errorf("rate limit %q: expected name=limit/period", entry)
+		}
+
+		limit, err := strconv.Atoi(limitText)
+		if err != nil || limit < 1 {
+			return nil, fmt.Errorf("rate limit %q: limit must be a positive number", entry)
+		}
+		period, err := time.ParseDuration(periodText)
+		if err != nil || period <= 0 {
+			return nil, fmt.Errorf("rate limit %q: period must be a positive duration", entry)
+		}
+		if key != KeyIP && key != KeyUser && key != KeyToken {
+			return nil, fmt.Errorf("rate limit %q: key must be ip, user or token", entry)
+		}
+		if _, exists := policies[name]; exists {
+			return nil, fmt.Errorf("rate limit %q: policy %s is defined twice", entry, name)
+		}
+
+		policies[name] = Policy{Name: name, Limit: limit, Period: period, Key: key}
+	}
+	return policies, nil
+}
+
+// Result is the outcome of taking a token from a bucket.
+//
+// Fields:
+//   - Allowed: Whether the request may proceed.
+//   - Remaining: Requests the client can still make right away.
+//   - Reset: Time until the bucket is full again.
+//   - RetryAfter: Time until the next request is allowed; zero when Allowed.
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// result describes a bucket of policy left with tokens after a request.
+func result(policy Policy, tokens float64, allowed bool) Result {
+	rate := policy.rate()
+	r := Result{
+		Allowed:   allowed,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(policy.Limit) - tokens) / rate),
+	}
+	if !allowed {
+		r.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	return r
+}
+
+// seconds converts a number of seconds to a duration.
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Max(s, 0) * float64(time.Second))
+}
+
+// Store keeps the token buckets.
+type Store interface {
+	// Take takes a token from the bucket of key under policy, creating a full bucket
+	// when there is none.
+	Take(ctx context.Context, policy Policy, key string) (Result, error)
+}
+
+// Default is the store the rate limit middleware uses.
+// It can be replaced at startup, for example with a PostgresStore.
+var Default Store = NewMemoryStore()
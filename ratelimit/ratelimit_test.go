@@ -0,0 +1,134 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jasen-devvv/mini-blog-backend/ratelimit"
+)
+
+// tenPerTenSeconds refills one token per second.
+var tenPerTenSeconds = ratelimit.Policy{Name: "test", Limit: 10, Period: 10 * time.Second, Key: ratelimit.KeyIP}
+
+func TestResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		tokens  float64
+		allowed bool
+		want    ratelimit.Result
+	}{
+		{"first request", 9, true, ratelimit.Result{Allowed: true, Remaining: 9, Reset: time.Second}},
+		{"partial token", 2.5, true, ratelimit.Result{Allowed: true, Remaining: 2, Reset: 7500 * time.Millisecond}},
+		{"last token", 0, true, ratelimit.Result{Allowed: true, Remaining: 0, Reset: 10 * time.Second}},
+		{"empty", 0, false, ratelimit.Result{Remaining: 0, Reset: 10 * time.Second, RetryAfter: time.Second}},
+		{"almost refilled", 0.75, false, ratelimit.Result{Remaining: 0, Reset: 9250 * time.Millisecond, RetryAfter: 250 * time.Millisecond}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ratelimit.ResultOf(tenPerTenSeconds, test.tokens, test.allowed); got != test.want {
+				t.Errorf("result = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRefill(t *testing.T) {
+	updated := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		tokens  float64
+		elapsed time.Duration
+		want    float64
+	}{
+		{"no time elapsed", 2, 0, 2},
+		{"one token per second", 2, 3 * time.Second, 5},
+		{"fraction of a second", 0, 500 * time.Millisecond, 0.5},
+		{"capped at the limit", 2, time.Hour, 10},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ratelimit.Refill(tenPerTenSeconds, test.tokens, updated, updated.Add(test.elapsed)); got != test.want {
+				t.Errorf("tokens = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+// clock is a time the tests move forward by hand.
+type clock struct{ now time.Time }
+
+func (c *clock) Now() time.Time          { return c.now }
+func (c *clock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	c := &clock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store := ratelimit.NewMemoryStoreAt(c.Now)
+
+	take := func(policy ratelimit.Policy, key string) ratelimit.Result {
+		t.Helper()
+		result, err := store.Take(ctx, policy, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	// A new bucket allows a burst up to the limit
+	for i := 9; i >= 0; i-- {
+		if result := take(tenPerTenSeconds, "a"); !result.Allowed || result.Remaining != i {
+			t.Fatalf("burst request %d = %+v, want allowed with %d remaining", 10-i, result, i)
+		}
+	}
+	if result := take(tenPerTenSeconds, "a"); result.Allowed || result.RetryAfter != time.Second || result.Reset != 10*time.Second {
+		t.Fatalf("request over the limit = %+v, want rejected for a second", result)
+	}
+
+	// Rejected requests take nothing, so the bucket keeps refilling at the same rate
+	c.Advance(500 * time.Millisecond)
+	if result := take(tenPerTenSeconds, "a"); result.Allowed || result.RetryAfter != 500*time.Millisecond {
+		t.Fatalf("request after half a token = %+v, want rejected for half a second", result)
+	}
+	c.Advance(500 * time.Millisecond)
+	if result := take(tenPerTenSeconds, "a"); !result.Allowed || result.Remaining != 0 {
+		t.Fatalf("request after a token = %+v, want allowed", result)
+	}
+
+	// Keys and policies have buckets of their own
+	if result := take(tenPerTenSeconds, "b"); !result.Allowed || result.Remaining != 9 {
+		t.Errorf("other key = %+v, want a full bucket", result)
+	}
+	other := tenPerTenSeconds
+	other.Name = "other"
+	if result := take(other, "a"); !result.Allowed || result.Remaining != 9 {
+		t.Errorf("other policy = %+v, want a full bucket", result)
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	ctx := context.Background()
+	c := &clock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store := ratelimit.NewMemoryStoreAt(c.Now)
+
+	fast := ratelimit.Policy{Name: "fast", Limit: 2, Period: 2 * time.Second, Key: ratelimit.KeyIP}
+	slow := ratelimit.Policy{Name: "slow", Limit: 1, Period: time.Hour, Key: ratelimit.KeyIP}
+
+	store.Take(ctx, fast, "a")
+	c.Advance(59 * time.Second)
+	store.Take(ctx, slow, "c")
+	if n := store.Len(); n != 2 {
+		t.Fatalf("buckets before the sweep = %d, want 2", n)
+	}
+
+	// A minute after the last sweep, the full bucket of a is forgotten, while the bucket
+	// of c is still refilling
+	c.Advance(2 * time.Second)
+	store.Take(ctx, fast, "b")
+	if n := store.Len(); n != 2 {
+		t.Errorf("buckets after the sweep = %d, want 2 (b and c)", n)
+	}
+	if result, _ := store.Take(ctx, slow, "c"); result.Allowed {
+		t.Errorf("c = %+v, want its bucket kept empty", result)
+	}
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// articleRepository is the GORM implementation of ArticleRepository.
+type articleRepository struct {
+	db *gorm.DB
+}
+
+// NewArticleRepository returns an ArticleRepository backed by db.
+func NewArticleRepository(db *gorm.DB) ArticleRepository {
+	return &articleRepository{db: db}
+}
+
+func (r *articleRepository) ListPublished(ctx context.Context) ([]models.Article, error) {
+	var articles []models.Article
+	err := r.db.WithContext(ctx).Preload("User").Preload("Tags").
+		Where("status = ?", models.ArticleStatusPublished).Order("created_at desc").Find(&articles).Error
+	return articles, translate(err)
+}
+
+func (r *articleRepository) ListPublishedByAuthor(ctx context.Context, userID uint, offset, limit int) ([]models.Article, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Article{}).Where("user_id = ? AND status = ?", userID, models.ArticleStatusPublished)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, translate(err)
+	}
+
+	var articles []models.Article
+	err := query.Preload("User").Preload("Tags").Order("created_at desc").Offset(offset).Limit(limit).Find(&articles).Error
+	return articles, total, translate(err)
+}
+
+func (r *articleRepository) FindByID(ctx context.Context, id uint) (*models.Article, error) {
+	var article models.Article
+	if err := r.db.WithContext(ctx).Preload("User").Preload("Tags").First(&article, id).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &article, nil
+}
+
+func (r *articleRepository) UniqueSlug(ctx context.Context, text string) (string, error) {
+	slug, err := models.UniqueArticleSlug(r.db.WithContext(ctx), text)
+	return slug, translate(err)
+}
+
+func (r *articleRepository) FindOrCreateTags(ctx context.Context, names []string) ([]models.Tag, error) {
+	tags := make([]models.Tag, 0, len(names))
+	for _, name := range names {
+		tag := models.Tag{Name: name}
+		if err := r.db.WithContext(ctx).Where(models.Tag{Name: name}).FirstOrCreate(&tag).Error; err != nil {
+			return nil, translate(err)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+func (r *articleRepository) Create(ctx context.Context, article *models.Article) error {
+	return translate(r.db.WithContext(ctx).Create(article).Error)
+}
+
+func (r *articleRepository) Update(ctx context.Context, article *models.Article, version uint) error {
+	// The version check and the write happen in one statement, so concurrent writers
+	// cannot overwrite each other
+	result := r.db.WithContext(ctx).Model(article).Where("version = ?", version).Updates(map[string]interface{}{
+		"title":            article.Title,
+		"content":          article.Content,
+		"excerpt":          article.Excerpt,
+		"cover_url":        article.CoverURL,
+		"meta_description": article.MetaDescription,
+		"canonical_url":    article.CanonicalURL,
+		"noindex":          article.NoIndex,
+		"status":           article.Status,
+		"version":          gorm.Expr("version + 1"),
+	})
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *articleRepository) ReplaceTags(ctx context.Context, article *models.Article, tags []models.Tag) error {
+	return translate(r.db.WithContext(ctx).Model(article).Association("Tags").Replace(tags))
+}
+
+func (r *articleRepository) Delete(ctx context.Context, article *models.Article, version uint) error {
+	result := r.db.WithContext(ctx).Where("version = ?", version).Delete(article)
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *articleRepository) Transaction(ctx context.Context, fn func(articles ArticleRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&articleRepository{db: tx})
+	})
+}
+
+func (r *articleRepository) IsCollaborator(ctx context.Context, articleID, userID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.ArticleCollaborator{}).
+		Where("article_id = ? AND user_id = ?", articleID, userID).Count(&count).Error
+	return count > 0, translate(err)
+}
+
+func (r *articleRepository) ListCollaborators(ctx context.Context, articleID uint) ([]models.User, error) {
+	var users []models.User
+	err := r.db.WithContext(ctx).
+		Joins("JOIN article_collaborators ON article_collaborators.user_id = users.id").
+		Where("article_collaborators.article_id = ?", articleID).Order("users.username").Find(&users).Error
+	return users, translate(err)
+}
+
+func (r *articleRepository) AddCollaborator(ctx context.Context, articleID, userID uint) error {
+	collaborator := models.ArticleCollaborator{ArticleID: articleID, UserID: userID}
+	return translate(r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&collaborator).Error)
+}
+
+func (r *articleRepository) RemoveCollaborator(ctx context.Context, articleID, userID uint) error {
+	result := r.db.WithContext(ctx).Where("article_id = ? AND user_id = ?", articleID, userID).Delete(&models.ArticleCollaborator{})
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"gorm.io/gorm"
+)
+
+// commentRepository is the GORM implementation of CommentRepository.
+type commentRepository struct {
+	db *gorm.DB
+}
+
+// NewCommentRepository returns a CommentRepository backed by db.
+func NewCommentRepository(db *gorm.DB) CommentRepository {
+	return &commentRepository{db: db}
+}
+
+func (r *commentRepository) ListByArticle(ctx context.Context, articleID uint) ([]models.Comment, error) {
+	var comments []models.Comment
+	err := r.db.WithContext(ctx).Where("article_id = ?", articleID).
+		Preload("User").Preload("RemoteActor").Order("created_at asc").Find(&comments).Error
+	return comments, translate(err)
+}
+
+func (r *commentRepository) FindByID(ctx context.Context, id uint) (*models.Comment, error) {
+	var comment models.Comment
+	if err := r.db.WithContext(ctx).Preload("User").Preload("RemoteActor").First(&comment, id).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &comment, nil
+}
+
+func (r *commentRepository) FindInArticle(ctx context.Context, articleID, id uint) (*models.Comment, error) {
+	var comment models.Comment
+	if err := r.db.WithContext(ctx).Where("article_id = ?", articleID).First(&comment, id).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &comment, nil
+}
+
+func (r *commentRepository) Create(ctx context.Context, comment *models.Comment) error {
+	return translate(r.db.WithContext(ctx).Create(comment).Error)
+}
@@ -0,0 +1,473 @@
+// Package memory provides in-memory implementations of the repository interfaces, for tests.
+//
+// Repositories created from the same Store share their data, the way tables of one
+// database do: articles are returned with their author, comments with theirs. Records are
+// copied in and out, so callers cannot change stored data without going through a
+// repository.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"github.com/jasen-devvv/mini-blog-backend/repository"
+)
+
+// Store holds the records of the in-memory repositories.
+type Store struct {
+	mu       sync.Mutex
+	lastID   uint
+	users    map[uint]models.User
+	articles map[uint]models.Article
+	tags     map[string]models.Tag
+	comments map[uint]models.Comment
+	follows  []models.Follow
+	shares   map[uint]map[uint]bool
+}
+
+// NewStore returns an empty store.
+func NewStore() *Store {
+	return &Store{
+		users:    map[uint]models.User{},
+		articles: map[uint]models.Article{},
+		tags:     map[string]models.Tag{},
+		comments: map[uint]models.Comment{},
+		shares:   map[uint]map[uint]bool{},
+	}
+}
+
+// Users returns a UserRepository backed by the store.
+func (s *Store) Users() repository.UserRepository {
+	return userRepository{s}
+}
+
+// Articles returns an ArticleRepository backed by the store.
+func (s *Store) Articles() repository.ArticleRepository {
+	return articleRepository{s}
+}
+
+// Comments returns a CommentRepository backed by the store.
+func (s *Store) Comments() repository.CommentRepository {
+	return commentRepository{s}
+}
+
+// Follow records that follower follows followee.
+func (s *Store) Follow(followerID, followeeID uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.follows = append(s.follows, models.Follow{FollowerID: followerID, FolloweeID: followeeID, CreatedAt: time.Now()})
+}
+
+// nextID returns a new record ID. IDs are unique across tables, which makes mix-ups
+// between them visible in tests.
+func (s *Store) nextID() uint {
+	s.lastID++
+	return s.lastID
+}
+
+// withTimestamps sets unset creation and update times to now.
+func withTimestamps(createdAt, updatedAt *time.Time) {
+	now := time.Now()
+	if createdAt.IsZero() {
+		*createdAt = now
+	}
+	if updatedAt.IsZero() {
+		*updatedAt = now
+	}
+}
+
+type userRepository struct{ s *Store }
+
+func (r userRepository) Create(ctx context.Context, user *models.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, existing := range r.s.users {
+		if existing.Username == user.Username || existing.Email == user.Email {
+			return repository.ErrConflict
+		}
+	}
+
+	user.ID = r.s.nextID()
+	if user.Role == "" {
+		user.Role = models.RoleUser
+	}
+	withTimestamps(&user.CreatedAt, &user.UpdatedAt)
+	r.s.users[user.ID] = *user
+	return nil
+}
+
+func (r userRepository) FindByID(ctx context.Context, id uint) (*models.User, error) {
+	return r.find(func(user models.User) bool { return user.ID == id })
+}
+
+func (r userRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.find(func(user models.User) bool { return user.Email == email })
+}
+
+func (r userRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	return r.find(func(user models.User) bool { return user.Username == username })
+}
+
+func (r userRepository) find(match func(models.User) bool) (*models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, user := range r.s.users {
+		if match(user) {
+			return &user, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r userRepository) UpdateProfile(ctx context.Context, user *models.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored, ok := r.s.users[user.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	stored.DisplayName = user.DisplayName
+	stored.Bio = user.Bio
+	stored.AvatarURL = user.AvatarURL
+	stored.Website = user.Website
+	stored.SocialLinks = user.SocialLinks
+	stored.UpdatedAt = time.Now()
+	r.s.users[user.ID] = stored
+	return nil
+}
+
+func (r userRepository) CountFollowers(ctx context.Context, userID uint) (int64, error) {
+	return r.countFollows(func(follow models.Follow) bool { return follow.FolloweeID == userID }), nil
+}
+
+func (r userRepository) CountFollowing(ctx context.Context, userID uint) (int64, error) {
+	return r.countFollows(func(follow models.Follow) bool { return follow.FollowerID == userID }), nil
+}
+
+func (r userRepository) countFollows(match func(models.Follow) bool) int64 {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var count int64
+	for _, follow := range r.s.follows {
+		if match(follow) {
+			count++
+		}
+	}
+	return count
+}
+
+type articleRepository struct{ s *Store }
+
+// load returns a copy of a stored article with its author. The caller holds the lock.
+func (r articleRepository) load(article models.Article) models.Article {
+	article.User = r.s.users[article.UserID]
+	article.Tags = append([]models.Tag{}, article.Tags...)
+	return article
+}
+
+// list returns the published articles matching filter, newest first. The caller holds the lock.
+func (r articleRepository) list(filter func(models.Article) bool) []models.Article {
+	articles := []models.Article{}
+	for _, article := range r.s.articles {
+		if article.Status == models.ArticleStatusPublished && filter(article) {
+			articles = append(articles, r.load(article))
+		}
+	}
+	sort.Slice(articles, func(i, j int) bool {
+		if !articles[i].CreatedAt.Equal(articles[j].CreatedAt) {
+			return articles[i].CreatedAt.After(articles[j].CreatedAt)
+		}
+		return articles[i].ID > articles[j].ID
+	})
+	return articles
+}
+
+func (r articleRepository) ListPublished(ctx context.Context) ([]models.Article, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.list(func(models.Article) bool { return true }), nil
+}
+
+func (r articleRepository) ListPublishedByAuthor(ctx context.Context, userID uint, offset, limit int) ([]models.Article, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	articles := r.list(func(article models.Article) bool { return article.UserID == userID })
+	total := int64(len(articles))
+	if offset > len(articles) {
+		offset = len(articles)
+	}
+	articles = articles[offset:]
+	if limit < len(articles) {
+		articles = articles[:limit]
+	}
+	return articles, total, nil
+}
+
+func (r articleRepository) FindByID(ctx context.Context, id uint) (*models.Article, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	article, ok := r.s.articles[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	article = r.load(article)
+	return &article, nil
+}
+
+func (r articleRepository) UniqueSlug(ctx context.Context, text string) (string, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	base := models.Slugify(text)
+	if base == "" {
+		base = "article"
+	}
+	used := map[string]bool{}
+	for _, article := range r.s.articles {
+		if article.Slug == base || strings.HasPrefix(article.Slug, base+"-") {
+			used[article.Slug] = true
+		}
+	}
+
+	slug := base
+	for i := 2; used[slug]; i++ {
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+	return slug, nil
+}
+
+func (r articleRepository) FindOrCreateTags(ctx context.Context, names []string) ([]models.Tag, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	tags := make([]models.Tag, 0, len(names))
+	for _, name := range names {
+		tag, ok := r.s.tags[name]
+		if !ok {
+			tag = models.Tag{ID: r.s.nextID(), Name: name}
+			r.s.tags[name] = tag
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+func (r articleRepository) Create(ctx context.Context, article *models.Article) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if article.Slug != "" {
+		for _, existing := range r.s.articles {
+			if existing.Slug == article.Slug {
+				return repository.ErrConflict
+			}
+		}
+	}
+
+	article.ID = r.s.nextID()
+	if article.Status == "" {
+		article.Status = models.ArticleStatusPublished
+	}
+	if article.Version == 0 {
+		article.Version = 1
+	}
+	withTimestamps(&article.CreatedAt, &article.UpdatedAt)
+
+	stored := *article
+	stored.User = models.User{}
+	stored.Tags = append([]models.Tag{}, article.Tags...)
+	r.s.articles[article.ID] = stored
+	return nil
+}
+
+func (r articleRepository) Update(ctx context.Context, article *models.Article, version uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored, ok := r.s.articles[article.ID]
+	if !ok || stored.Version != version {
+		return repository.ErrConflict
+	}
+
+	stored.Title = article.Title
+	stored.Content = article.Content
+	stored.Excerpt = article.Excerpt
+	stored.CoverURL = article.CoverURL
+	stored.MetaDescription = article.MetaDescription
+	stored.CanonicalURL = article.CanonicalURL
+	stored.NoIndex = article.NoIndex
+	stored.Status = article.Status
+	stored.Version++
+	stored.UpdatedAt = time.Now()
+	r.s.articles[article.ID] = stored
+	return nil
+}
+
+func (r articleRepository) ReplaceTags(ctx context.Context, article *models.Article, tags []models.Tag) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored, ok := r.s.articles[article.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	stored.Tags = append([]models.Tag{}, tags...)
+	r.s.articles[article.ID] = stored
+	return nil
+}
+
+func (r articleRepository) Delete(ctx context.Context, article *models.Article, version uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored, ok := r.s.articles[article.ID]
+	if !ok || stored.Version != version {
+		return repository.ErrConflict
+	}
+	delete(r.s.articles, article.ID)
+	delete(r.s.shares, article.ID)
+	return nil
+}
+
+// Transaction restores the articles when fn fails. Unlike a database transaction, it does
+// not hide the writes of fn from concurrent readers.
+func (r articleRepository) Transaction(ctx context.Context, fn func(articles repository.ArticleRepository) error) error {
+	r.s.mu.Lock()
+	articles := make(map[uint]models.Article, len(r.s.articles))
+	for id, article := range r.s.articles {
+		articles[id] = article
+	}
+	r.s.mu.Unlock()
+
+	if err := fn(r); err != nil {
+		r.s.mu.Lock()
+		r.s.articles = articles
+		r.s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (r articleRepository) IsCollaborator(ctx context.Context, articleID, userID uint) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.s.shares[articleID][userID], nil
+}
+
+func (r articleRepository) ListCollaborators(ctx context.Context, articleID uint) ([]models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	users := []models.User{}
+	for userID := range r.s.shares[articleID] {
+		users = append(users, r.s.users[userID])
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+	return users, nil
+}
+
+func (r articleRepository) AddCollaborator(ctx context.Context, articleID, userID uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.articles[articleID]; !ok {
+		return fmt.Errorf("article %d does not exist", articleID)
+	}
+	if r.s.shares[articleID] == nil {
+		r.s.shares[articleID] = map[uint]bool{}
+	}
+	r.s.shares[articleID][userID] = true
+	return nil
+}
+
+func (r articleRepository) RemoveCollaborator(ctx context.Context, articleID, userID uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if !r.s.shares[articleID][userID] {
+		return repository.ErrNotFound
+	}
+	delete(r.s.shares[articleID], userID)
+	return nil
+}
+
+type commentRepository struct{ s *Store }
+
+// load returns a copy of a stored comment with its author. The caller holds the lock.
+func (r commentRepository) load(comment models.Comment) models.Comment {
+	if comment.UserID != nil {
+		if user, ok := r.s.users[*comment.UserID]; ok {
+			comment.User = &user
+		}
+	}
+	return comment
+}
+
+func (r commentRepository) ListByArticle(ctx context.Context, articleID uint) ([]models.Comment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	comments := []models.Comment{}
+	for _, comment := range r.s.comments {
+		if comment.ArticleID == articleID {
+			comments = append(comments, r.load(comment))
+		}
+	}
+	sort.Slice(comments, func(i, j int) bool {
+		if !comments[i].CreatedAt.Equal(comments[j].CreatedAt) {
+			return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+		}
+		return comments[i].ID < comments[j].ID
+	})
+	return comments, nil
+}
+
+func (r commentRepository) FindByID(ctx context.Context, id uint) (*models.Comment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	comment, ok := r.s.comments[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	comment = r.load(comment)
+	return &comment, nil
+}
+
+func (r commentRepository) FindInArticle(ctx context.Context, articleID, id uint) (*models.Comment, error) {
+	comment, err := r.FindByID(ctx, id)
+	if err != nil || comment.ArticleID != articleID {
+		return nil, repository.ErrNotFound
+	}
+	return comment, nil
+}
+
+func (r commentRepository) Create(ctx context.Context, comment *models.Comment) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.articles[comment.ArticleID]; !ok {
+		return fmt.Errorf("article %d does not exist", comment.ArticleID)
+	}
+
+	comment.ID = r.s.nextID()
+	withTimestamps(&comment.CreatedAt, &comment.UpdatedAt)
+
+	stored := *comment
+	stored.User = nil
+	r.s.comments[comment.ID] = stored
+	return nil
+}
@@ -0,0 +1,99 @@
+// Package repository defines how the application reads and writes its core models.
+//
+// Handlers and services depend on the interfaces below instead of the global database,
+// so they can be tested against the in-memory implementations in repository/memory.
+// The GORM implementations returned by the New* functions are used in production.
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNotFound is returned when the requested record does not exist.
+	ErrNotFound = errors.New("record not found")
+
+	// ErrConflict is returned when a write violates a unique constraint, or when a
+	// versioned write finds that the record changed in the meantime.
+	ErrConflict = errors.New("record conflicts with the stored data")
+)
+
+// UserRepository stores users and their follow counts.
+type UserRepository interface {
+	// Create stores a new user. It returns ErrConflict when the username or email is taken.
+	Create(ctx context.Context, user *models.User) error
+	FindByID(ctx context.Context, id uint) (*models.User, error)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	FindByUsername(ctx context.Context, username string) (*models.User, error)
+	// UpdateProfile saves the profile fields of a user: display name, bio, avatar, website and social links.
+	UpdateProfile(ctx context.Context, user *models.User) error
+	CountFollowers(ctx context.Context, userID uint) (int64, error)
+	CountFollowing(ctx context.Context, userID uint) (int64, error)
+}
+
+// ArticleRepository stores articles and their tags. Articles are returned with their
+// author and tags loaded.
+type ArticleRepository interface {
+	// ListPublished returns every published article, newest first.
+	ListPublished(ctx context.Context) ([]models.Article, error)
+	// ListPublishedByAuthor returns a page of an author's published articles, newest first,
+	// and how many there are in total.
+	ListPublishedByAuthor(ctx context.Context, userID uint, offset, limit int) ([]models.Article, int64, error)
+	FindByID(ctx context.Context, id uint) (*models.Article, error)
+	// UniqueSlug returns a slug for text that no article uses yet.
+	UniqueSlug(ctx context.Context, text string) (string, error)
+	// FindOrCreateTags returns the tags with the given (normalized) names, creating missing ones.
+	FindOrCreateTags(ctx context.Context, names []string) ([]models.Tag, error)
+	// Create stores a new article with its tags.
+	Create(ctx context.Context, article *models.Article) error
+	// Update saves the editable fields of an article if it is still at the given version,
+	// and increments the version. It returns ErrConflict when the version changed.
+	Update(ctx context.Context, article *models.Article, version uint) error
+	// ReplaceTags replaces the tags of an article.
+	ReplaceTags(ctx context.Context, article *models.Article, tags []models.Tag) error
+	// Delete removes an article if it is still at the given version, and returns
+	// ErrConflict otherwise.
+	Delete(ctx context.Context, article *models.Article, version uint) error
+	// Transaction runs fn with a repository whose writes are committed together when fn
+	// returns nil, and rolled back when it returns an error.
+	Transaction(ctx context.Context, fn func(articles ArticleRepository) error) error
+	// IsCollaborator reports whether the author shared an article with a user.
+	IsCollaborator(ctx context.Context, articleID, userID uint) (bool, error)
+	// ListCollaborators returns the users an article is shared with, by username.
+	ListCollaborators(ctx context.Context, articleID uint) ([]models.User, error)
+	// AddCollaborator shares an article with a user. Sharing it twice is not an error.
+	AddCollaborator(ctx context.Context, articleID, userID uint) error
+	// RemoveCollaborator stops sharing an article with a user. It returns ErrNotFound when
+	// the article was not shared with them.
+	RemoveCollaborator(ctx context.Context, articleID, userID uint) error
+}
+
+// CommentRepository stores comments. Comments are returned with their author loaded
+// (the remote actor for replies received over ActivityPub).
+type CommentRepository interface {
+	// ListByArticle returns the comments of an article, oldest first.
+	ListByArticle(ctx context.Context, articleID uint) ([]models.Comment, error)
+	FindByID(ctx context.Context, id uint) (*models.Comment, error)
+	// FindInArticle returns a comment only if it belongs to the given article.
+	FindInArticle(ctx context.Context, articleID, id uint) (*models.Comment, error)
+	Create(ctx context.Context, comment *models.Comment) error
+}
+
+// translate maps GORM and Postgres errors to the errors of this package.
+func translate(err error) error {
+	var pgErr *pgconn.PgError
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.As(err, &pgErr) && pgErr.Code == "23505": // unique_violation
+		return ErrConflict
+	}
+	return err
+}
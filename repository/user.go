@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"gorm.io/gorm"
+)
+
+// userRepository is the GORM implementation of UserRepository.
+type userRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRepository returns a UserRepository backed by db.
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{db: db}
+}
+
+func (r *userRepository) Create(ctx context.Context, user *models.User) error {
+	return translate(r.db.WithContext(ctx).Create(user).Error)
+}
+
+func (r *userRepository) FindByID(ctx context.Context, id uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).First(&user, id).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &user, nil
+}
+
+func (r *userRepository) UpdateProfile(ctx context.Context, user *models.User) error {
+	// Update with a map so that empty values clear the stored fields
+	err := r.db.WithContext(ctx).Model(user).Updates(map[string]interface{}{
+		"display_name": user.DisplayName,
+		"bio":          user.Bio,
+		"avatar_url":   user.AvatarURL,
+		"website":      user.Website,
+		"social_links": user.SocialLinks,
+	}).Error
+	return translate(err)
+}
+
+func (r *userRepository) CountFollowers(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Follow{}).Where("followee_id = ?", userID).Count(&count).Error
+	return count, translate(err)
+}
+
+func (r *userRepository) CountFollowing(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Follow{}).Where("follower_id = ?", userID).Count(&count).Error
+	return count, translate(err)
+}
@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/controllers"
+)
+
+// SetupActivityPubRoutes sets up the federation routes that let fediverse servers
+// (such as Mastodon) follow authors and reply to articles.
+//
+// Available routes:
+//   - GET  /.well-known/webfinger        -> Resolve acct:username@host to an actor
+//   - GET  /ap/users/:username           -> ActivityPub actor of an author
+//   - POST /ap/users/:username/inbox     -> Receive signed activities (follows, replies, deletes)
+//   - GET  /ap/users/:username/outbox    -> The author's newest published articles
+//   - GET  /ap/users/:username/followers -> Number of local and remote followers
+//   - GET  /ap/articles/:id              -> A published article as an ActivityPub object
+func SetupActivityPubRoutes(router *gin.Engine, handler *controllers.Handler) {
+	router.GET("/.well-known/webfinger", handler.WebFinger)
+
+	ap := router.Group("/ap")
+	{
+		ap.GET("/users/:username", handler.GetActor)
+		ap.POST("/users/:username/inbox", handler.PostInbox)
+		ap.GET("/users/:username/outbox", handler.GetOutbox)
+		ap.GET("/users/:username/followers", handler.GetFollowers)
+		ap.GET("/articles/:id", handler.GetArticleObject)
+	}
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/controllers"
+	"github.com/jasen-devvv/mini-blog-backend/middleware"
+)
+
+// SetupAdminRoutes sets up administration routes for the application.
+//
+// Available routes:
+//   - POST /api/admin/import     -> Start importing a WordPress WXR export or a ZIP of Markdown files (requires admin)
+//   - GET  /api/admin/import/:id -> Get the status and report of an import (requires admin)
+//
+// All routes require authentication and the administrator role.
+func SetupAdminRoutes(router *gin.Engine, handler *controllers.Handler) {
+	admin := router.Group("/api/admin")
+	admin.Use(middleware.AuthMiddleware(handler.Config), middleware.AdminMiddleware())
+	{
+		admin.POST("/import", handler.ImportContent)
+		admin.GET("/import/:id", handler.GetImport)
+	}
+}
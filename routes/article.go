@@ -9,24 +9,38 @@ import (
 // SetupArticleRoutes sets up the article-related routes for the application.
 //
 // Available routes:
-//   - GET    /api/articles       -> Fetch all articles
-//   - GET    /api/articles/:id   -> Fetch a specific article by ID
-//   - POST   /api/articles       -> Create a new article (requires authentication)
-//   - PUT    /api/articles/:id   -> Update an existing article by ID (requires authentication)
-//   - DELETE /api/articles/:id   -> Delete an article by ID (requires authentication)
+//   - GET    /api/articles                    -> Fetch all published articles
+//   - GET    /api/articles/:id                -> Fetch a specific article by ID (drafts are visible to their author and collaborators only)
+//   - GET    /api/articles/:id/live           -> WebSocket reporting viewers and edit locks (requires authentication)
+//   - POST   /api/articles                    -> Create a new article (requires authentication)
+//   - PUT    /api/articles/:id                -> Update an existing article by ID (requires authentication)
+//   - PATCH  /api/articles/:id                -> Partially update an article with a JSON Merge Patch (requires authentication)
+//   - DELETE /api/articles/:id                -> Delete an article by ID (requires authentication, author only)
+//   - GET    /api/articles/:id/collaborators  -> List the users an article is shared with (requires authentication)
+//   - PUT    /api/articles/:id/collaborators/:username -> Share an article with a user (requires authentication, author only)
+//   - DELETE /api/articles/:id/collaborators/:username -> Stop sharing an article with a user (requires authentication, author only)
+//   - PUT    /api/articles/:id/reactions/:kind -> React to an article (requires authentication)
+//   - DELETE /api/articles/:id/reactions/:kind -> Remove a reaction from an article (requires authentication)
 //
-// Routes that modify data (POST, PUT, DELETE) are protected by authentication middleware.
-func SetupArticleRoutes(router *gin.Engine) {
+// Routes that modify data (POST, PUT, PATCH, DELETE) are protected by authentication middleware.
+func SetupArticleRoutes(router *gin.Engine, handler *controllers.Handler) {
 	articles := router.Group("/api/articles")
 	{
-		articles.GET("", controllers.GetAllArticles)
-		articles.GET("/:id", controllers.GetArticle)
+		articles.GET("", middleware.OptionalAuthMiddleware(handler.Config), handler.GetAllArticles)
+		articles.GET("/:id", middleware.OptionalAuthMiddleware(handler.Config), handler.GetArticle)
+		articles.GET("/:id/live", middleware.StreamAuthMiddleware(handler.Config), handler.ArticleLive)
 
-		articles.Use(middleware.AuthMiddleware())
+		articles.Use(middleware.AuthMiddleware(handler.Config))
 		{
-			articles.POST("", controllers.CreateArticle)
-			articles.PUT("/:id", controllers.UpdateArticle)
-			articles.DELETE("/:id", controllers.DeleteArticle)
+			articles.POST("", handler.CreateArticle)
+			articles.PUT("/:id", handler.UpdateArticle)
+			articles.PATCH("/:id", handler.PatchArticle)
+			articles.DELETE("/:id", handler.DeleteArticle)
+			articles.GET("/:id/collaborators", handler.GetCollaborators)
+			articles.PUT("/:id/collaborators/:username", handler.AddCollaborator)
+			articles.DELETE("/:id/collaborators/:username", handler.RemoveCollaborator)
+			articles.PUT("/:id/reactions/:kind", handler.AddArticleReaction)
+			articles.DELETE("/:id/reactions/:kind", handler.RemoveArticleReaction)
 		}
 	}
 }
@@ -3,17 +3,18 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/jasen-devvv/mini-blog-backend/controllers"
+	"github.com/jasen-devvv/mini-blog-backend/middleware"
 )
 
 // SetupAuthRoutes sets up authentication-related routes for the application.
 //
 // Available routes:
-//   - POST /api/auth/register -> Register a new user
-//   - POST /api/auth/login    -> Authenticate and log in a user
-func SetupAuthRoutes(router *gin.Engine) {
+//   - POST /api/auth/register -> Register a new user (rate limited by the "register" policy)
+//   - POST /api/auth/login    -> Authenticate and log in a user (rate limited by the "login" policy)
+func SetupAuthRoutes(router *gin.Engine, handler *controllers.Handler) {
 	auth := router.Group("/api/auth")
 	{
-		auth.POST("/register", controllers.Register)
-		auth.POST("/login", controllers.Login)
+		auth.POST("/register", middleware.RateLimitMiddleware(handler.Config, "register"), handler.Register)
+		auth.POST("/login", middleware.RateLimitMiddleware(handler.Config, "login"), handler.Login)
 	}
 }
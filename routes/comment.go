@@ -6,21 +6,35 @@ import (
 	"github.com/jasen-devvv/mini-blog-backend/middleware"
 )
 
-// SetupCommentRoutes sets up comment-related routes for the application.
+// SetupCommentRoutes sets up comment and webmention routes for the application.
 //
 // Available routes:
-//   - GET  /api/articles/:id/comments  -> Fetch all comments for an article
-//   - POST /api/articles/:id/comments  -> Add a new comment to an article (requires authentication)
+//   - GET    /api/articles/:id/comments         -> Fetch all comments for an article
+//   - GET    /api/articles/:id/comments/stream  -> Stream new comments as Server-Sent Events
+//   - POST   /api/articles/:id/comments         -> Add a new comment to an article (requires authentication; rate limited by the "comment" policy)
+//   - PUT    /api/comments/:id/reactions/:kind  -> React to a comment (requires authentication)
+//   - DELETE /api/comments/:id/reactions/:kind  -> Remove a reaction from a comment (requires authentication)
+//   - POST   /webmention                        -> Receive a Webmention (verified in the background)
+//   - GET    /api/articles/:id/webmentions      -> Fetch the verified webmentions of an article (all of them for its author)
+//   - PUT    /api/webmentions/:id               -> Hide or show a webmention (requires authentication)
+//   - DELETE /api/webmentions/:id               -> Delete a webmention (requires authentication)
 //
-// The POST route is protected by authentication middleware.
-func SetupCommentRoutes(router *gin.Engine) {
+// Routes that modify data are protected by authentication middleware.
+func SetupCommentRoutes(router *gin.Engine, handler *controllers.Handler) {
 	// Public routes
-	router.GET("/api/articles/:id/comments", controllers.GetComments)
+	router.GET("/api/articles/:id/comments", middleware.OptionalAuthMiddleware(handler.Config), handler.GetComments)
+	router.GET("/api/articles/:id/comments/stream", handler.StreamComments)
+	router.POST("/webmention", handler.ReceiveWebmention)
+	router.GET("/api/articles/:id/webmentions", middleware.OptionalAuthMiddleware(handler.Config), handler.GetWebmentions)
 
 	// Protected routes
 	protected := router.Group("/api")
-	protected.Use(middleware.AuthMiddleware())
+	protected.Use(middleware.AuthMiddleware(handler.Config))
 	{
-		protected.POST("/articles/:id/comments", controllers.CreateComment)
+		protected.POST("/articles/:id/comments", middleware.RateLimitMiddleware(handler.Config, "comment"), handler.CreateComment)
+		protected.PUT("/comments/:id/reactions/:kind", handler.AddCommentReaction)
+		protected.DELETE("/comments/:id/reactions/:kind", handler.RemoveCommentReaction)
+		protected.PUT("/webmentions/:id", handler.ModerateWebmention)
+		protected.DELETE("/webmentions/:id", handler.DeleteWebmention)
 	}
 }
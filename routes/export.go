@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/controllers"
+	"github.com/jasen-devvv/mini-blog-backend/middleware"
+)
+
+// SetupExportRoutes sets up the data export routes for the application.
+//
+// Available routes:
+//   - POST /api/exports              -> Start an export of the caller's data, or of the whole blog (requires auth)
+//   - GET  /api/exports/:id          -> Get the status and download link of an export (requires auth)
+//   - GET  /api/exports/:id/download -> Download a finished export with the token from its link
+func SetupExportRoutes(router *gin.Engine, handler *controllers.Handler) {
+	// Public routes (the token in the link grants access)
+	router.GET("/api/exports/:id/download", handler.DownloadExport)
+
+	// Protected routes
+	protected := router.Group("/api/exports")
+	protected.Use(middleware.AuthMiddleware(handler.Config))
+	{
+		protected.POST("", handler.CreateExport)
+		protected.GET("/:id", handler.GetExport)
+	}
+}
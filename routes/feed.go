@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/controllers"
+	"github.com/jasen-devvv/mini-blog-backend/middleware"
+)
+
+// SetupFeedRoutes sets up the personalized feed route for the application.
+//
+// Available routes:
+//   - GET /api/feed -> Fetch recent articles from followed authors (requires authentication)
+func SetupFeedRoutes(router *gin.Engine, handler *controllers.Handler) {
+	router.GET("/api/feed", middleware.AuthMiddleware(handler.Config), handler.GetFeed)
+}
@@ -0,0 +1,159 @@
+package routes_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/config"
+	"github.com/jasen-devvv/mini-blog-backend/controllers"
+	"github.com/jasen-devvv/mini-blog-backend/internal/testutil"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"github.com/jasen-devvv/mini-blog-backend/routes"
+	"gorm.io/gorm"
+)
+
+const testSecret = "test-secret"
+
+// The integration tests run the full router against Postgres, see testutil.RunWithDatabase.
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	gin.DefaultWriter = io.Discard
+	os.Exit(testutil.RunWithDatabase(m))
+}
+
+// harness is the application running on a fresh, migrated database schema.
+type harness struct {
+	t       *testing.T
+	db      *gorm.DB
+	router  *gin.Engine
+	handler *controllers.Handler
+}
+
+// newHarness builds the router on a fresh, migrated schema, which is dropped when the
+// test ends. Tests using it must not run in parallel, as notifications and edit locks
+// use the global database connection.
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+	db := testutil.Database(t)
+
+	// Point the global connection at the test schema
+	previous := config.DB
+	config.DB = db
+	t.Cleanup(func() { config.DB = previous })
+
+	cfg := &config.Config{
+		JWTSecret: testSecret,
+		SiteURL:   "http://blog.test",
+		SiteName:  "Test Blog",
+		APIURL:    "http://api.test",
+	}
+
+	handler := controllers.NewHandler(db, cfg)
+	return &harness{t: t, db: db, router: routes.SetupRouter(handler), handler: handler}
+}
+
+// fixtures are the records seeded into every integration test.
+//
+// Fields:
+//   - Alice: Author of the articles.
+//   - Bob: Reader who commented on Alice's article.
+//   - Admin: Administrator.
+//   - Published: Alice's published article.
+//   - Draft: Alice's draft.
+//   - Comment: Bob's comment on the published article.
+type fixtures struct {
+	Alice     models.User
+	Bob       models.User
+	Admin     models.User
+	Published models.Article
+	Draft     models.Article
+	Comment   models.Comment
+}
+
+// fixturePassword is the password of every seeded user.
+const fixturePassword = "password"
+
+// seed inserts the fixtures.
+func (h *harness) seed() fixtures {
+	h.t.Helper()
+	var f fixtures
+
+	f.Alice = h.createUser("alice", models.RoleUser)
+	f.Bob = h.createUser("bob", models.RoleUser)
+	f.Admin = h.createUser("admin", models.RoleAdmin)
+
+	f.Published = models.Article{Title: "Published", Slug: "published", Content: "Hello world", Status: models.ArticleStatusPublished, UserID: f.Alice.ID}
+	f.Draft = models.Article{Title: "Draft", Slug: "draft", Content: "Work in progress", Status: models.ArticleStatusDraft, UserID: f.Alice.ID}
+	for _, article := range []*models.Article{&f.Published, &f.Draft} {
+		if err := h.db.Create(article).Error; err != nil {
+			h.t.Fatalf("seeding article %q: %v", article.Title, err)
+		}
+	}
+
+	f.Comment = models.Comment{Content: "Great post", ArticleID: f.Published.ID, UserID: &f.Bob.ID}
+	if err := h.db.Create(&f.Comment).Error; err != nil {
+		h.t.Fatalf("seeding comment: %v", err)
+	}
+	return f
+}
+
+// createUser registers a user with the fixture password and the given role.
+func (h *harness) createUser(username, role string) models.User {
+	h.t.Helper()
+
+	user, err := h.handler.Auth.Register(context.Background(), username, username+"@example.com", fixturePassword)
+	if err != nil {
+		h.t.Fatalf("seeding user %s: %v", username, err)
+	}
+	if role != models.RoleUser {
+		if err := h.db.Model(user).Update("role", role).Error; err != nil {
+			h.t.Fatalf("setting the role of %s: %v", username, err)
+		}
+		user.Role = role
+	}
+	return *user
+}
+
+// tokenFor returns a token authenticating as user.
+func (h *harness) tokenFor(user models.User) string {
+	h.t.Helper()
+
+	token, err := h.handler.Auth.IssueToken(&user)
+	if err != nil {
+		h.t.Fatalf("issuing a token for %s: %v", user.Username, err)
+	}
+	return token
+}
+
+// login authenticates through the login endpoint and returns the token.
+func (h *harness) login(email, password string) string {
+	h.t.Helper()
+
+	w := h.do(http.MethodPost, "/api/auth/login", "", gin.H{"email": email, "password": password})
+	testutil.ExpectStatus(h.t, w, http.StatusOK)
+	var body struct{ Token string }
+	testutil.Decode(h.t, w, &body)
+	return body.Token
+}
+
+// do sends a request to the router, see testutil.Do.
+func (h *harness) do(method, path, token string, body interface{}, headers ...string) *httptest.ResponseRecorder {
+	h.t.Helper()
+	return testutil.Do(h.t, h.router, method, path, token, body, headers...)
+}
+
+// count returns the number of rows of model matching the condition.
+func (h *harness) count(model interface{}, query string, args ...interface{}) int64 {
+	h.t.Helper()
+
+	var n int64
+	if err := h.db.Model(model).Where(query, args...).Count(&n).Error; err != nil {
+		h.t.Fatalf("counting rows: %v", err)
+	}
+	return n
+}
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/controllers"
+)
+
+// SetupHealthRoutes sets up the probes used by the orchestrator.
+//
+// Available routes:
+//   - GET /healthz -> Liveness: the process is up and serving requests
+//   - GET /readyz -> Readiness: the database is reachable and every migration is applied (503 otherwise)
+func SetupHealthRoutes(router *gin.Engine, handler *controllers.Handler) {
+	router.GET("/healthz", handler.Healthz)
+	router.GET("/readyz", handler.Readyz)
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/controllers"
+)
+
+// SetupMetricsRoutes sets up the monitoring routes for the application.
+//
+// Available routes:
+//   - GET /metrics -> Application metrics in the Prometheus text format (requires the metrics token when one is configured)
+func SetupMetricsRoutes(router *gin.Engine, handler *controllers.Handler) {
+	router.GET("/metrics", handler.GetMetrics)
+}
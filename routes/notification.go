@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/controllers"
+	"github.com/jasen-devvv/mini-blog-backend/middleware"
+)
+
+// SetupNotificationRoutes sets up notification inbox routes for the application.
+//
+// Available routes:
+//   - GET  /api/notifications             -> Fetch the user's notifications with the unread count
+//   - GET  /api/notifications/stream      -> Stream new notifications as Server-Sent Events
+//   - POST /api/notifications/:id/read    -> Mark a notification as read
+//   - POST /api/notifications/read-all    -> Mark all notifications as read
+//   - GET  /api/notifications/preferences -> Fetch per-type notification preferences
+//   - PUT  /api/notifications/preferences -> Update per-type notification preferences
+//
+// All routes are protected by authentication middleware.
+func SetupNotificationRoutes(router *gin.Engine, handler *controllers.Handler) {
+	// The stream also accepts the token as a query parameter for EventSource clients
+	router.GET("/api/notifications/stream", middleware.StreamAuthMiddleware(handler.Config), handler.StreamNotifications)
+
+	notifications := router.Group("/api/notifications")
+	notifications.Use(middleware.AuthMiddleware(handler.Config))
+	{
+		notifications.GET("", handler.GetNotifications)
+		notifications.POST("/:id/read", handler.MarkNotificationRead)
+		notifications.POST("/read-all", handler.MarkAllNotificationsRead)
+		notifications.GET("/preferences", handler.GetNotificationPreferences)
+		notifications.PUT("/preferences", handler.UpdateNotificationPreferences)
+	}
+}
@@ -0,0 +1,45 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/controllers"
+	"github.com/jasen-devvv/mini-blog-backend/middleware"
+)
+
+// SetupReadingListRoutes sets up bookmark and reading list routes for the application.
+//
+// Available routes:
+//   - GET    /api/bookmarks                   -> Fetch the user's bookmarks (requires authentication)
+//   - POST   /api/articles/:id/bookmark       -> Bookmark an article (requires authentication)
+//   - DELETE /api/articles/:id/bookmark       -> Remove a bookmark (requires authentication)
+//   - GET    /api/lists                       -> Fetch the user's reading lists (requires authentication)
+//   - POST   /api/lists                       -> Create a reading list (requires authentication)
+//   - GET    /api/lists/:id                   -> Fetch a reading list (public lists are shareable by URL)
+//   - PUT    /api/lists/:id                   -> Update a reading list (requires authentication)
+//   - DELETE /api/lists/:id                   -> Delete a reading list (requires authentication)
+//   - POST   /api/lists/:id/items             -> Add an article to a list (requires authentication)
+//   - PUT    /api/lists/:id/items/:article_id -> Update the note of a list item (requires authentication)
+//   - DELETE /api/lists/:id/items/:article_id -> Remove an article from a list (requires authentication)
+//   - PUT    /api/lists/:id/order             -> Reorder the articles in a list (requires authentication)
+func SetupReadingListRoutes(router *gin.Engine, handler *controllers.Handler) {
+	// Public routes
+	router.GET("/api/lists/:id", middleware.OptionalAuthMiddleware(handler.Config), handler.GetReadingList)
+
+	// Protected routes
+	protected := router.Group("/api")
+	protected.Use(middleware.AuthMiddleware(handler.Config))
+	{
+		protected.GET("/bookmarks", handler.GetBookmarks)
+		protected.POST("/articles/:id/bookmark", handler.CreateBookmark)
+		protected.DELETE("/articles/:id/bookmark", handler.DeleteBookmark)
+
+		protected.GET("/lists", handler.GetReadingLists)
+		protected.POST("/lists", handler.CreateReadingList)
+		protected.PUT("/lists/:id", handler.UpdateReadingList)
+		protected.DELETE("/lists/:id", handler.DeleteReadingList)
+		protected.POST("/lists/:id/items", handler.AddReadingListItem)
+		protected.PUT("/lists/:id/items/:article_id", handler.UpdateReadingListItem)
+		protected.DELETE("/lists/:id/items/:article_id", handler.RemoveReadingListItem)
+		protected.PUT("/lists/:id/order", handler.ReorderReadingList)
+	}
+}
@@ -0,0 +1,72 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/apierror"
+	"github.com/jasen-devvv/mini-blog-backend/controllers"
+	"github.com/jasen-devvv/mini-blog-backend/middleware"
+)
+
+// SetupRouter builds the application's router with its middleware and every route.
+//
+// Every endpoint is served by handler, which also carries the application settings. The
+// client IP address, used in logs and rate limits, is only read from X-Forwarded-For when
+// the request comes from one of handler.Config.TrustedProxies.
+func SetupRouter(handler *controllers.Handler) *gin.Engine {
+	r := gin.New()
+
+	// Let handlers pass the gin context on as a context.Context carrying the request's
+	// logger, deadline and cancellation
+	r.ContextWithFallback = true
+
+	// Setup request IDs, tracing, structured access logs, metrics, problem details error
+	// responses and panic recovery
+	r.Use(
+		middleware.RequestIDMiddleware(),
+		middleware.TracingMiddleware(),
+		middleware.LoggerMiddleware(),
+		middleware.MetricsMiddleware(),
+		middleware.ErrorMiddleware(),
+		middleware.RecoveryMiddleware(),
+	)
+
+	// Answer unknown routes with a problem details response too
+	r.NoRoute(func(ctx *gin.Context) {
+		ctx.Error(apierror.New(http.StatusNotFound, "Route not found"))
+	})
+
+	// Setup Proxy; the entries were checked by Config.Validate
+	if err := r.SetTrustedProxies(handler.Config.TrustedProxies); err != nil {
+		panic(err)
+	}
+
+	// Setup CORS
+	r.Use(cors.New(cors.Config{
+		AllowOrigins:     []string{"*"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "If-Match", "If-None-Match", "Last-Event-ID", "X-Edit-Lock", "X-Request-ID", "Traceparent"},
+		ExposeHeaders:    []string{"Content-Length", "ETag", "Warning", "X-Request-ID", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After"},
+		AllowCredentials: true,
+	}))
+
+	// Setup routes
+	SetupAuthRoutes(r, handler)
+	SetupArticleRoutes(r, handler)
+	SetupCommentRoutes(r, handler) // Opsional
+	SetupUserRoutes(r, handler)
+	SetupFeedRoutes(r, handler)
+	SetupReadingListRoutes(r, handler)
+	SetupNotificationRoutes(r, handler)
+	SetupSyndicationRoutes(r, handler)
+	SetupSEORoutes(r, handler)
+	SetupActivityPubRoutes(r, handler)
+	SetupAdminRoutes(r, handler)
+	SetupExportRoutes(r, handler)
+	SetupMetricsRoutes(r, handler)
+	SetupHealthRoutes(r, handler)
+
+	return r
+}
@@ -0,0 +1,279 @@
+package routes_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/exporter"
+	"github.com/jasen-devvv/mini-blog-backend/internal/testutil"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"github.com/jasen-devvv/mini-blog-backend/presence"
+)
+
+func TestAuthRoutes(t *testing.T) {
+	h := newHarness(t)
+	f := h.seed()
+
+	// Registered users can log in and use their token
+	w := h.do(http.MethodPost, "/api/auth/register", "", gin.H{"username": "carol", "email": "carol@example.com", "password": "secret1"})
+	testutil.ExpectStatus(t, w, http.StatusCreated)
+	token := h.login("carol@example.com", "secret1")
+	testutil.ExpectStatus(t, h.do(http.MethodPut, "/api/users/me", token, gin.H{"bio": "Hello"}), http.StatusOK)
+
+	// Seeded users log in with the fixture password
+	if h.login(f.Alice.Email, fixturePassword) == "" {
+		t.Fatal("login returned no token")
+	}
+
+	testutil.ExpectStatus(t, h.do(http.MethodPost, "/api/auth/register", "", gin.H{"username": "alice", "email": "new@example.com", "password": "secret1"}), http.StatusConflict)
+	testutil.ExpectStatus(t, h.do(http.MethodPost, "/api/auth/register", "", gin.H{"username": "dave", "email": "dave@example.com", "password": "1"}), http.StatusBadRequest)
+	testutil.ExpectStatus(t, h.do(http.MethodPost, "/api/auth/login", "", gin.H{"email": f.Alice.Email, "password": "wrong"}), http.StatusUnauthorized)
+	testutil.ExpectStatus(t, h.do(http.MethodPost, "/api/auth/login", "", gin.H{"email": "nobody@example.com", "password": fixturePassword}), http.StatusUnauthorized)
+
+	// Protected routes reject missing and invalid tokens
+	testutil.ExpectStatus(t, h.do(http.MethodPost, "/api/articles", "", gin.H{"title": "T", "content": "C"}), http.StatusUnauthorized)
+	testutil.ExpectStatus(t, h.do(http.MethodPost, "/api/articles", "not-a-token", gin.H{"title": "T", "content": "C"}), http.StatusUnauthorized)
+}
+
+func TestArticleCRUD(t *testing.T) {
+	h := newHarness(t)
+	f := h.seed()
+	token := h.tokenFor(f.Alice)
+
+	// Create
+	w := h.do(http.MethodPost, "/api/articles", token, gin.H{"title": "Published", "content": "Body", "tags": []string{"Go", "web"}})
+	testutil.ExpectStatus(t, w, http.StatusCreated)
+	var created struct{ Data models.Article }
+	testutil.Decode(t, w, &created)
+	if created.Data.Slug != "published-2" || created.Data.Version != 1 || len(created.Data.Tags) != 2 {
+		t.Fatalf("created article = %+v", created.Data)
+	}
+	path := fmt.Sprintf("/api/articles/%d", created.Data.ID)
+
+	// Read
+	w = h.do(http.MethodGet, "/api/articles", "", nil)
+	testutil.ExpectStatus(t, w, http.StatusOK)
+	var list struct{ Data []models.Article }
+	testutil.Decode(t, w, &list)
+	if len(list.Data) != 2 || list.Data[0].ID != created.Data.ID || list.Data[1].ID != f.Published.ID {
+		t.Fatalf("articles = %+v", list.Data)
+	}
+
+	w = h.do(http.MethodGet, path, "", nil)
+	testutil.ExpectStatus(t, w, http.StatusOK)
+	testutil.ExpectStatus(t, h.do(http.MethodGet, path, "", nil, "If-None-Match", w.Header().Get("ETag")), http.StatusNotModified)
+
+	// Update
+	testutil.ExpectStatus(t, h.do(http.MethodPut, path, token, gin.H{"title": "Renamed", "content": "Body"}), http.StatusPreconditionRequired)
+	w = h.do(http.MethodPut, path, token, gin.H{"title": "Renamed", "content": "New body", "tags": []string{"web"}}, "If-Match", `"v1"`)
+	testutil.ExpectStatus(t, w, http.StatusOK)
+	var updated struct{ Data models.Article }
+	testutil.Decode(t, w, &updated)
+	if updated.Data.Title != "Renamed" || updated.Data.Version != 2 || len(updated.Data.Tags) != 1 {
+		t.Fatalf("updated article = %+v", updated.Data)
+	}
+	testutil.ExpectStatus(t, h.do(http.MethodPut, path, token, gin.H{"title": "Stale", "content": "Body"}, "If-Match", `"v1"`), http.StatusPreconditionFailed)
+
+	// Patch
+	w = h.do(http.MethodPatch, path, token, `{"excerpt": "Short", "status": "draft"}`, "Content-Type", "application/merge-patch+json", "If-Match", `"v2"`)
+	testutil.ExpectStatus(t, w, http.StatusOK)
+	var patched struct{ Data models.Article }
+	testutil.Decode(t, w, &patched)
+	if patched.Data.Excerpt != "Short" || patched.Data.Status != models.ArticleStatusDraft || patched.Data.Content != "New body" {
+		t.Fatalf("patched article = %+v", patched.Data)
+	}
+
+	// Delete
+	testutil.ExpectStatus(t, h.do(http.MethodDelete, path, token, nil, "If-Match", `"v3"`), http.StatusOK)
+	testutil.ExpectStatus(t, h.do(http.MethodGet, path, token, nil), http.StatusNotFound)
+	if n := h.count(&models.Article{}, "id = ?", created.Data.ID); n != 0 {
+		t.Errorf("%d deleted articles remain", n)
+	}
+}
+
+func TestArticleOwnership(t *testing.T) {
+	h := newHarness(t)
+	f := h.seed()
+	aliceToken, bobToken := h.tokenFor(f.Alice), h.tokenFor(f.Bob)
+	published := fmt.Sprintf("/api/articles/%d", f.Published.ID)
+	draft := fmt.Sprintf("/api/articles/%d", f.Draft.ID)
+
+	// Drafts are only visible to their author and collaborators
+	testutil.ExpectStatus(t, h.do(http.MethodGet, draft, "", nil), http.StatusNotFound)
+	testutil.ExpectStatus(t, h.do(http.MethodGet, draft, bobToken, nil), http.StatusNotFound)
+	testutil.ExpectStatus(t, h.do(http.MethodGet, draft, aliceToken, nil), http.StatusOK)
+
+	// Only the author may change or delete an article
+	update := gin.H{"title": "Hijacked", "content": "Body"}
+	testutil.ExpectStatus(t, h.do(http.MethodPut, published, bobToken, update, "If-Match", `"v1"`), http.StatusForbidden)
+	testutil.ExpectStatus(t, h.do(http.MethodPatch, published, bobToken, `{"title": "Hijacked"}`, "Content-Type", "application/merge-patch+json", "If-Match", `"v1"`), http.StatusForbidden)
+	testutil.ExpectStatus(t, h.do(http.MethodDelete, published, bobToken, nil, "If-Match", `"v1"`), http.StatusForbidden)
+
+	var article models.Article
+	if err := h.db.First(&article, f.Published.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if article.Title != f.Published.Title || article.Version != 1 {
+		t.Errorf("article changed by another user: %+v", article)
+	}
+
+	// Admin endpoints require the admin role
+	testutil.ExpectStatus(t, h.do(http.MethodPost, "/api/admin/import", bobToken, nil), http.StatusForbidden)
+	testutil.ExpectStatus(t, h.do(http.MethodPost, "/api/admin/import", h.tokenFor(f.Admin), nil), http.StatusBadRequest)
+}
+
+func TestComments(t *testing.T) {
+	h := newHarness(t)
+	f := h.seed()
+	aliceToken, bobToken := h.tokenFor(f.Alice), h.tokenFor(f.Bob)
+	path := fmt.Sprintf("/api/articles/%d/comments", f.Published.ID)
+
+	// Alice replies to Bob's comment
+	w := h.do(http.MethodPost, path, aliceToken, gin.H{"content": "Thanks!", "parent_id": f.Comment.ID})
+	testutil.ExpectStatus(t, w, http.StatusCreated)
+	var reply struct{ Data models.Comment }
+	testutil.Decode(t, w, &reply)
+	if reply.Data.ParentID == nil || *reply.Data.ParentID != f.Comment.ID || reply.Data.User == nil || reply.Data.User.Username != "alice" {
+		t.Fatalf("reply = %+v", reply.Data)
+	}
+
+	// Bob is notified of the reply
+	if n := h.count(&models.Notification{}, "user_id = ? AND type = ?", f.Bob.ID, models.NotificationTypeReply); n != 1 {
+		t.Errorf("bob has %d reply notifications, want 1", n)
+	}
+
+	w = h.do(http.MethodGet, path, "", nil)
+	testutil.ExpectStatus(t, w, http.StatusOK)
+	var list struct{ Data []models.Comment }
+	testutil.Decode(t, w, &list)
+	if len(list.Data) != 2 || list.Data[0].ID != f.Comment.ID || list.Data[1].ID != reply.Data.ID {
+		t.Fatalf("comments = %+v", list.Data)
+	}
+
+	// Comments need an author, content and an existing article and parent
+	testutil.ExpectStatus(t, h.do(http.MethodPost, path, "", gin.H{"content": "Anonymous"}), http.StatusUnauthorized)
+	testutil.ExpectStatus(t, h.do(http.MethodPost, path, bobToken, gin.H{}), http.StatusBadRequest)
+	testutil.ExpectStatus(t, h.do(http.MethodPost, "/api/articles/999999/comments", bobToken, gin.H{"content": "Hm"}), http.StatusNotFound)
+	testutil.ExpectStatus(t, h.do(http.MethodPost, path, bobToken, gin.H{"content": "Hm", "parent_id": 999999}), http.StatusBadRequest)
+}
+
+func TestFeedConditionalGet(t *testing.T) {
+	h := newHarness(t)
+	f := h.seed()
+
+	// Date the articles back, as HTTP dates only have a precision of one second
+	if err := h.db.Model(&models.Article{}).Where("1 = 1").Update("updated_at", time.Now().Add(-time.Hour)).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	w := h.do(http.MethodGet, "/feeds/rss.xml", "", nil)
+	testutil.ExpectStatus(t, w, http.StatusOK)
+	etag, lastModified := w.Header().Get("ETag"), w.Header().Get("Last-Modified")
+	if etag == "" || lastModified == "" {
+		t.Fatalf("feed has ETag %q and Last-Modified %q", etag, lastModified)
+	}
+	testutil.ExpectStatus(t, h.do(http.MethodGet, "/feeds/rss.xml", "", nil, "If-None-Match", etag), http.StatusNotModified)
+	testutil.ExpectStatus(t, h.do(http.MethodGet, "/feeds/rss.xml", "", nil, "If-Modified-Since", lastModified), http.StatusNotModified)
+	testutil.ExpectStatus(t, h.do(http.MethodGet, "/feeds/authors/alice/rss.xml", "", nil, "If-Modified-Since", lastModified), http.StatusNotModified)
+
+	// If-None-Match takes precedence over If-Modified-Since
+	testutil.ExpectStatus(t, h.do(http.MethodGet, "/feeds/rss.xml", "", nil, "If-None-Match", `"other"`, "If-Modified-Since", lastModified), http.StatusOK)
+
+	// Deleting the only entry empties the feed and advances Last-Modified
+	testutil.ExpectStatus(t, h.do(http.MethodDelete, fmt.Sprintf("/api/articles/%d", f.Published.ID), h.tokenFor(f.Alice), nil, "If-Match", `"v1"`), http.StatusOK)
+	testutil.ExpectStatus(t, h.do(http.MethodGet, "/feeds/rss.xml", "", nil, "If-Modified-Since", lastModified), http.StatusOK)
+	testutil.ExpectStatus(t, h.do(http.MethodGet, "/feeds/authors/alice/rss.xml", "", nil, "If-Modified-Since", lastModified), http.StatusOK)
+}
+
+func TestEditLock(t *testing.T) {
+	h := newHarness(t)
+	f := h.seed()
+	aliceToken, bobToken := h.tokenFor(f.Alice), h.tokenFor(f.Bob)
+	path := fmt.Sprintf("/api/articles/%d", f.Draft.ID)
+	update := func(title string) gin.H { return gin.H{"title": title, "content": "Body", "status": "draft"} }
+
+	testutil.ExpectStatus(t, h.do(http.MethodPut, path+"/collaborators/bob", aliceToken, nil), http.StatusOK)
+
+	// Bob starts editing the shared draft
+	bobSession := presence.NewToken()
+	if acquired, err := presence.AcquireLock(context.Background(), f.Draft.ID, f.Bob.ID, bobSession); err != nil || !acquired {
+		t.Fatalf("acquiring the lock = %v, %v", acquired, err)
+	}
+
+	// Alice's save is refused and tells her who holds the lock
+	w := h.do(http.MethodPut, path, aliceToken, update("Alice"), "If-Match", `"v1"`)
+	testutil.ExpectStatus(t, w, http.StatusLocked)
+	var problem struct {
+		Code string
+		Lock models.ArticleLock
+	}
+	testutil.Decode(t, w, &problem)
+	if problem.Code != "locked" || problem.Lock.UserID != f.Bob.ID || problem.Lock.User.Username != "bob" || problem.Lock.User.Email != "" {
+		t.Fatalf("problem = %+v, want bob named as the holder", problem)
+	}
+	if strings.Contains(w.Body.String(), bobSession) {
+		t.Error("the response leaks the holder's session token")
+	}
+
+	// Bob's other sessions save with a warning, the holding one silently
+	w = h.do(http.MethodPut, path, bobToken, update("Bob's tab"), "If-Match", `"v1"`)
+	testutil.ExpectStatus(t, w, http.StatusOK)
+	if w.Header().Get("Warning") == "" {
+		t.Error("missing Warning for a save from another of the holder's sessions")
+	}
+	w = h.do(http.MethodPut, path, bobToken, update("Bob"), "If-Match", `"v2"`, "X-Edit-Lock", bobSession)
+	testutil.ExpectStatus(t, w, http.StatusOK)
+	if warning := w.Header().Get("Warning"); warning != "" {
+		t.Errorf("Warning = %q for the holding session", warning)
+	}
+
+	// Once the lock is released, Alice saves again
+	if err := presence.ReleaseLock(context.Background(), bobSession); err != nil {
+		t.Fatal(err)
+	}
+	testutil.ExpectStatus(t, h.do(http.MethodPut, path, aliceToken, update("Alice"), "If-Match", `"v3"`), http.StatusOK)
+}
+
+func TestExportDownload(t *testing.T) {
+	h := newHarness(t)
+	f := h.seed()
+
+	path := filepath.Join(t.TempDir(), "export.zip")
+	if err := os.WriteFile(path, []byte("zip data"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	expiresAt := time.Now().Add(time.Hour)
+	job := models.ExportJob{UserID: f.Alice.ID, Scope: exporter.ScopeUser, SubjectUserID: &f.Alice.ID, Status: models.ExportStatusCompleted, FilePath: path, Token: "secret", ExpiresAt: &expiresAt}
+	pending := models.ExportJob{UserID: f.Alice.ID, Scope: exporter.ScopeUser, SubjectUserID: &f.Alice.ID, Status: models.ExportStatusPending}
+	for _, j := range []*models.ExportJob{&job, &pending} {
+		if err := h.db.Create(j).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	download := func(job models.ExportJob) string {
+		return fmt.Sprintf("/api/exports/%d/download", job.ID)
+	}
+
+	// The link works without authentication, with the job's token only
+	w := h.do(http.MethodGet, download(job)+"?token=secret", "", nil)
+	testutil.ExpectStatus(t, w, http.StatusOK)
+	if w.Body.String() != "zip data" || w.Header().Get("Cache-Control") != "private, no-store" {
+		t.Errorf("download = %q with Cache-Control %q", w.Body, w.Header().Get("Cache-Control"))
+	}
+	testutil.ExpectStatus(t, h.do(http.MethodGet, download(job), "", nil), http.StatusNotFound)
+	testutil.ExpectStatus(t, h.do(http.MethodGet, download(job)+"?token=guess", "", nil), http.StatusNotFound)
+	testutil.ExpectStatus(t, h.do(http.MethodGet, download(pending)+"?token=", "", nil), http.StatusNotFound)
+
+	// Links expire after exporter.LinkTTL
+	if err := h.db.Model(&job).Update("expires_at", time.Now().Add(-time.Minute)).Error; err != nil {
+		t.Fatal(err)
+	}
+	testutil.ExpectStatus(t, h.do(http.MethodGet, download(job)+"?token=secret", "", nil), http.StatusGone)
+}
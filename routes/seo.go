@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/controllers"
+	"github.com/jasen-devvv/mini-blog-backend/middleware"
+)
+
+// SetupSEORoutes sets up search engine routes for the application.
+//
+// Available routes:
+//   - GET /robots.txt             -> Crawler rules pointing to the sitemap
+//   - GET /sitemap.xml            -> Sitemap, or a sitemap index for large blogs
+//   - GET /sitemaps/:file         -> One page of a split sitemap (articles-{n}.xml)
+//   - GET /api/articles/:id/meta  -> Open Graph and Twitter card metadata of an article
+func SetupSEORoutes(router *gin.Engine, handler *controllers.Handler) {
+	router.GET("/robots.txt", handler.Robots)
+	router.GET("/sitemap.xml", handler.Sitemap)
+	router.GET("/sitemaps/:file", handler.SitemapPage)
+	router.GET("/api/articles/:id/meta", middleware.OptionalAuthMiddleware(handler.Config), handler.GetArticleMeta)
+}
@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/controllers"
+)
+
+// SetupSyndicationRoutes sets up the public syndication feed routes for the application.
+//
+// Available routes:
+//   - GET /feeds/rss.xml   -> RSS 2.0 feed of the newest articles
+//   - GET /feeds/atom.xml  -> Atom 1.0 feed of the newest articles
+//   - GET /feeds/feed.json -> JSON Feed 1.1 of the newest articles
+//
+// Each feed is also available for a single author under /feeds/authors/:username/
+// and for a single tag under /feeds/tags/:tag/. All feeds support conditional GET
+// with If-None-Match or If-Modified-Since.
+func SetupSyndicationRoutes(router *gin.Engine, handler *controllers.Handler) {
+	for _, prefix := range []string{"/feeds", "/feeds/authors/:username", "/feeds/tags/:tag"} {
+		group := router.Group(prefix)
+		{
+			group.GET("/rss.xml", handler.RSSFeed)
+			group.GET("/atom.xml", handler.AtomFeed)
+			group.GET("/feed.json", handler.JSONFeed)
+		}
+	}
+}
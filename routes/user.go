@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jasen-devvv/mini-blog-backend/controllers"
+	"github.com/jasen-devvv/mini-blog-backend/middleware"
+)
+
+// SetupUserRoutes sets up user profile routes for the application.
+//
+// Available routes:
+//   - GET    /api/users/:username        -> Fetch a public author profile with their published articles
+//   - PUT    /api/users/me               -> Update the authenticated user's profile (requires authentication)
+//   - POST   /api/users/:username/follow -> Follow an author (requires authentication)
+//   - DELETE /api/users/:username/follow -> Unfollow an author (requires authentication)
+func SetupUserRoutes(router *gin.Engine, handler *controllers.Handler) {
+	users := router.Group("/api/users")
+	{
+		users.GET("/:username", handler.GetUserProfile)
+		users.PUT("/me", middleware.AuthMiddleware(handler.Config), handler.UpdateProfile)
+		users.POST("/:username/follow", middleware.AuthMiddleware(handler.Config), handler.FollowUser)
+		users.DELETE("/:username/follow", middleware.AuthMiddleware(handler.Config), handler.UnfollowUser)
+	}
+}
@@ -0,0 +1,224 @@
+// Package sanitize makes user supplied content safe to embed in HTML.
+//
+// HTML keeps a small allowlist of formatting tags and attributes and drops
+// everything else, including scripts, styles, event handlers and unsafe URLs.
+// Text renders plain text as escaped paragraphs.
+package sanitize
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+
+	xhtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// allowedTags maps each allowed tag to the attributes it may keep.
+var allowedTags = map[atom.Atom][]string{
+	atom.A:          {"href", "title"},
+	atom.Abbr:       {"title"},
+	atom.B:          nil,
+	atom.Blockquote: nil,
+	atom.Br:         nil,
+	atom.Code:       nil,
+	atom.Del:        nil,
+	atom.Em:         nil,
+	atom.H1:         nil,
+	atom.H2:         nil,
+	atom.H3:         nil,
+	atom.H4:         nil,
+	atom.H5:         nil,
+	atom.H6:         nil,
+	atom.Hr:         nil,
+	atom.I:          nil,
+	atom.Img:        {"src", "alt", "title"},
+	atom.Li:         nil,
+	atom.Ol:         nil,
+	atom.P:          nil,
+	atom.Pre:        nil,
+	atom.S:          nil,
+	atom.Strong:     nil,
+	atom.Sub:        nil,
+	atom.Sup:        nil,
+	atom.Ul:         nil,
+}
+
+// droppedContent lists tags whose content is removed along with the tag.
+var droppedContent = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Iframe:   true,
+	atom.Object:   true,
+	atom.Embed:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Textarea: true,
+	atom.Title:    true,
+}
+
+// tagPattern detects markup, telling HTML apart from plain text.
+var tagPattern = regexp.MustCompile(`<[a-zA-Z/!]`)
+
+// urlAttributes lists attributes holding URLs, which must use a safe scheme.
+var urlAttributes = map[string]bool{"href": true, "src": true}
+
+// HTML returns a sanitized copy of an HTML fragment.
+//
+// Content that contains no tags at all is treated as plain text and converted with Text,
+// so articles written without markup still render as paragraphs.
+func HTML(input string) string {
+	if !tagPattern.MatchString(input) {
+		return Text(input)
+	}
+
+	var b strings.Builder
+	tokenizer := xhtml.NewTokenizer(strings.NewReader(input))
+	skipDepth := 0
+
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == xhtml.ErrorToken {
+			// io.EOF marks the end of the input; other errors leave what was written so far
+			return b.String()
+		}
+
+		token := tokenizer.Token()
+		switch tokenType {
+		case xhtml.StartTagToken, xhtml.SelfClosingTagToken:
+			if droppedContent[token.DataAtom] {
+				if tokenType == xhtml.StartTagToken {
+					skipDepth++
+				}
+				continue
+			}
+			if skipDepth > 0 {
+				continue
+			}
+			if attrs, ok := allowedTags[token.DataAtom]; ok {
+				writeTag(&b, token, attrs, tokenType == xhtml.SelfClosingTagToken)
+			}
+
+		case xhtml.EndTagToken:
+			if droppedContent[token.DataAtom] {
+				if skipDepth > 0 {
+					skipDepth--
+				}
+				continue
+			}
+			if skipDepth > 0 {
+				continue
+			}
+			if _, ok := allowedTags[token.DataAtom]; ok {
+				b.WriteString("</" + token.DataAtom.String() + ">")
+			}
+
+		case xhtml.TextToken:
+			if skipDepth == 0 {
+				b.WriteString(html.EscapeString(token.Data))
+			}
+		}
+	}
+}
+
+// Text escapes plain text and turns blank-line separated blocks into paragraphs,
+// with single line breaks kept as <br>.
+func Text(input string) string {
+	input = strings.ReplaceAll(input, "\r\n", "\n")
+
+	var b strings.Builder
+	for _, block := range strings.Split(input, "\n\n") {
+		block = strings.TrimSpace(block)
+		if block == "" {
+			continue
+		}
+		lines := strings.Split(block, "\n")
+		for i := range lines {
+			lines[i] = html.EscapeString(lines[i])
+		}
+		b.WriteString("<p>" + strings.Join(lines, "<br>") + "</p>")
+	}
+	return b.String()
+}
+
+// Strip removes all markup and returns the plain text content, with whitespace collapsed.
+func Strip(input string) string {
+	var b strings.Builder
+	tokenizer := xhtml.NewTokenizer(strings.NewReader(input))
+	skipDepth := 0
+
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == xhtml.ErrorToken {
+			return strings.Join(strings.Fields(b.String()), " ")
+		}
+
+		token := tokenizer.Token()
+		switch tokenType {
+		case xhtml.StartTagToken:
+			if droppedContent[token.DataAtom] {
+				skipDepth++
+			}
+			b.WriteString(" ")
+		case xhtml.EndTagToken:
+			if droppedContent[token.DataAtom] && skipDepth > 0 {
+				skipDepth--
+			}
+			b.WriteString(" ")
+		case xhtml.TextToken:
+			if skipDepth == 0 {
+				b.WriteString(token.Data)
+			}
+		}
+	}
+}
+
+// SafeURL reports whether a URL is absolute http(s) or mailto, or relative.
+// Schemes such as javascript: and data: are rejected.
+func SafeURL(raw string) bool {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	}
+	return false
+}
+
+// writeTag writes a start tag keeping only the allowed attributes with safe values.
+func writeTag(b *strings.Builder, token xhtml.Token, allowed []string, selfClosing bool) {
+	b.WriteString("<" + token.DataAtom.String())
+
+	for _, attr := range token.Attr {
+		if attr.Namespace != "" || !contains(allowed, attr.Key) {
+			continue
+		}
+		if urlAttributes[attr.Key] && !SafeURL(attr.Val) {
+			continue
+		}
+		b.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+	}
+
+	// Links to other sites must not pass on referrer or window access
+	if token.DataAtom == atom.A {
+		b.WriteString(` rel="nofollow noopener noreferrer"`)
+	}
+
+	if selfClosing {
+		b.WriteString(" />")
+		return
+	}
+	b.WriteString(">")
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jasen-devvv/mini-blog-backend/metrics"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"github.com/jasen-devvv/mini-blog-backend/repository"
+)
+
+// slugAttempts is how many slugs Create tries for an article, when concurrent requests
+// take the ones it picked first.
+const slugAttempts = 5
+
+// ArticleService manages articles and their tags.
+type ArticleService struct {
+	articles repository.ArticleRepository
+}
+
+// NewArticleService returns an ArticleService.
+func NewArticleService(articles repository.ArticleRepository) *ArticleService {
+	return &ArticleService{articles: articles}
+}
+
+// List returns every published article, newest first.
+func (s *ArticleService) List(ctx context.Context) ([]models.Article, error) {
+	return s.articles.ListPublished(ctx)
+}
+
+// Get returns an article. Drafts are only visible to the users who may edit them (see
+// CanEdit): for anyone else (viewerID 0 for anonymous callers) it returns ErrNotFound.
+func (s *ArticleService) Get(ctx context.Context, id, viewerID uint) (*models.Article, error) {
+	article, err := s.articles.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if article.Status != models.ArticleStatusPublished {
+		canEdit, err := s.CanEdit(ctx, article, viewerID)
+		if err != nil {
+			return nil, err
+		}
+		if !canEdit {
+			return nil, ErrNotFound
+		}
+	}
+	return article, nil
+}
+
+// CanEdit reports whether a user may change an article: its author and the users it is
+// shared with may.
+func (s *ArticleService) CanEdit(ctx context.Context, article *models.Article, userID uint) (bool, error) {
+	if userID == 0 {
+		return false, nil
+	}
+	if article.UserID == userID {
+		return true, nil
+	}
+	return s.articles.IsCollaborator(ctx, article.ID, userID)
+}
+
+// Editable returns an article the user may change: ErrNotFound when it does not exist
+// and ErrForbidden when the user is neither its author nor a collaborator.
+func (s *ArticleService) Editable(ctx context.Context, id, userID uint) (*models.Article, error) {
+	article, err := s.articles.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	canEdit, err := s.CanEdit(ctx, article, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit {
+		return nil, ErrForbidden
+	}
+	return article, nil
+}
+
+// Owned returns an article the user wrote, for the changes only its author may make
+// (deleting it and sharing it): ErrNotFound when it does not exist and ErrForbidden when
+// the user is not its author.
+func (s *ArticleService) Owned(ctx context.Context, id, userID uint) (*models.Article, error) {
+	article, err := s.articles.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if article.UserID != userID {
+		return nil, ErrForbidden
+	}
+	return article, nil
+}
+
+// Create stores a new article with a unique slug derived from its title, and the
+// given tags. It returns the stored article with its author and tags.
+//
+// The slug is picked before the insert, so a concurrent request can take it first: the
+// insert then fails with ErrConflict and Create picks the next free slug. It returns
+// ErrConflict after slugAttempts failed inserts.
+func (s *ArticleService) Create(ctx context.Context, article *models.Article, tagNames []string) (*models.Article, error) {
+	tags, err := s.articles.FindOrCreateTags(ctx, normalizeTags(tagNames))
+	if err != nil {
+		return nil, err
+	}
+	article.Tags = tags
+
+	for attempt := 1; ; attempt++ {
+		slug, err := s.articles.UniqueSlug(ctx, article.Title)
+		if err != nil {
+			return nil, err
+		}
+		article.Slug = slug
+
+		err = s.articles.Create(ctx, article)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, ErrConflict) || attempt == slugAttempts {
+			return nil, err
+		}
+	}
+	metrics.ArticlesCreated.Inc()
+	return s.articles.FindByID(ctx, article.ID)
+}
+
+// Update saves the changed fields of an article if it is still at the given version, and
+// replaces its tags unless tagNames is nil, in one transaction. It returns ErrConflict
+// when the article changed in the meantime, and the stored article otherwise.
+func (s *ArticleService) Update(ctx context.Context, article *models.Article, version uint, tagNames []string) (*models.Article, error) {
+	err := s.articles.Transaction(ctx, func(articles repository.ArticleRepository) error {
+		if err := articles.Update(ctx, article, version); err != nil {
+			return err
+		}
+		if tagNames == nil {
+			return nil
+		}
+		tags, err := articles.FindOrCreateTags(ctx, normalizeTags(tagNames))
+		if err != nil {
+			return err
+		}
+		return articles.ReplaceTags(ctx, article, tags)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.articles.FindByID(ctx, article.ID)
+}
+
+// Delete removes an article if it is still at the given version, and returns
+// ErrConflict otherwise.
+func (s *ArticleService) Delete(ctx context.Context, article *models.Article, version uint) error {
+	return s.articles.Delete(ctx, article, version)
+}
+
+// Collaborators returns the users an article is shared with, by username.
+func (s *ArticleService) Collaborators(ctx context.Context, article *models.Article) ([]models.User, error) {
+	return s.articles.ListCollaborators(ctx, article.ID)
+}
+
+// Share lets a user edit an article. It returns ErrInvalidCollaborator for the author,
+// who can edit it anyway.
+func (s *ArticleService) Share(ctx context.Context, article *models.Article, user *models.User) error {
+	if user.ID == article.UserID {
+		return ErrInvalidCollaborator
+	}
+	return s.articles.AddCollaborator(ctx, article.ID, user.ID)
+}
+
+// Unshare stops letting a user edit an article. It returns ErrNotFound when the article
+// was not shared with them.
+func (s *ArticleService) Unshare(ctx context.Context, article *models.Article, user *models.User) error {
+	return s.articles.RemoveCollaborator(ctx, article.ID, user.ID)
+}
+
+// normalizeTags normalizes tag names and removes empty names and duplicates.
+// Names are lowercased, trimmed and have spaces replaced by dashes.
+func normalizeTags(names []string) []string {
+	normalized := []string{}
+	seen := map[string]bool{}
+
+	for _, name := range names {
+		name = models.NormalizeTagName(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+	return normalized
+}
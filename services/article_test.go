@@ -0,0 +1,116 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"github.com/jasen-devvv/mini-blog-backend/repository"
+	"github.com/jasen-devvv/mini-blog-backend/repository/memory"
+	"github.com/jasen-devvv/mini-blog-backend/services"
+)
+
+// failingTags is an ArticleRepository whose ReplaceTags fails, also within transactions.
+type failingTags struct {
+	repository.ArticleRepository
+}
+
+func (r failingTags) ReplaceTags(ctx context.Context, article *models.Article, tags []models.Tag) error {
+	return errors.New("tags are unavailable")
+}
+
+func (r failingTags) Transaction(ctx context.Context, fn func(articles repository.ArticleRepository) error) error {
+	return r.ArticleRepository.Transaction(ctx, func(articles repository.ArticleRepository) error {
+		return fn(failingTags{articles})
+	})
+}
+
+func TestUpdateIsAtomic(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	author := models.User{Username: "alice", Email: "alice@example.com"}
+	if err := store.Users().Create(ctx, &author); err != nil {
+		t.Fatal(err)
+	}
+
+	articles := services.NewArticleService(store.Articles())
+	article, err := articles.Create(ctx, &models.Article{Title: "Title", Content: "Body", UserID: author.ID}, []string{"go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A failure to replace the tags leaves the fields unchanged too
+	failing := services.NewArticleService(failingTags{store.Articles()})
+	changed := *article
+	changed.Title = "Changed"
+	if _, err := failing.Update(ctx, &changed, article.Version, []string{"web"}); err == nil {
+		t.Fatal("update succeeded although the tags could not be replaced")
+	}
+
+	stored, err := articles.Get(ctx, article.ID, author.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Title != "Title" || stored.Version != article.Version || len(stored.Tags) != 1 || stored.Tags[0].Name != "go" {
+		t.Errorf("article after the failed update = %+v", stored)
+	}
+
+	// Without new tags, the fields are saved on their own
+	saved, err := failing.Update(ctx, &changed, article.Version, nil)
+	if err != nil {
+		t.Fatalf("updating without tags: %v", err)
+	}
+	if saved.Title != "Changed" || saved.Version != article.Version+1 || len(saved.Tags) != 1 {
+		t.Errorf("article after the update = %+v", saved)
+	}
+}
+
+// racingSlugs is an ArticleRepository whose UniqueSlug returns taken for its first
+// calls, as when a concurrent request stores an article with the slug before the insert.
+type racingSlugs struct {
+	repository.ArticleRepository
+	taken string
+	races *int
+}
+
+func (r racingSlugs) UniqueSlug(ctx context.Context, text string) (string, error) {
+	if *r.races > 0 {
+		*r.races--
+		return r.taken, nil
+	}
+	return r.ArticleRepository.UniqueSlug(ctx, text)
+}
+
+func TestCreateRetriesTakenSlugs(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	author := models.User{Username: "alice", Email: "alice@example.com"}
+	if err := store.Users().Create(ctx, &author); err != nil {
+		t.Fatal(err)
+	}
+	first, err := services.NewArticleService(store.Articles()).Create(ctx, &models.Article{Title: "Title", Content: "Body", UserID: author.ID}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The insert losing the race picks the next free slug
+	races := 2
+	articles := services.NewArticleService(racingSlugs{store.Articles(), first.Slug, &races})
+	second, err := articles.Create(ctx, &models.Article{Title: "Title", Content: "Body", UserID: author.ID}, []string{"go"})
+	if err != nil {
+		t.Fatalf("creating with a taken slug: %v", err)
+	}
+	if second.Slug != "title-2" || len(second.Tags) != 1 || races != 0 {
+		t.Errorf("article = %+v after %d races left, want the slug title-2", second, races)
+	}
+
+	// Losing every time gives up with ErrConflict
+	races = 10
+	if _, err := articles.Create(ctx, &models.Article{Title: "Title", Content: "Body", UserID: author.ID}, nil); !errors.Is(err, services.ErrConflict) {
+		t.Errorf("error = %v, want ErrConflict", err)
+	}
+	if races != 5 {
+		t.Errorf("tried %d slugs, want 5", 10-races)
+	}
+}
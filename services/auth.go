@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jasen-devvv/mini-blog-backend/metrics"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"github.com/jasen-devvv/mini-blog-backend/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TokenTTL is how long authentication tokens stay valid.
+const TokenTTL = 7 * 24 * time.Hour
+
+// AuthService registers users and logs them in.
+type AuthService struct {
+	users  repository.UserRepository
+	secret []byte
+}
+
+// NewAuthService returns an AuthService signing tokens with secret.
+func NewAuthService(users repository.UserRepository, secret string) *AuthService {
+	return &AuthService{users: users, secret: []byte(secret)}
+}
+
+// Register creates a user with a hashed password.
+// It returns ErrConflict when the username or email is taken.
+func (s *AuthService) Register(ctx context.Context, username, email, password string) (*models.User, error) {
+	// Hash password for secure storage
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		Username: username,
+		Email:    email,
+		Password: string(hashedPassword),
+	}
+	if err := s.users.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	metrics.Registrations.Inc()
+	return user, nil
+}
+
+// Login checks a user's email and password and returns a signed token for them.
+// It returns ErrInvalidCredentials when either is wrong.
+func (s *AuthService) Login(ctx context.Context, email, password string) (string, *models.User, error) {
+	user, err := s.users.FindByEmail(ctx, email)
+	if errors.Is(err, repository.ErrNotFound) {
+		metrics.LoginFailures.Inc()
+		return "", nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Verify password against stored hash
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		metrics.LoginFailures.Inc()
+		return "", nil, ErrInvalidCredentials
+	}
+
+	token, err := s.IssueToken(user)
+	if err != nil {
+		return "", nil, err
+	}
+	metrics.Logins.Inc()
+	return token, user, nil
+}
+
+// IssueToken returns a signed token authenticating the user, valid for TokenTTL.
+func (s *AuthService) IssueToken(user *models.User) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": user.ID,
+		"exp":     time.Now().Add(TokenTTL).Unix(),
+	})
+	return token.SignedString(s.secret)
+}
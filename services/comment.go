@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jasen-devvv/mini-blog-backend/metrics"
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"github.com/jasen-devvv/mini-blog-backend/notifications"
+	"github.com/jasen-devvv/mini-blog-backend/repository"
+)
+
+// CommentService manages the comments of articles.
+type CommentService struct {
+	articles repository.ArticleRepository
+	comments repository.CommentRepository
+	notify   func(context.Context, notifications.Event)
+}
+
+// NewCommentService returns a CommentService. New comments are reported to notify
+// (notifications.Notify in production).
+func NewCommentService(articles repository.ArticleRepository, comments repository.CommentRepository, notify func(context.Context, notifications.Event)) *CommentService {
+	return &CommentService{articles: articles, comments: comments, notify: notify}
+}
+
+// List returns the comments of an article, oldest first.
+func (s *CommentService) List(ctx context.Context, articleID uint) ([]models.Comment, error) {
+	return s.comments.ListByArticle(ctx, articleID)
+}
+
+// Create adds a comment by the user to an article, as a reply when parentID is set.
+// It returns ErrNotFound when the article does not exist and ErrInvalidParent when the
+// parent comment is not on the same article.
+// The article author is notified, and for replies the author of the parent comment too.
+func (s *CommentService) Create(ctx context.Context, articleID, userID uint, content string, parentID *uint) (*models.Comment, error) {
+	article, err := s.articles.FindByID(ctx, articleID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify the parent comment belongs to the same article when replying
+	var parent *models.Comment
+	if parentID != nil {
+		if parent, err = s.comments.FindInArticle(ctx, article.ID, *parentID); errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidParent
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	comment := &models.Comment{
+		Content:   content,
+		UserID:    &userID,
+		ArticleID: article.ID,
+		ParentID:  parentID,
+	}
+	if err := s.comments.Create(ctx, comment); err != nil {
+		return nil, err
+	}
+	metrics.CommentsPosted.Inc()
+
+	// Notify the article author, and the parent comment author for replies
+	s.notify(ctx, notifications.Event{
+		Type:        models.NotificationTypeComment,
+		RecipientID: article.UserID,
+		ActorID:     userID,
+		ArticleID:   &article.ID,
+		CommentID:   &comment.ID,
+	})
+	if parent != nil && parent.UserID != nil && *parent.UserID != article.UserID {
+		s.notify(ctx, notifications.Event{
+			Type:        models.NotificationTypeReply,
+			RecipientID: *parent.UserID,
+			ActorID:     userID,
+			ArticleID:   &article.ID,
+			CommentID:   &parent.ID,
+		})
+	}
+
+	return s.comments.FindByID(ctx, comment.ID)
+}
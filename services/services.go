@@ -0,0 +1,31 @@
+// Package services holds the business rules of the core features: accounts, articles,
+// comments and profiles. Services work on the repository interfaces and know nothing
+// about HTTP; the controllers translate their errors into responses.
+package services
+
+import (
+	"errors"
+
+	"github.com/jasen-devvv/mini-blog-backend/repository"
+)
+
+var (
+	// ErrNotFound is returned when a record does not exist or is hidden from the caller.
+	ErrNotFound = repository.ErrNotFound
+
+	// ErrConflict is returned when a record is taken (such as a username) or was changed
+	// by someone else in the meantime.
+	ErrConflict = repository.ErrConflict
+
+	// ErrForbidden is returned when the caller may not change a record.
+	ErrForbidden = errors.New("not allowed to change this record")
+
+	// ErrInvalidCredentials is returned when logging in with a wrong email or password.
+	ErrInvalidCredentials = errors.New("invalid email or password")
+
+	// ErrInvalidParent is returned when replying to a comment of another article.
+	ErrInvalidParent = errors.New("parent comment not found on this article")
+
+	// ErrInvalidCollaborator is returned when an author shares an article with themselves.
+	ErrInvalidCollaborator = errors.New("the author cannot collaborate on their own article")
+)
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+
+	"github.com/jasen-devvv/mini-blog-backend/models"
+	"github.com/jasen-devvv/mini-blog-backend/repository"
+)
+
+// UserService manages user profiles.
+type UserService struct {
+	users    repository.UserRepository
+	articles repository.ArticleRepository
+}
+
+// NewUserService returns a UserService.
+func NewUserService(users repository.UserRepository, articles repository.ArticleRepository) *UserService {
+	return &UserService{users: users, articles: articles}
+}
+
+// Profile is the public profile of an author.
+//
+// Fields:
+//   - User: The author.
+//   - Followers: Number of users following the author.
+//   - Following: Number of users the author follows.
+//   - Articles: A page of the author's published articles, newest first.
+//   - Total: Number of published articles.
+type Profile struct {
+	User      models.User
+	Followers int64
+	Following int64
+	Articles  []models.Article
+	Total     int64
+}
+
+// Profile returns the profile of the user with the given username and a page of their
+// published articles.
+func (s *UserService) Profile(ctx context.Context, username string, page, limit int) (*Profile, error) {
+	user, err := s.users.FindByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	articles, total, err := s.articles.ListPublishedByAuthor(ctx, user.ID, (page-1)*limit, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	followers, err := s.users.CountFollowers(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	following, err := s.users.CountFollowing(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Profile{User: *user, Followers: followers, Following: following, Articles: articles, Total: total}, nil
+}
+
+// UpdateProfile replaces the profile fields of a user with those of profile (display
+// name, bio, avatar, website and social links) and returns the updated user.
+func (s *UserService) UpdateProfile(ctx context.Context, userID uint, profile models.User) (*models.User, error) {
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.DisplayName = profile.DisplayName
+	user.Bio = profile.Bio
+	user.AvatarURL = profile.AvatarURL
+	user.Website = profile.Website
+	user.SocialLinks = profile.SocialLinks
+	if user.SocialLinks == nil {
+		user.SocialLinks = models.SocialLinks{}
+	}
+
+	if err := s.users.UpdateProfile(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Get returns the user with the given ID.
+func (s *UserService) Get(ctx context.Context, id uint) (*models.User, error) {
+	return s.users.FindByID(ctx, id)
+}
+
+// Find returns the user with the given username.
+func (s *UserService) Find(ctx context.Context, username string) (*models.User, error) {
+	return s.users.FindByUsername(ctx, username)
+}
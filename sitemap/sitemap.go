@@ -0,0 +1,84 @@
+// Package sitemap renders XML sitemaps and sitemap indexes following the
+// sitemaps.org protocol.
+package sitemap
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// MaxURLs is the number of URLs per sitemap file. The protocol allows up to
+// 50,000; a lower limit keeps files small and quick to generate.
+const MaxURLs = 10000
+
+const namespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// URL is a single page listed in a sitemap.
+//
+// Fields:
+//   - Loc: Absolute URL of the page.
+//   - LastMod: Time the page was last modified (omitted when zero).
+type URL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+// Entry is a sitemap file listed in a sitemap index.
+//
+// Fields:
+//   - Loc: Absolute URL of the sitemap file.
+//   - LastMod: Time the most recent page in the file was modified (omitted when zero).
+type Entry struct {
+	Loc     string
+	LastMod time.Time
+}
+
+type xmlLocation struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// URLSet renders a sitemap listing the given pages.
+func URLSet(urls []URL) ([]byte, error) {
+	doc := struct {
+		XMLName xml.Name      `xml:"urlset"`
+		NS      string        `xml:"xmlns,attr"`
+		URLs    []xmlLocation `xml:"url"`
+	}{NS: namespace}
+
+	for _, u := range urls {
+		doc.URLs = append(doc.URLs, xmlLocation{Loc: u.Loc, LastMod: formatTime(u.LastMod)})
+	}
+
+	return marshal(doc)
+}
+
+// Index renders a sitemap index listing the given sitemap files.
+func Index(entries []Entry) ([]byte, error) {
+	doc := struct {
+		XMLName  xml.Name      `xml:"sitemapindex"`
+		NS       string        `xml:"xmlns,attr"`
+		Sitemaps []xmlLocation `xml:"sitemap"`
+	}{NS: namespace}
+
+	for _, e := range entries {
+		doc.Sitemaps = append(doc.Sitemaps, xmlLocation{Loc: e.Loc, LastMod: formatTime(e.LastMod)})
+	}
+
+	return marshal(doc)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func marshal(doc interface{}) ([]byte, error) {
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
@@ -0,0 +1,337 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Exporter sends finished spans somewhere they can be inspected.
+type Exporter interface {
+	// Export sends a batch of spans.
+	Export(ctx context.Context, spans []SpanData) error
+	// Shutdown releases the exporter's resources.
+	Shutdown(ctx context.Context) error
+}
+
+const (
+	// maxQueueSize is the number of spans waiting for export above which spans are dropped.
+	maxQueueSize = 2048
+	// maxBatchSize is the number of spans exported at once.
+	maxBatchSize = 512
+	// batchInterval is how often queued spans are exported.
+	batchInterval = 5 * time.Second
+	// exportTimeout limits the time one export may take.
+	exportTimeout = 10 * time.Second
+)
+
+// current is the processor of the exporter configured with Setup, or nil.
+var current atomic.Pointer[processor]
+
+// Setup exports ended spans with exporter, in batches, in the background. Pass nil to
+// stop exporting. Call Shutdown before exiting so queued spans are not lost.
+func Setup(exporter Exporter) {
+	var p *processor
+	if exporter != nil {
+		p = newProcessor(exporter)
+	}
+	if previous := current.Swap(p); previous != nil {
+		previous.shutdown(context.Background())
+	}
+}
+
+// Shutdown exports the queued spans and shuts the exporter down.
+func Shutdown(ctx context.Context) error {
+	if p := current.Swap(nil); p != nil {
+		return p.shutdown(ctx)
+	}
+	return nil
+}
+
+// export queues an ended span for export.
+func export(span SpanData) {
+	if p := current.Load(); p != nil {
+		p.enqueue(span)
+	}
+}
+
+// processor queues spans and exports them in batches.
+type processor struct {
+	exporter Exporter
+	queue    chan SpanData
+	done     chan struct{}
+
+	mu     sync.RWMutex
+	closed bool // the queue was closed by shutdown
+}
+
+func newProcessor(exporter Exporter) *processor {
+	p := &processor{exporter: exporter, queue: make(chan SpanData, maxQueueSize), done: make(chan struct{})}
+	go p.run()
+	return p
+}
+
+// enqueue queues a span, dropping it when the queue is full rather than slowing down
+// the request that ended it.
+func (p *processor) enqueue(span SpanData) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return
+	}
+	select {
+	case p.queue <- span:
+	default:
+	}
+}
+
+// run exports batches until the queue is closed.
+func (p *processor) run() {
+	defer close(p.done)
+	ticker := time.NewTicker(batchInterval)
+	defer ticker.Stop()
+
+	batch := make([]SpanData, 0, maxBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+		if err := p.exporter.Export(ctx, batch); err != nil {
+			slog.Warn("failed to export spans", "spans", len(batch), "error", err)
+		}
+		cancel()
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case span, ok := <-p.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, span)
+			if len(batch) == maxBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// shutdown exports the queued spans and shuts the exporter down.
+func (p *processor) shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.queue)
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return p.exporter.Shutdown(ctx)
+}
+
+// NewWriterExporter returns an exporter writing each span to w as a line of JSON, for
+// local debugging without a collector.
+func NewWriterExporter(w io.Writer) Exporter {
+	return &writerExporter{w: w}
+}
+
+// NewFileExporter returns an exporter appending spans to the file at path as lines of JSON.
+func NewFileExporter(path string) (Exporter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &writerExporter{w: file, closer: file}, nil
+}
+
+type writerExporter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// writtenSpan is the JSON form of a span written by writerExporter.
+type writtenSpan struct {
+	TraceID       string                 `json:"trace_id"`
+	SpanID        string                 `json:"span_id"`
+	ParentSpanID  string                 `json:"parent_span_id,omitempty"`
+	Name          string                 `json:"name"`
+	Kind          string                 `json:"kind"`
+	Start         time.Time              `json:"start"`
+	DurationMS    float64                `json:"duration_ms"`
+	Attributes    map[string]interface{} `json:"attributes,omitempty"`
+	Status        string                 `json:"status,omitempty"`
+	StatusMessage string                 `json:"status_message,omitempty"`
+}
+
+func (e *writerExporter) Export(ctx context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	encoder := json.NewEncoder(e.w)
+	for _, span := range spans {
+		written := writtenSpan{
+			TraceID:       span.TraceID.String(),
+			Name:          span.Name,
+			SpanID:        span.SpanID.String(),
+			Kind:          map[SpanKind]string{SpanKindInternal: "internal", SpanKindServer: "server", SpanKindClient: "client"}[span.Kind],
+			Start:         span.Start,
+			DurationMS:    float64(span.End.Sub(span.Start).Microseconds()) / 1000,
+			Attributes:    span.Attributes,
+			Status:        map[StatusCode]string{StatusOK: "ok", StatusError: "error"}[span.Status],
+			StatusMessage: span.StatusMessage,
+		}
+		if span.ParentSpanID.IsValid() {
+			written.ParentSpanID = span.ParentSpanID.String()
+		}
+		if err := encoder.Encode(written); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *writerExporter) Shutdown(ctx context.Context) error {
+	if e.closer != nil {
+		return e.closer.Close()
+	}
+	return nil
+}
+
+// NewOTLPExporter returns an exporter sending spans to an OpenTelemetry collector with
+// OTLP over HTTP, in its JSON encoding. endpoint is the collector's base URL, such as
+// "http://localhost:4318"; spans are posted to its /v1/traces path.
+func NewOTLPExporter(endpoint, serviceName string) Exporter {
+	return &otlpExporter{
+		url:         strings.TrimRight(endpoint, "/") + "/v1/traces",
+		serviceName: serviceName,
+		client:      &http.Client{Timeout: exportTimeout},
+	}
+}
+
+type otlpExporter struct {
+	url         string
+	serviceName string
+	client      *http.Client // not traced, so exports do not create spans themselves
+}
+
+// otlpAttribute and the types below are the parts of the OTLP JSON encoding used here.
+type otlpAttribute struct {
+	Key   string                 `json:"key"`
+	Value map[string]interface{} `json:"value"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              SpanKind        `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            struct {
+		Code    StatusCode `json:"code,omitempty"`
+		Message string     `json:"message,omitempty"`
+	} `json:"status"`
+}
+
+func (e *otlpExporter) Export(ctx context.Context, spans []SpanData) error {
+	encoded := make([]otlpSpan, len(spans))
+	for i, span := range spans {
+		encoded[i] = otlpSpan{
+			TraceID:           span.TraceID.String(),
+			SpanID:            span.SpanID.String(),
+			Name:              span.Name,
+			Kind:              span.Kind,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Attributes:        otlpAttributes(span.Attributes),
+		}
+		if span.ParentSpanID.IsValid() {
+			encoded[i].ParentSpanID = span.ParentSpanID.String()
+		}
+		encoded[i].Status.Code = span.Status
+		encoded[i].Status.Message = span.StatusMessage
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"resourceSpans": []interface{}{map[string]interface{}{
+			"resource": map[string]interface{}{
+				"attributes": otlpAttributes(map[string]interface{}{"service.name": e.serviceName}),
+			},
+			"scopeSpans": []interface{}{map[string]interface{}{
+				"scope": map[string]string{"name": "github.com/jasen-devvv/mini-blog-backend/tracing"},
+				"spans": encoded,
+			}},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("collector responded with %s", resp.Status)
+	}
+	return nil
+}
+
+func (e *otlpExporter) Shutdown(ctx context.Context) error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+// otlpAttributes encodes attributes as OTLP key-value pairs, sorted by key.
+func otlpAttributes(attributes map[string]interface{}) []otlpAttribute {
+	encoded := make([]otlpAttribute, 0, len(attributes))
+	for key, value := range attributes {
+		var v map[string]interface{}
+		switch value := value.(type) {
+		case string:
+			v = map[string]interface{}{"stringValue": value}
+		case int64:
+			v = map[string]interface{}{"intValue": strconv.FormatInt(value, 10)}
+		case float64:
+			v = map[string]interface{}{"doubleValue": value}
+		case bool:
+			v = map[string]interface{}{"boolValue": value}
+		default:
+			v = map[string]interface{}{"stringValue": fmt.Sprint(value)}
+		}
+		encoded = append(encoded, otlpAttribute{Key: key, Value: v})
+	}
+	sort.Slice(encoded, func(i, j int) bool { return encoded[i].Key < encoded[j].Key })
+	return encoded
+}
@@ -0,0 +1,107 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jasen-devvv/mini-blog-backend/internal/testutil"
+	"github.com/jasen-devvv/mini-blog-backend/tracing"
+)
+
+// testSpans returns a server span with a remote parent and a failed database span below it.
+func testSpans() []tracing.SpanData {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	traceID := tracing.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	serverID := tracing.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7}
+
+	return []tracing.SpanData{
+		{
+			TraceID:      traceID,
+			SpanID:       serverID,
+			ParentSpanID: tracing.SpanID{0x53, 0x99, 0x5c, 0x3f, 0x42, 0xcd, 0x8a, 0xd8},
+			Name:         "GET /api/articles/:id",
+			Kind:         tracing.SpanKindServer,
+			Start:        start,
+			End:          start.Add(12345 * time.Microsecond),
+			Attributes: map[string]interface{}{
+				"http.request.method":       "GET",
+				"http.route":                "/api/articles/:id",
+				"http.response.status_code": int64(200),
+			},
+			Status: tracing.StatusUnset,
+		},
+		{
+			TraceID:      traceID,
+			SpanID:       tracing.SpanID{0x1a, 0x2b, 0x3c, 0x4d, 0x5e, 0x6f, 0x70, 0x81},
+			ParentSpanID: serverID,
+			Name:         "query articles",
+			Kind:         tracing.SpanKindClient,
+			Start:        start.Add(time.Millisecond),
+			End:          start.Add(3500 * time.Microsecond),
+			Attributes: map[string]interface{}{
+				"db.system":     "postgresql",
+				"db.statement":  `SELECT * FROM "articles" WHERE id = $1`,
+				"db.rows":       int64(0),
+				"db.cached":     false,
+				"db.sample":     0.25,
+				"custom.struct": struct{ A int }{1},
+			},
+			Status:        tracing.StatusError,
+			StatusMessage: "record not found",
+		},
+	}
+}
+
+func TestOTLPExporterGolden(t *testing.T) {
+	var body []byte
+	var contentType string
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/traces" {
+			http.NotFound(w, r)
+			return
+		}
+		contentType = r.Header.Get("Content-Type")
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer collector.Close()
+
+	exporter := tracing.NewOTLPExporter(collector.URL+"/", "mini-blog-test")
+	if err := exporter.Export(context.Background(), testSpans()); err != nil {
+		t.Fatalf("exporting: %v", err)
+	}
+	if contentType != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", contentType)
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, body, "", "  "); err != nil {
+		t.Fatalf("collector received invalid JSON: %v\n%s", err, body)
+	}
+	indented.WriteByte('\n')
+	testutil.Golden(t, "otlp.golden.json", indented.Bytes())
+}
+
+func TestOTLPExporterCollectorError(t *testing.T) {
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	defer collector.Close()
+
+	if err := tracing.NewOTLPExporter(collector.URL, "mini-blog-test").Export(context.Background(), testSpans()); err == nil {
+		t.Error("export to a failing collector succeeded")
+	}
+}
+
+func TestWriterExporterGolden(t *testing.T) {
+	var out bytes.Buffer
+	if err := tracing.NewWriterExporter(&out).Export(context.Background(), testSpans()); err != nil {
+		t.Fatalf("exporting: %v", err)
+	}
+	testutil.Golden(t, "spans.golden.jsonl", out.Bytes())
+}
@@ -0,0 +1,88 @@
+package tracing
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// spanSetting is the statement setting holding the span of a query.
+const spanSetting = "tracing:span"
+
+// GormPlugin records a client span for every GORM query made within a trace, that is
+// with a context carrying a span (db.WithContext). Queries outside a trace, such as
+// those of background jobs, are not recorded. Install it with db.Use(tracing.GormPlugin{}).
+type GormPlugin struct{}
+
+// Name returns the name of the plugin.
+func (GormPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize registers the plugin's callbacks around every kind of query.
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	type registerer interface {
+		Register(name string, fn func(*gorm.DB)) error
+	}
+
+	callbacks := db.Callback()
+	operations := []struct {
+		name          string
+		before, after registerer
+	}{
+		{"create", callbacks.Create().Before("*"), callbacks.Create().After("*")},
+		{"query", callbacks.Query().Before("*"), callbacks.Query().After("*")},
+		{"update", callbacks.Update().Before("*"), callbacks.Update().After("*")},
+		{"delete", callbacks.Delete().Before("*"), callbacks.Delete().After("*")},
+		{"row", callbacks.Row().Before("*"), callbacks.Row().After("*")},
+		{"raw", callbacks.Raw().Before("*"), callbacks.Raw().After("*")},
+	}
+
+	for _, operation := range operations {
+		if err := operation.before.Register("tracing:before_"+operation.name, startQuerySpan(operation.name)); err != nil {
+			return err
+		}
+		if err := operation.after.Register("tracing:after_"+operation.name, endQuerySpan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startQuerySpan returns a callback starting the span of a query.
+func startQuerySpan(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil || SpanFromContext(ctx) == nil {
+			return
+		}
+
+		name := "db." + operation
+		if db.Statement.Table != "" {
+			name += " " + db.Statement.Table
+		}
+		_, span := Start(ctx, name, SpanKindClient)
+		span.SetAttribute("db.system", "postgresql")
+		span.SetAttribute("db.operation", operation)
+		if db.Statement.Table != "" {
+			span.SetAttribute("db.sql.table", db.Statement.Table)
+		}
+		db.InstanceSet(spanSetting, span)
+	}
+}
+
+// endQuerySpan ends the span of a query with its statement and outcome.
+func endQuerySpan(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanSetting)
+	if !ok {
+		return
+	}
+	span := value.(*Span)
+
+	span.SetAttribute("db.statement", db.Statement.SQL.String())
+	span.SetAttribute("db.rows_affected", db.Statement.RowsAffected)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+	}
+	span.End()
+}
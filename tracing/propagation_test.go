@@ -0,0 +1,97 @@
+package tracing_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/jasen-devvv/mini-blog-backend/tracing"
+)
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceparent string
+		valid       bool
+		sampled     bool
+	}{
+		{"sampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true, true},
+		{"not sampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", true, false},
+		{"other flags set", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-03", true, true},
+		{"surrounding spaces", "  00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01 ", true, true},
+		{"future version with more fields", "cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-what-the-future-holds", true, true},
+		{"version 00 with more fields", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false, false},
+		{"forbidden version ff", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false, false},
+		{"long version", "000-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false, false},
+		{"uppercase trace ID", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", false, false},
+		{"short trace ID", "00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01", false, false},
+		{"short span ID", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b-01", false, false},
+		{"non-hex span ID", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902zz-01", false, false},
+		{"non-hex flags", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0g", false, false},
+		{"zero trace ID", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", false, false},
+		{"zero span ID", "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false, false},
+		{"missing flags", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7", false, false},
+		{"empty", "", false, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			header := http.Header{}
+			if test.traceparent != "" {
+				header.Set(tracing.TraceparentHeader, test.traceparent)
+			}
+
+			sc, ok := tracing.Extract(header)
+			if ok != test.valid {
+				t.Fatalf("Extract(%q) ok = %v, want %v", test.traceparent, ok, test.valid)
+			}
+			if !ok {
+				if sc.IsValid() {
+					t.Errorf("rejected header returned a valid span context: %+v", sc)
+				}
+				return
+			}
+			if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID.String() != "00f067aa0ba902b7" {
+				t.Errorf("got trace %s span %s", sc.TraceID, sc.SpanID)
+			}
+			if sc.Sampled != test.sampled {
+				t.Errorf("Sampled = %v, want %v", sc.Sampled, test.sampled)
+			}
+		})
+	}
+}
+
+func TestInject(t *testing.T) {
+	header := http.Header{}
+	tracing.Inject(context.Background(), header)
+	if value := header.Get(tracing.TraceparentHeader); value != "" {
+		t.Errorf("injected %q without a span", value)
+	}
+
+	for _, traceparent := range []string{
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+	} {
+		incoming := http.Header{}
+		incoming.Set(tracing.TraceparentHeader, traceparent)
+		parent, _ := tracing.Extract(incoming)
+
+		// The remote parent is propagated as is
+		ctx := tracing.ContextWithRemoteParent(context.Background(), parent)
+		header := http.Header{}
+		tracing.Inject(ctx, header)
+		if got := header.Get(tracing.TraceparentHeader); got != traceparent {
+			t.Errorf("injected %q, want %q", got, traceparent)
+		}
+
+		// A child span keeps the trace ID and sampling decision, with its own span ID
+		ctx, span := tracing.Start(ctx, "child", tracing.SpanKindClient)
+		header = http.Header{}
+		tracing.Inject(ctx, header)
+		child, ok := tracing.Extract(header)
+		if !ok || child.TraceID != parent.TraceID || child.SpanID != span.SpanContext().SpanID ||
+			child.SpanID == parent.SpanID || child.Sampled != parent.Sampled {
+			t.Errorf("child of %q injected %q", traceparent, header.Get(tracing.TraceparentHeader))
+		}
+		span.End()
+	}
+}
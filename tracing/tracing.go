@@ -0,0 +1,227 @@
+// Package tracing records distributed traces of requests across the HTTP server, the
+// database and outbound HTTP calls.
+//
+// It follows the OpenTelemetry model: a trace is a tree of spans, each timing one
+// operation. Trace context is propagated between services with the W3C "traceparent"
+// header (see Extract and Inject). Ended spans are handed to the exporter configured with
+// Setup, which writes them as JSON lines for local debugging or sends them to an OTLP
+// collector. Without an exporter, spans are still created, so trace IDs can be logged and
+// propagated, but they are not recorded anywhere.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// TraceID identifies a trace.
+type TraceID [16]byte
+
+// String returns the ID in lowercase hex.
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IsValid reports whether the ID is not all zeros.
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+// String returns the ID in lowercase hex.
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IsValid reports whether the ID is not all zeros.
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+// SpanContext is the part of a span that is propagated to other services.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid reports whether both IDs are set.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// SpanKind describes the relationship of a span to its parent and children.
+type SpanKind int
+
+// The span kinds, numbered as in OTLP.
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+)
+
+// StatusCode is the outcome of a span, numbered as in OTLP.
+type StatusCode int
+
+const (
+	StatusUnset StatusCode = 0
+	StatusOK    StatusCode = 1
+	StatusError StatusCode = 2
+)
+
+// SpanData is a finished span, as handed to exporters.
+//
+// Fields:
+//   - TraceID, SpanID: Identify the span.
+//   - ParentSpanID: The parent span; zero for root spans.
+//   - Name: Operation the span timed, such as "GET /api/articles/:id".
+//   - Kind: Whether the span served a request, made one, or neither.
+//   - Start, End: When the operation started and ended.
+//   - Attributes: Details of the operation; values are strings, int64s, float64s or bools.
+//   - Status, StatusMessage: Outcome of the operation.
+type SpanData struct {
+	TraceID       TraceID
+	SpanID        SpanID
+	ParentSpanID  SpanID
+	Name          string
+	Kind          SpanKind
+	Start         time.Time
+	End           time.Time
+	Attributes    map[string]interface{}
+	Status        StatusCode
+	StatusMessage string
+}
+
+// Span times an operation. A nil *Span is valid and records nothing, so callers never
+// need to check whether tracing is enabled.
+type Span struct {
+	mu      sync.Mutex
+	data    SpanData
+	sampled bool
+	ended   bool
+}
+
+// spanKey is the key spans are stored under in a context.
+type spanKey struct{}
+
+// Start starts a span as a child of the span in ctx, or of the remote parent stored by
+// ContextWithRemoteParent, or as the root of a new trace. It returns a copy of ctx
+// carrying the new span, which must be ended with End.
+func Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	span := &Span{data: SpanData{Name: name, Kind: kind, Start: time.Now(), Attributes: map[string]interface{}{}}}
+
+	if parent := SpanContextFromContext(ctx); parent.IsValid() {
+		span.data.TraceID = parent.TraceID
+		span.data.ParentSpanID = parent.SpanID
+		span.sampled = parent.Sampled
+	} else {
+		rand.Read(span.data.TraceID[:])
+		span.sampled = true
+	}
+	rand.Read(span.data.SpanID[:])
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// ContextWithRemoteParent returns a copy of ctx whose spans become children of a span of
+// another service, typically extracted from a request with Extract.
+func ContextWithRemoteParent(ctx context.Context, parent SpanContext) context.Context {
+	remote := &Span{data: SpanData{TraceID: parent.TraceID, SpanID: parent.SpanID}, sampled: parent.Sampled, ended: true}
+	return context.WithValue(ctx, spanKey{}, remote)
+}
+
+// SpanFromContext returns the span carried by ctx, or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// SpanContextFromContext returns the context of the span carried by ctx; it is invalid
+// when there is none.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	return SpanFromContext(ctx).SpanContext()
+}
+
+// SpanContext returns the propagated part of the span.
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return SpanContext{TraceID: s.data.TraceID, SpanID: s.data.SpanID, Sampled: s.sampled}
+}
+
+// SetName replaces the name of the span, for names only known once the operation ran.
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Name = name
+}
+
+// SetAttribute records a detail of the operation. Values should be strings, integers,
+// floats or bools.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	switch v := value.(type) {
+	case int:
+		value = int64(v)
+	case uint:
+		value = int64(v)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.data.Attributes[key] = value
+	}
+}
+
+// SetStatus records the outcome of the operation.
+func (s *Span) SetStatus(code StatusCode, message string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.data.Status = code
+		s.data.StatusMessage = message
+	}
+}
+
+// RecordError marks the span as failed with err, when err is not nil.
+func (s *Span) RecordError(err error) {
+	if err != nil {
+		s.SetStatus(StatusError, err.Error())
+	}
+}
+
+// End ends the span and hands it to the exporter when it is sampled. Calls after the
+// first are ignored.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	if s.sampled {
+		export(data)
+	}
+}
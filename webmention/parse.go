@@ -0,0 +1,284 @@
+package webmention
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jasen-devvv/mini-blog-backend/netsafe"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ErrNoLink is returned by Verify when the source page does not link to the target.
+var ErrNoLink = errors.New("source does not link to target")
+
+// Mention types, derived from the microformats class of the link to the target.
+const (
+	TypeMention  = "mention"
+	TypeReply    = "reply"
+	TypeLike     = "like"
+	TypeRepost   = "repost"
+	TypeBookmark = "bookmark"
+)
+
+// Maximum lengths of the metadata kept for display.
+const (
+	maxTitleLength   = 200
+	maxAuthorLength  = 100
+	maxExcerptLength = 300
+)
+
+// Metadata describes the source page of a verified Webmention. Text fields are plain
+// text, trimmed to a maximum length, and URL fields are absolute http(s) URLs, so they
+// are safe to display.
+type Metadata struct {
+	Type        string
+	Title       string
+	AuthorName  string
+	AuthorURL   string
+	AuthorPhoto string
+	Excerpt     string
+	Published   *time.Time
+}
+
+// classTypes maps microformats link classes to mention types.
+var classTypes = map[string]string{
+	"u-in-reply-to": TypeReply,
+	"u-like-of":     TypeLike,
+	"u-repost-of":   TypeRepost,
+	"u-bookmark-of": TypeBookmark,
+}
+
+// parseSource checks that a source page links to target and extracts its metadata,
+// preferring microformats2 h-entry properties and falling back to standard meta tags.
+func parseSource(r io.Reader, base *url.URL, target string) (Metadata, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	// Find the link to the target
+	link := findNode(doc, func(n *html.Node) bool {
+		for _, key := range []string{"href", "src"} {
+			if value, ok := attr(n, key); ok && absolute(base, value) == target {
+				return true
+			}
+		}
+		return false
+	})
+	if link == nil {
+		return Metadata{}, ErrNoLink
+	}
+
+	meta := Metadata{Type: TypeMention}
+	for _, class := range strings.Fields(attrValue(link, "class")) {
+		if mentionType, ok := classTypes[class]; ok {
+			meta.Type = mentionType
+		}
+	}
+
+	// Prefer microformats2 properties of the h-entry
+	if entry := findNode(doc, hasClass("h-entry")); entry != nil {
+		if n := findNode(entry, hasClass("p-name")); n != nil {
+			meta.Title = text(n)
+		}
+		if n := findNode(entry, hasClass("e-content")); n != nil {
+			meta.Excerpt = text(n)
+		} else if n := findNode(entry, hasClass("p-summary")); n != nil {
+			meta.Excerpt = text(n)
+		}
+		if n := findNode(entry, hasClass("dt-published")); n != nil {
+			if published, err := time.Parse(time.RFC3339, firstNonEmpty(attrValue(n, "datetime"), text(n))); err == nil {
+				meta.Published = &published
+			}
+		}
+		if author := findNode(entry, hasClass("p-author")); author != nil {
+			meta.AuthorName = text(author)
+			if n := findNode(author, hasClass("p-name")); n != nil {
+				meta.AuthorName = text(n)
+			}
+			if n := findNode(author, hasClass("u-url")); n != nil {
+				meta.AuthorURL = absolute(base, attrValue(n, "href"))
+			} else if href, ok := attr(author, "href"); ok {
+				meta.AuthorURL = absolute(base, href)
+			}
+			if n := findNode(author, hasClass("u-photo")); n != nil {
+				meta.AuthorPhoto = absolute(base, attrValue(n, "src"))
+			}
+		}
+	}
+
+	// Fall back to the page title and meta tags
+	if meta.Title == "" {
+		meta.Title = firstNonEmpty(metaContent(doc, "og:title"), titleText(doc))
+	}
+	if meta.Excerpt == "" {
+		meta.Excerpt = firstNonEmpty(metaContent(doc, "description"), metaContent(doc, "og:description"))
+	}
+	if meta.AuthorName == "" {
+		meta.AuthorName = metaContent(doc, "author")
+	}
+
+	meta.Title = truncate(meta.Title, maxTitleLength)
+	meta.AuthorName = truncate(meta.AuthorName, maxAuthorLength)
+	meta.Excerpt = truncate(meta.Excerpt, maxExcerptLength)
+	if !netsafe.IsHTTPURL(meta.AuthorURL) {
+		meta.AuthorURL = ""
+	}
+	if !netsafe.IsHTTPURL(meta.AuthorPhoto) {
+		meta.AuthorPhoto = ""
+	}
+
+	return meta, nil
+}
+
+// htmlEndpoint finds the href of the first <link> or <a> element with rel="webmention".
+func htmlEndpoint(r io.Reader) (string, bool) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return "", false
+	}
+
+	n := findNode(doc, func(n *html.Node) bool {
+		if n.DataAtom != atom.Link && n.DataAtom != atom.A {
+			return false
+		}
+		_, hasHref := attr(n, "href")
+		return hasHref && hasToken(attrValue(n, "rel"), "webmention")
+	})
+	if n == nil {
+		return "", false
+	}
+	return attrValue(n, "href"), true
+}
+
+// Links returns the absolute http(s) URLs linked from an HTML fragment, without duplicates.
+func Links(fragment string) []string {
+	doc, err := html.Parse(strings.NewReader(fragment))
+	if err != nil {
+		return nil
+	}
+
+	links := []string{}
+	seen := map[string]bool{}
+	findNode(doc, func(n *html.Node) bool {
+		if n.DataAtom == atom.A {
+			if href := attrValue(n, "href"); netsafe.IsHTTPURL(href) && !seen[href] {
+				seen[href] = true
+				links = append(links, href)
+			}
+		}
+		return false
+	})
+	return links
+}
+
+// findNode returns the first node (depth first) matching the predicate.
+func findNode(n *html.Node, match func(*html.Node) bool) *html.Node {
+	if n.Type == html.ElementNode && match(n) {
+		return n
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if found := findNode(child, match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// hasClass returns a predicate matching elements with the given class.
+func hasClass(class string) func(*html.Node) bool {
+	return func(n *html.Node) bool {
+		return hasToken(attrValue(n, "class"), class)
+	}
+}
+
+// metaContent returns the content of a <meta> tag by name or property.
+func metaContent(doc *html.Node, name string) string {
+	n := findNode(doc, func(n *html.Node) bool {
+		return n.DataAtom == atom.Meta && (attrValue(n, "name") == name || attrValue(n, "property") == name)
+	})
+	if n == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(attrValue(n, "content")), " ")
+}
+
+// titleText returns the text of the page's <title>.
+func titleText(doc *html.Node) string {
+	n := findNode(doc, func(n *html.Node) bool { return n.DataAtom == atom.Title })
+	if n == nil {
+		return ""
+	}
+	return text(n)
+}
+
+// text returns the plain text content of a node with whitespace collapsed,
+// skipping scripts and styles.
+func text(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && (n.DataAtom == atom.Script || n.DataAtom == atom.Style) {
+			return
+		}
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			b.WriteString(" ")
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// attr returns the value of an attribute and whether it is present.
+func attr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// attrValue returns the value of an attribute, or "" when it is absent.
+func attrValue(n *html.Node, key string) string {
+	value, _ := attr(n, key)
+	return value
+}
+
+// absolute resolves a URL reference against the page's URL.
+func absolute(base *url.URL, ref string) string {
+	u, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	return u.String()
+}
+
+// truncate shortens text to at most max characters, ending with an ellipsis when cut.
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max-1]) + "…"
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
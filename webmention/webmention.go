@@ -0,0 +1,178 @@
+// Package webmention sends and verifies Webmentions (https://www.w3.org/TR/webmention/).
+//
+// All network access goes through the HTTPClient interface, so sending and
+// verification can be tested against a fake client or a local server.
+package webmention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jasen-devvv/mini-blog-backend/netsafe"
+)
+
+// maxPageSize limits how much of a fetched page is read.
+const maxPageSize = 1 << 20
+
+// HTTPClient sends HTTP requests. *http.Client implements it.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client discovers endpoints, sends Webmentions and verifies received ones.
+type Client struct {
+	HTTP      HTTPClient
+	UserAgent string
+}
+
+// DefaultClient is the client used by the application. It refuses to connect to
+// loopback and private addresses, so a received Webmention cannot make the server
+// fetch internal URLs.
+var DefaultClient = &Client{
+	HTTP:      netsafe.NewHTTPClient(10 * time.Second),
+	UserAgent: "mini-blog-backend (Webmention)",
+}
+
+// ErrNoEndpoint is returned when a target page does not advertise a Webmention endpoint.
+var ErrNoEndpoint = errors.New("no webmention endpoint")
+
+// DiscoverEndpoint finds the Webmention endpoint advertised by a target page, from its
+// Link header or from a <link> or <a> element with rel="webmention".
+func (c *Client) DiscoverEndpoint(ctx context.Context, target string) (string, error) {
+	resp, err := c.get(ctx, target)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	base := resp.Request.URL
+	if base == nil {
+		base, _ = url.Parse(target)
+	}
+
+	for _, link := range resp.Header.Values("Link") {
+		if href, ok := linkHeaderEndpoint(link); ok {
+			return resolve(base, href)
+		}
+	}
+
+	if !strings.Contains(resp.Header.Get("Content-Type"), "html") {
+		return "", ErrNoEndpoint
+	}
+	href, ok := htmlEndpoint(io.LimitReader(resp.Body, maxPageSize))
+	if !ok {
+		return "", ErrNoEndpoint
+	}
+	return resolve(base, href)
+}
+
+// Send notifies an endpoint that source links to target.
+func (c *Client) Send(ctx context.Context, endpoint, source, target string) error {
+	form := url.Values{"source": {source}, "target": {target}}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", c.UserAgent)
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxPageSize))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("sending webmention to %s: unexpected status %s", endpoint, resp.Status)
+	}
+	return nil
+}
+
+// Verify fetches the source page and checks that it links to target. It returns
+// display-safe metadata about the source on success, and ErrNoLink when the link is missing.
+func (c *Client) Verify(ctx context.Context, source, target string) (Metadata, error) {
+	resp, err := c.get(ctx, source)
+	if err != nil {
+		return Metadata{}, err
+	}
+	defer resp.Body.Close()
+
+	if !strings.Contains(resp.Header.Get("Content-Type"), "html") {
+		return Metadata{}, ErrNoLink
+	}
+
+	return parseSource(io.LimitReader(resp.Body, maxPageSize), resp.Request.URL, target)
+}
+
+// get fetches a page, failing on non-2xx responses.
+func (c *Client) get(ctx context.Context, rawURL string) (*http.Response, error) {
+	if !netsafe.IsHTTPURL(rawURL) {
+		return nil, fmt.Errorf("%q is not an http(s) URL", rawURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("User-Agent", c.UserAgent)
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("fetching %s: unexpected status %s", rawURL, resp.Status)
+	}
+	return resp, nil
+}
+
+// linkHeaderEndpoint returns the URL of a Link header value with rel="webmention".
+func linkHeaderEndpoint(header string) (string, bool) {
+	for _, link := range strings.Split(header, ",") {
+		parts := strings.Split(link, ";")
+		href := strings.TrimSpace(parts[0])
+		if !strings.HasPrefix(href, "<") || !strings.HasSuffix(href, ">") {
+			continue
+		}
+		for _, param := range parts[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "rel") && hasToken(strings.Trim(value, `"`), "webmention") {
+				return strings.Trim(href, "<>"), true
+			}
+		}
+	}
+	return "", false
+}
+
+// resolve resolves an endpoint reference relative to the page it was found on.
+func resolve(base *url.URL, href string) (string, error) {
+	ref, err := url.Parse(href)
+	if err != nil {
+		return "", err
+	}
+	endpoint := base.ResolveReference(ref)
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return "", ErrNoEndpoint
+	}
+	return endpoint.String(), nil
+}
+
+// hasToken reports whether a space separated list contains a token (case-insensitively).
+func hasToken(list, token string) bool {
+	for _, item := range strings.Fields(list) {
+		if strings.EqualFold(item, token) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package webmention_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jasen-devvv/mini-blog-backend/webmention"
+)
+
+const target = "https://blog.example/articles/1"
+
+// sourceServer serves the given pages by path, as HTML unless the path ends in ".txt".
+func sourceServer(t *testing.T, pages map[string]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, ".txt") {
+			w.Header().Set("Content-Type", "text/plain")
+		} else {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		}
+		io.WriteString(w, page)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVerify(t *testing.T) {
+	server := sourceServer(t, map[string]string{
+		"/reply": `<html><body><article class="h-entry">
+			<h1 class="p-name">Re: Hello</h1>
+			<a class="p-author h-card" href="/about"><span class="p-name">Bob</span></a>
+			<time class="dt-published" datetime="2024-05-01T10:00:00Z">May 1</time>
+			<div class="e-content">Nice post! <a class="u-in-reply-to" href="` + target + `">Hello</a>
+			<script>alert(1)</script></div>
+		</article></body></html>`,
+		"/mention":  `<html><head><title>Links</title></head><body><a href="` + target + `">a post</a></body></html>`,
+		"/unlinked": `<html><body><a href="https://blog.example/articles/2">another post</a></body></html>`,
+		"/text.txt": target,
+	})
+	client := &webmention.Client{HTTP: server.Client(), UserAgent: "test"}
+	ctx := context.Background()
+
+	meta, err := client.Verify(ctx, server.URL+"/reply", target)
+	if err != nil {
+		t.Fatalf("verifying a reply: %v", err)
+	}
+	if meta.Type != webmention.TypeReply || meta.Title != "Re: Hello" || meta.AuthorName != "Bob" ||
+		meta.AuthorURL != server.URL+"/about" || meta.Published == nil {
+		t.Errorf("unexpected metadata: %+v", meta)
+	}
+	if strings.Contains(meta.Excerpt, "<") {
+		t.Errorf("excerpt is not plain text: %q", meta.Excerpt)
+	}
+
+	meta, err = client.Verify(ctx, server.URL+"/mention", target)
+	if err != nil || meta.Type != webmention.TypeMention || meta.Title != "Links" {
+		t.Errorf("verifying a mention: %+v, %v", meta, err)
+	}
+
+	for name, source := range map[string]string{
+		"no link to the target": server.URL + "/unlinked",
+		"not an HTML page":      server.URL + "/text.txt",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := client.Verify(ctx, source, target); !errors.Is(err, webmention.ErrNoLink) {
+				t.Errorf("Verify = %v, want ErrNoLink", err)
+			}
+		})
+	}
+
+	for name, source := range map[string]string{
+		"missing page":       server.URL + "/deleted",
+		"not an http(s) URL": "file:///etc/passwd",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := client.Verify(ctx, source, target); err == nil || errors.Is(err, webmention.ErrNoLink) {
+				t.Errorf("Verify = %v, want a fetch error", err)
+			}
+		})
+	}
+}
+
+func TestDefaultClientRefusesPrivateAddresses(t *testing.T) {
+	server := sourceServer(t, map[string]string{"/mention": `<a href="` + target + `">a post</a>`})
+
+	_, err := webmention.DefaultClient.Verify(context.Background(), server.URL+"/mention", target)
+	if err == nil || !strings.Contains(err.Error(), "refusing to connect") {
+		t.Fatalf("verifying a loopback source: %v, want a refused connection", err)
+	}
+}